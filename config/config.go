@@ -3,15 +3,41 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 )
 
 type Config struct {
+	// AIProvider selects the language model: openai, openai-compatible (with AI_BASE_URL) or anthropic
+	AIProvider         string `envconfig:"AI_PROVIDER" default:"openai"`
+	AIBaseURL          string `envconfig:"AI_BASE_URL"`
+	AIAPIKey           string `envconfig:"AI_API_KEY"`
+	AnthropicModel     string `envconfig:"ANTHROPIC_MODEL" default:"claude-3-5-sonnet-latest"`
+	AnthropicMaxTokens int    `envconfig:"ANTHROPIC_MAX_TOKENS" default:"8192"`
+	// OpenAIModel is the model of the openai and openai-compatible providers
 	OpenAIModel   string `envconfig:"OPENAI_MODEL" default:"gpt-4o-2024-08-06"`
 	AudioLanguage string `envconfig:"AUDIO_LANGUAGE" default:"en"`
-	WithImage     bool   `envconfig:"WITH_IMAGE" default:"false"`
-	TempDir       string `envconfig:"TEMPDIR" default:"auto"`
+	// AudioSegmentDuration is the maximum duration of the segments of an audio file, transcribed separately
+	AudioSegmentDuration time.Duration `envconfig:"AUDIO_SEGMENT_DURATION" default:"10m"`
+	// AudioParallelism is the maximum number of segments of an audio file transcribed concurrently
+	AudioParallelism int `envconfig:"AUDIO_PARALLELISM" default:"4"`
+	// MaxPromptTokens is the budget of a prompt; a longer content is split and the partial presentations are merged
+	MaxPromptTokens int `envconfig:"MAX_PROMPT_TOKENS" default:"60000"`
+	// MaxOutputTokens is the budget of an answer; a presentation longer than that is not re-emitted by the model
+	MaxOutputTokens int    `envconfig:"MAX_OUTPUT_TOKENS" default:"16000"`
+	WithImage       bool   `envconfig:"WITH_IMAGE" default:"false"`
+	TempDir         string `envconfig:"TEMPDIR" default:"auto"`
+	// SlidesBatchSize is the maximum number of requests sent to the Google Slides API in a single BatchUpdate
+	SlidesBatchSize int `envconfig:"SLIDES_BATCH_SIZE" default:"500"`
+	// CheckpointInterval is the number of slides built between two checkpoints of a Google Slides build
+	CheckpointInterval int `envconfig:"CHECKPOINT_INTERVAL" default:"5"`
+	// GoogleMaxRetries is the maximum number of retries of a Google API call failing with 429, or 5xx if it is idempotent
+	GoogleMaxRetries int `envconfig:"GOOGLE_MAX_RETRIES" default:"5"`
+	// GoogleWritesPerMinute is the maximum number of write requests per minute to the Google APIs; 0 means no limit
+	GoogleWritesPerMinute int `envconfig:"GOOGLE_WRITES_PER_MINUTE" default:"60"`
+	// Layouts maps each slide role (cover, chapter, content) to a layout name, display name or object ID of the template
+	Layouts map[string]string `envconfig:"LAYOUTS" default:"cover:g2ac55f3490c_0_1073,chapter:g2ac55f3490c_0_1010,content:g2ac55f3490c_0_1006"`
 }
 
 var ConfigInstance *Config
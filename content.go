@@ -4,28 +4,191 @@ import (
 	"context"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/owulveryck/gptslideshow/internal/ai"
+	"github.com/owulveryck/gptslideshow/internal/audio"
+	"github.com/owulveryck/gptslideshow/internal/chart"
+	"github.com/owulveryck/gptslideshow/internal/extract"
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/structure"
 )
 
-func readContent(ctx context.Context, openaiClient *ai.AI, textfile, audiofile *string) []byte {
-	var content []byte
-	var err error
+// transcriptPrompt precedes the prompt when the content is the transcript of an audio file.
+const transcriptPrompt = `The content is the transcript of a talk. In the speaker notes of each slide, quote the passage of the transcript the slide is generated from, so that the speaker can say what was actually said.
+Each passage of the transcript starts with its time marker, such as [01:23]. In the audio span of each slide, give the markers of the first and the last passages the slide summarises, leaving its source empty.
+`
 
-	if *textfile != "" {
-		content, err = os.ReadFile(*textfile)
-		if err != nil {
-			log.Fatal(err)
+// sourcesPrompt precedes the prompt when the content is made of several sources (see joinDocuments).
+const sourcesPrompt = `The content is made of several sources, each one starting with a heading such as "# Source: notes.md". These headings only give the provenance of what follows: do not create slides or chapters for them. Keep track of the provenance, and end the speaker notes of each slide with the sources it comes from, such as "(Source: notes.md)".
+`
+
+// transcriptSourcesPrompt follows sourcesPrompt when some of the sources are transcripts of audio files.
+const transcriptSourcesPrompt = `The sources marked as transcripts are transcripts of talks. In the speaker notes of the slides generated from them, quote the passage of the transcript the slide is generated from, so that the speaker can say what was actually said.
+Each passage of a transcript starts with its time marker, such as [01:23]. In the audio span of each slide generated from a transcript, give the audio file of the transcript, such as meeting.mp3, and the markers of the first and the last passages the slide summarises. Leave the audio span of the other slides empty.
+`
+
+// tablesPrompt precedes the prompt when the tables of the content are replaced by markers (see slidesutils.ExtractTables).
+const tablesPrompt = `The tables of the content are replaced by markers such as [[table 1]]. For each marker, create a slide whose body is the marker alone, with a title and a subtitle introducing the table.
+`
+
+// condensePrompt is the prompt condensing the body of a slide built from the outline of the content; its argument is
+// the length budget of the body.
+const condensePrompt = `Condense the body of the following slide to at most %d characters, keeping its Markdown formatting, its facts and its figures. Keep the title unchanged and leave the speaker notes empty.
+`
+
+// refinePrompt is the prompt rewriting a slide of an existing presentation (see refineSlide); its argument is the
+// instruction of the user.
+const refinePrompt = `Rewrite the following slide of an existing presentation according to this instruction: %v
+Keep what the instruction does not ask to change, such as the language and the tone of the slide. The body uses Markdown lists and emphasis, and the speaker notes are the talking points of the slide. Leave the code, the table, the chart and the audio span empty.
+When it is given, the original content the presentation was generated from follows the slide: take the facts and the examples from it.
+`
+
+// chartTables turns the table slides whose table holds figures into chart slides (see chart.FromTable).
+func chartTables(tables []structure.Slide) {
+	for i := range tables {
+		if c, ok := chart.FromTable(tables[i].Table); ok {
+			tables[i].Chart = c
+			tables[i].Table = structure.Table{}
+		}
+	}
+}
+
+// checkCharts turns the chart slides whose chart cannot be rendered (see chart.Validate) into table slides holding the
+// data of the chart, or into content slides if the chart has no labels.
+func checkCharts(slides []structure.Slide) {
+	for i := range slides {
+		slide := &slides[i]
+		if slide.Chart.Empty() {
+			continue
+		}
+		if err := chart.Validate(slide.Chart); err != nil {
+			log.Printf("Warning: slide %v (%v): %v; it is built without chart", i, slide.Title, err)
+			if len(slide.Chart.Labels) > 0 {
+				slide.Table = chart.ToTable(slide.Chart)
+			}
+			slide.Chart = structure.Chart{}
+		}
+	}
+}
+
+// splitSlides replaces the content slides whose body does not fit the text box by their continuation slides (see
+// slidesutils.SplitSlide).
+func splitSlides(p *structure.Presentation, box slidesutils.TextBox) {
+	var split []structure.Slide
+	for _, slide := range p.Slides {
+		parts := slidesutils.SplitSlide(slide, box)
+		if len(parts) > 1 {
+			log.Printf("The body of the slide %q is split into %d slides", slide.Title, len(parts))
+		}
+		split = append(split, parts...)
+	}
+	p.Slides = split
+}
+
+// insertTables turns the slides holding a table marker into table (or chart) slides; the tables whose marker was dropped by the model
+// are appended to the presentation.
+func insertTables(p *structure.Presentation, tables []structure.Slide) {
+	used := make([]bool, len(tables))
+	for i := range p.Slides {
+		slide := &p.Slides[i]
+		for j, table := range tables {
+			if used[j] || slide.Chapter || !strings.Contains(slide.Body, slidesutils.TableMarker(j)) {
+				continue
+			}
+			slide.Table = table.Table
+			slide.Chart = table.Chart
+			slide.Body = ""
+			slide.Code = ""
+			if slide.Title == "" {
+				slide.Title = table.Title
+			}
+			used[j] = true
+			break
 		}
 	}
+	for j, table := range tables {
+		if !used[j] {
+			p.Slides = append(p.Slides, table)
+		}
+	}
+}
+
+// document is the text of a source.
+type document struct {
+	name       string // the path of the source
+	text       string // the text of the transcript is marked with the time of its passages (see audio.Transcript.Marked)
+	audio      bool
+	transcript audio.Transcript
+}
 
-	if *audiofile != "" {
-		b, err := openaiClient.ExtractTextFromAudio(ctx, *audiofile)
+// readContent returns the text of the sources: the documents are converted to Markdown (see extract.Text) and the
+// audio files transcribed, once the format of all of them is checked.
+func readContent(ctx context.Context, aiClient ai.Provider, sources []source) []document {
+	for _, s := range sources {
+		if s.audio {
+			if err := checkAudio(s.path); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+	docs := make([]document, len(sources))
+	for i, s := range sources {
+		docs[i] = document{name: s.path, audio: s.audio}
+		if s.audio {
+			log.Printf("Transcribing %v", s.path)
+			transcript, err := aiClient.ExtractTextFromAudio(ctx, s.path)
+			if err != nil {
+				log.Fatal(err)
+			}
+			docs[i].transcript = transcript
+			docs[i].text = transcript.Marked()
+			continue
+		}
+		content, err := os.ReadFile(s.path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		docs[i].text, err = extract.Text(s.path, content)
 		if err != nil {
 			log.Fatal(err)
 		}
-		content = []byte(b)
 	}
-	saveContent("input-*.txt", content)
-	return content
+	saveContent("input-*.txt", []byte(joinDocuments(docs)))
+	return docs
+}
+
+// joinDocuments returns the text of the documents. Unless there is a single document, each one starts with a heading
+// giving its provenance, such as "# Source: notes.md" or "# Source: meeting.mp3 (transcript)".
+func joinDocuments(docs []document) string {
+	if len(docs) == 1 {
+		return docs[0].text
+	}
+	parts := make([]string, len(docs))
+	for i, doc := range docs {
+		label := "# Source: " + doc.name
+		if doc.audio {
+			label += " (transcript)"
+		}
+		parts[i] = label + "\n\n" + strings.TrimSpace(doc.text)
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// contentPrompt returns the prompt of the documents: it explains the provenance headings of several documents,
+// asks to quote the transcripts in the speaker notes and to give the time span of the slides generated from them.
+func contentPrompt(prompt string, docs []document) string {
+	transcripts := false
+	for _, doc := range docs {
+		transcripts = transcripts || doc.audio
+	}
+	switch {
+	case len(docs) == 1 && transcripts:
+		return transcriptPrompt + prompt
+	case len(docs) > 1 && transcripts:
+		return sourcesPrompt + transcriptSourcesPrompt + prompt
+	case len(docs) > 1:
+		return sourcesPrompt + prompt
+	}
+	return prompt
 }
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"os"
+
+	"github.com/owulveryck/gptslideshow/config"
+	"github.com/owulveryck/gptslideshow/internal/driveutils"
+	"github.com/owulveryck/gptslideshow/internal/plan"
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/slidesutils/mytemplate"
+	"github.com/owulveryck/gptslideshow/internal/slidesutils/pptx"
+	"github.com/owulveryck/gptslideshow/internal/slidesutils/profile"
+	"github.com/owulveryck/gptslideshow/internal/slidesutils/revealjs"
+)
+
+// deck is the destination of the generated presentation.
+type deck struct {
+	builder    slidesutils.BuilderInterface
+	imageFrame slidesutils.Frame
+	// uploadImage stores an illustration and returns a URL usable by the builder.
+	uploadImage func(ctx context.Context, img image.Image, name string) (string, error)
+	// save is called once all the slides are created.
+	save func(ctx context.Context) error
+	// checkpoint sends the pending requests and records the state of the builder in the checkpoint;
+	// nil if the deck cannot be resumed (the local outputs are written at once by save).
+	checkpoint func(ctx context.Context, cp *plan.Checkpoint) error
+	// resume restores the state of the builder from the checkpoint.
+	resume func(ctx context.Context, cp *plan.Checkpoint) error
+	// checkpointFile is the file the checkpoint is written to, created in the temporary directory if empty.
+	checkpointFile string
+	// bodyBox is the text box the bodies of the content slides are fitted in; nil if the builder does not fit them.
+	bodyBox *slidesutils.TextBox
+}
+
+// loadProfile returns the profile describing the template, nil if there is none.
+func loadProfile(opts *options) (*profile.Profile, error) {
+	if opts.profileFile == "" {
+		return nil, nil
+	}
+	return profile.Load(opts.profileFile)
+}
+
+// layoutNames returns the layout of each role of the template: from the profile if any, from the configuration otherwise.
+func layoutNames(p *profile.Profile) map[string]string {
+	if p == nil {
+		return config.ConfigInstance.Layouts
+	}
+	return p.LayoutNames()
+}
+
+// newDeck returns the deck matching the output format; the profile (nil if none) and the layout names are used by the
+// Google Slides builder.
+func newDeck(ctx context.Context, opts *options, p *profile.Profile, layouts map[string]string) (*deck, error) {
+	switch opts.output {
+	case outputSlides:
+		return newGoogleSlidesDeck(ctx, opts, p, layouts)
+	case outputPPTX:
+		return newPPTXDeck(), nil
+	case outputHTML:
+		return newHTMLDeck(), nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", opts.output)
+	}
+}
+
+// newGoogleSlidesDeck builds the presentation in Google Slides, optionally from a copy of a template, and exports it as PDF.
+func newGoogleSlidesDeck(ctx context.Context, opts *options, p *profile.Profile, layouts map[string]string) (*deck, error) {
+	// Initialize Google services
+	client := initGoogleClient()
+	slidesSrv := initSlidesService(client)
+	driveSrv := initDriveService(client)
+
+	// Handle template copy if specified
+	presentationId := opts.presentationId
+	if opts.fromTemplate != "" {
+		presentationId = handleTemplateCopy(ctx, driveSrv, opts.fromTemplate)
+	}
+
+	d := &deck{
+		imageFrame: slidesutils.DefaultChapterImageFrame,
+		uploadImage: func(ctx context.Context, img image.Image, name string) (string, error) {
+			return driveutils.UploadImage(ctx, driveSrv, img, name)
+		},
+		save: func(ctx context.Context) error {
+			b, err := driveutils.ExtractPDF(ctx, driveSrv, presentationId)
+			if err != nil {
+				return err
+			}
+			return saveContent("output-*.pdf", b)
+		},
+	}
+
+	// Using mytemplate unless a profile describes the template
+	var mb *mytemplate.Builder
+	if p != nil {
+		d.imageFrame = p.ImageFrame(mytemplate.RoleChapter)
+		for role, layout := range layouts {
+			if r, ok := p.Roles[role]; ok {
+				r.Layout = layout
+				p.Roles[role] = r
+			}
+		}
+		b, err := profile.NewBuilder(ctx, slidesSrv, presentationId, p)
+		if err != nil {
+			return nil, err
+		}
+		d.builder, mb = b, b.Builder
+	} else {
+		b, err := mytemplate.NewBuilder(ctx, slidesSrv, presentationId, layouts)
+		if err != nil {
+			return nil, err
+		}
+		d.builder, mb = b, b
+		box := b.BodyBox()
+		d.bodyBox = &box
+	}
+	mb.BatchSize = config.ConfigInstance.SlidesBatchSize
+	d.checkpoint = func(ctx context.Context, cp *plan.Checkpoint) error {
+		if err := mb.Flush(ctx); err != nil {
+			return err
+		}
+		cp.PresentationID = presentationId
+		cp.Chapter = mb.CurrentChapter
+		cp.ObjectIDPrefix, cp.LastObjectID = mb.ObjectIDs()
+		return nil
+	}
+	d.resume = func(ctx context.Context, cp *plan.Checkpoint) error {
+		mb.CurrentChapter = cp.Chapter
+		return mb.Resume(ctx, cp.ObjectIDPrefix, cp.LastObjectID)
+	}
+	return d, nil
+}
+
+// saveCheckpoint sends the pending requests of the deck and writes the checkpoint.
+func (d *deck) saveCheckpoint(ctx context.Context, cp *plan.Checkpoint) error {
+	if d.checkpoint == nil {
+		return nil
+	}
+	if err := d.checkpoint(ctx, cp); err != nil {
+		return err
+	}
+	if d.checkpointFile == "" {
+		f, err := os.CreateTemp(config.ConfigInstance.TempDir, "checkpoint-*.json")
+		if err != nil {
+			return err
+		}
+		f.Close()
+		d.checkpointFile = f.Name()
+		log.Printf("Checkpoint file created: %s (resume an interrupted build with -resume %s)", d.checkpointFile, d.checkpointFile)
+	}
+	return cp.Save(d.checkpointFile)
+}
+
+// newPPTXDeck builds a PowerPoint file locally; the illustrations are stored in the temporary directory.
+func newPPTXDeck() *deck {
+	builder := pptx.NewBuilder()
+	return &deck{
+		builder:     builder,
+		imageFrame:  slidesutils.DefaultChapterImageFrame,
+		uploadImage: saveImage,
+		save: func(ctx context.Context) error {
+			var buf bytes.Buffer
+			if err := builder.Write(&buf); err != nil {
+				return err
+			}
+			return saveContent("output-*.pptx", buf.Bytes())
+		},
+	}
+}
+
+// newHTMLDeck renders a self-contained reveal.js style HTML file locally.
+func newHTMLDeck() *deck {
+	builder := revealjs.NewBuilder()
+	return &deck{
+		builder:     builder,
+		imageFrame:  slidesutils.DefaultChapterImageFrame,
+		uploadImage: saveImage,
+		save: func(ctx context.Context) error {
+			var buf bytes.Buffer
+			if err := builder.Write(&buf); err != nil {
+				return err
+			}
+			return saveContent("output-*.html", buf.Bytes())
+		},
+	}
+}
+
+// saveImage stores the illustration as a PNG file in the temporary directory and returns its path.
+func saveImage(ctx context.Context, img image.Image, name string) (string, error) {
+	f, err := os.CreateTemp(config.ConfigInstance.TempDir, "image-*.png")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		return "", fmt.Errorf("failed to encode image %v: %w", name, err)
+	}
+	return f.Name(), nil
+}
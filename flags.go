@@ -5,14 +5,70 @@ import (
 	"fmt"
 
 	"github.com/owulveryck/gptslideshow/config"
+	"github.com/owulveryck/gptslideshow/internal/lint"
 )
 
-func parseFlags() (presentationId *string, fromTemplate *string, prompt *string, textfile *string, audiofile *string, helpFlag *bool) {
-	presentationId = flag.String("id", "", "ID of the slide to update, empty means create a new one")
-	fromTemplate = flag.String("t", "", "ID of a template file")
-	helpFlag = flag.Bool("h", false, "help")
-	prompt = flag.String("prompt", `Convert the following text into an array of structured slides.
+// The supported values of the -output flag.
+const (
+	outputSlides = "slides"
+	outputPPTX   = "pptx"
+	outputHTML   = "html"
+)
+
+// The supported values of the -order flag.
+const (
+	orderArgs = "args" // the order of the command line, the files of a directory or a pattern by path
+	orderName = "name"
+	orderTime = "time"
+)
+
+// The commands; without any command, the plan is generated and applied in a single run.
+const (
+	commandPlan   = "plan"
+	commandApply  = "apply"
+	commandLint   = "lint"
+	commandRefine = "refine"
+)
+
+// options holds the command-line flags.
+type options struct {
+	presentationId string
+	fromTemplate   string
+	prompt         string
+	sources        []source
+	order          string
+	chapters       bool
+	profileFile    string
+	output         string
+	marpFile       string
+	outline        bool
+	condense       int
+	review         int
+	planFile       string
+	resumeFile     string
+	lintConfig     string
+	lintFormat     string
+	slide          string
+	instruction    string
+	help           bool
+}
+
+// parseFlags returns the command (empty if none) and the flags that follow it.
+func parseFlags(args []string) (string, *options) {
+	var command string
+	if len(args) > 0 && (args[0] == commandPlan || args[0] == commandApply || args[0] == commandLint || args[0] == commandRefine) {
+		command, args = args[0], args[1:]
+	}
+	var opts options
+	flag.StringVar(&opts.presentationId, "id", "", "ID of the slide to update, empty means create a new one")
+	flag.StringVar(&opts.fromTemplate, "t", "", "ID of a template file")
+	flag.BoolVar(&opts.help, "h", false, "help")
+	flag.StringVar(&opts.prompt, "prompt", `Convert the following text into an array of structured slides.
 Each slide should have a title, a subtitle, and a body that should add comprehensive and detailed explanation. Do not use markdown, and seperate each paragraph with two newlines;
+Each slide should also have speaker notes: the talking points a presenter would say, taken from the content.
+When the content holds source code worth showing, create a code slide: put the code as a fenced code block, with its language after the opening fence (such as `+"```go"+`), in the code field, and keep the code field empty for the other slides.
+When a comparison or a feature matrix reads better as a table, create a table slide: fill the table field with a header row and rows of short plain text cells, and leave the header and the rows empty for the other slides.
+When the content holds figures worth comparing (such as percentages or a time series), create a chart slide: fill the chart field with its kind (bar, line or pie), its labels and its series, with one value per label, and leave the series empty for the other slides.
 
 You can also generate chapters between a set of content slides.
 If the slide is a chapter, the body should contain a complete description of the content of the chapter usable to generate a picture to illustrate.
@@ -23,16 +79,40 @@ If it is a chapter, the field 'chapter' must be set to true.
 
 `, "the prompt")
 
-	textfile = flag.String("content", "", "The content file")
-	audiofile = flag.String("audio", "", "The audio file in mp3")
+	flag.Var(sourceFlag{sources: &opts.sources}, "content", "A content file (Markdown, text, PDF, DOCX, HTML, EPUB or CSV), a directory of such files or a glob pattern; repeat the flag to merge several contents into one deck")
+	flag.Var(sourceFlag{sources: &opts.sources, audio: true}, "audio", "An audio file to transcribe (such as mp3), a directory of such files or a glob pattern; can be repeated and mixed with -content")
+	flag.StringVar(&opts.order, "order", orderArgs, "The order of the contents: "+orderArgs+" (the order of the command line, the files of a directory or a pattern by path), "+orderName+" (by file name) or "+orderTime+" (by modification time)")
+	flag.BoolVar(&opts.chapters, "chapters", false, "Dedicate a chapter to each content file")
+	flag.StringVar(&opts.profileFile, "profile", "", "A YAML or JSON template profile describing the layouts and placeholders (default: the built-in template)")
+	flag.StringVar(&opts.marpFile, "marp", "", "A Marp Markdown file (such as the presentation-*.md written after each generation) to build the slides from, without calling the model")
+	flag.BoolVar(&opts.outline, "outline", false, "Build the slides from the headings of the Markdown -content files (# title, ## chapters, ### slides), without calling the model")
+	flag.IntVar(&opts.condense, "condense", 0, "With -outline, condense with the model the bodies longer than this number of characters (0 keeps the bodies as is)")
+	flag.IntVar(&opts.review, "review", 0, "Critique the generated presentation against the content (coverage, redundancy, ordering and slide count) and revise it, at most this number of times; the critiques are written next to the plan (0 generates in a single pass)")
+	flag.StringVar(&opts.output, "output", outputSlides, "The output format: "+outputSlides+" (Google Slides), "+outputPPTX+" (local PowerPoint file) or "+outputHTML+" (local reveal.js style HTML file); only "+outputSlides+" needs Google credentials")
+
+	flag.StringVar(&opts.planFile, "plan", "", "The plan file written by the "+commandPlan+" command (default: a plan-*.json file in the temporary directory) and read by the "+commandApply+", "+commandLint+" and "+commandRefine+" commands")
+
+	flag.StringVar(&opts.lintConfig, "lint-config", "", "A YAML or JSON file configuring the rules of the "+commandLint+" command (default: the built-in rules)")
+	flag.StringVar(&opts.lintFormat, "format", lint.FormatText, "The format of the report of the "+commandLint+" command: "+lint.FormatText+", "+lint.FormatJSON+" or "+lint.FormatSARIF)
+
+	flag.StringVar(&opts.slide, "slide", "", "The slide rewritten by the "+commandRefine+" command: its number in the deck (the cover being 1) or its object ID")
+	flag.StringVar(&opts.instruction, "instruction", "", "The instruction of the "+commandRefine+" command, such as \"make it shorter\" or \"add an example\"")
+
+	flag.StringVar(&opts.resumeFile, "resume", "", "A checkpoint file (checkpoint-*.json in the temporary directory) to resume an interrupted Google Slides build from, without calling the model")
 
-	flag.Parse()
-	return
+	flag.CommandLine.Parse(args)
+	return command, &opts
 }
 
 func printHelp() {
 	fmt.Println("Usage:")
-	fmt.Println("  [flags]")
+	fmt.Printf("  %-26s %s\n", "[flags]", "generate the presentation and build the slides")
+	fmt.Printf("  %-26s %s\n", commandPlan+" [flags]", "generate the presentation and write the plan file")
+	fmt.Printf("  %-26s %s\n", commandApply+" -plan file [flags]", "build the slides from a plan file without calling the model")
+	fmt.Printf("  %-26s %s\n", commandLint+" -plan file [flags]", "check the presentation of a plan or generated-data file against the lint rules")
+	fmt.Printf("  %-26s %s\n", commandLint+" -id presentation", "check a Google Slides presentation against the lint rules")
+	fmt.Printf("  %-26s %s\n", commandRefine+" -id presentation", "rewrite the -slide of a Google Slides presentation according to the -instruction")
+	fmt.Printf("  %-26s %s\n", "-resume checkpoint [flags]", "resume an interrupted build in the same presentation")
 
 	fmt.Println("\nFlags:")
 	flag.PrintDefaults()
@@ -6,8 +6,11 @@ require (
 	github.com/invopop/jsonschema v0.12.0
 	github.com/kelseyhightower/envconfig v1.4.0
 	github.com/openai/openai-go v0.1.0-alpha.38
+	golang.org/x/image v0.23.0
+	golang.org/x/net v0.31.0
 	golang.org/x/oauth2 v0.24.0
 	google.golang.org/api v0.209.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -36,11 +39,9 @@ require (
 	go.opentelemetry.io/otel/metric v1.29.0 // indirect
 	go.opentelemetry.io/otel/trace v1.29.0 // indirect
 	golang.org/x/crypto v0.29.0 // indirect
-	golang.org/x/net v0.31.0 // indirect
 	golang.org/x/sys v0.27.0 // indirect
-	golang.org/x/text v0.20.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20241113202542-65e8d215514f // indirect
 	google.golang.org/grpc v1.67.1 // indirect
 	google.golang.org/protobuf v1.35.2 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/owulveryck/gptslideshow/config"
 	"github.com/owulveryck/gptslideshow/internal/gcputils"
 	"golang.org/x/oauth2/google"
 	drive "google.golang.org/api/drive/v3"
@@ -17,12 +18,14 @@ func initGoogleClient() *http.Client {
 		log.Fatalf("Unable to read client secret file: %v", err)
 	}
 
+	cfg := config.ConfigInstance
 	config, err := google.ConfigFromJSON(b, drive.DriveScope, slides.PresentationsScope)
 	if err != nil {
 		log.Fatalf("Unable to parse client secret file to config: %v", err)
 	}
 
-	return gcputils.GetClient(config)
+	// Retry the calls failing with quota or server errors instead of aborting mid-deck
+	return gcputils.WithRetry(gcputils.GetClient(config), cfg.GoogleMaxRetries, cfg.GoogleWritesPerMinute)
 }
 
 func initSlidesService(client *http.Client) *slides.Service {
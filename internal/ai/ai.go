@@ -6,23 +6,38 @@ import (
 
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
+
+	"github.com/owulveryck/gptslideshow/config"
 )
 
-// AI represents a client for interacting with OpenAI's API.
+// AI represents a client for interacting with OpenAI's API, or with any server implementing it.
 type AI struct {
 	Client *openai.Client
+	// Compatible asks for a JSON object and gives the schema in the prompt, instead of the strict json_schema
+	// response format that servers such as llama.cpp or Ollama may reject.
+	Compatible bool
 }
 
+// NewAI returns a client of the OpenAI API.
+// AI_BASE_URL and AI_API_KEY allow targeting an OpenAI compatible server (such as llama.cpp or Ollama).
 func NewAI() *AI {
 	// Create a custom HTTP client with a 5-minute timeout.
 	httpClient := &http.Client{
 		Timeout: 5 * time.Minute,
 	}
 
-	// Create a new OpenAI client using the custom HTTP client.
-	client := openai.NewClient(
+	opts := []option.RequestOption{
 		option.WithHTTPClient(httpClient),
-	)
+	}
+	if config.ConfigInstance.AIBaseURL != "" {
+		opts = append(opts, option.WithBaseURL(config.ConfigInstance.AIBaseURL))
+	}
+	if config.ConfigInstance.AIAPIKey != "" {
+		opts = append(opts, option.WithAPIKey(config.ConfigInstance.AIAPIKey))
+	}
+
+	// Create a new OpenAI client using the custom HTTP client.
+	client := openai.NewClient(opts...)
 
-	return &AI{client}
+	return &AI{Client: client}
 }
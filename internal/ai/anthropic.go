@@ -0,0 +1,165 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/owulveryck/gptslideshow/config"
+	"github.com/owulveryck/gptslideshow/internal/audio"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// anthropicVersion is the version of the messages API.
+const anthropicVersion = "2023-06-01"
+
+// Anthropic is a client of the Anthropic messages API (or of a server implementing it).
+// The structured output is obtained by forcing the model to call a tool whose input schema is the expected structure.
+// Anthropic does not generate images nor transcribe audio.
+type Anthropic struct {
+	Client    *http.Client
+	BaseURL   string
+	APIKey    string
+	Model     string
+	MaxTokens int
+}
+
+// NewAnthropic returns a client of the Anthropic messages API.
+// The API key is AI_API_KEY, or ANTHROPIC_API_KEY if unset; AI_BASE_URL allows targeting another server.
+func NewAnthropic(cfg *config.Config) (*Anthropic, error) {
+	apiKey := cfg.AIAPIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("ANTHROPIC_API_KEY")
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("the %v provider needs AI_API_KEY or ANTHROPIC_API_KEY", ProviderAnthropic)
+	}
+	baseURL := cfg.AIBaseURL
+	if baseURL == "" {
+		baseURL = "https://api.anthropic.com"
+	}
+	return &Anthropic{
+		Client: &http.Client{
+			Timeout: 5 * time.Minute,
+		},
+		BaseURL:   strings.TrimSuffix(baseURL, "/"),
+		APIKey:    apiKey,
+		Model:     cfg.AnthropicModel,
+		MaxTokens: cfg.AnthropicMaxTokens,
+	}, nil
+}
+
+type anthropicRequest struct {
+	Model      string             `json:"model"`
+	MaxTokens  int                `json:"max_tokens"`
+	Messages   []anthropicMessage `json:"messages"`
+	Tools      []anthropicTool    `json:"tools"`
+	ToolChoice anthropicChoice    `json:"tool_choice"`
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicTool struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	InputSchema interface{} `json:"input_schema"`
+}
+
+type anthropicChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type  string          `json:"type"`
+		Name  string          `json:"name"`
+		Input json.RawMessage `json:"input"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Error      *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// GenerateStructured prompts the model and decodes its answer, constrained by the JSON schema, into v.
+func (a *Anthropic) GenerateStructured(ctx context.Context, name, description string, schema interface{}, prompt string, v interface{}) error {
+	body, err := json.Marshal(anthropicRequest{
+		Model:     a.Model,
+		MaxTokens: a.MaxTokens,
+		Messages: []anthropicMessage{
+			{Role: "user", Content: prompt},
+		},
+		Tools: []anthropicTool{
+			{Name: name, Description: description, InputSchema: schema},
+		},
+		ToolChoice: anthropicChoice{Type: "tool", Name: name},
+	})
+	if err != nil {
+		return fmt.Errorf("cannot encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.BaseURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("x-api-key", a.APIKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var response anthropicResponse
+	if err := json.Unmarshal(b, &response); err != nil {
+		return fmt.Errorf("cannot decode answer (%v): %w", resp.Status, err)
+	}
+	if response.Error != nil {
+		return fmt.Errorf("%v: %v", response.Error.Type, response.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %v", resp.Status)
+	}
+	for _, content := range response.Content {
+		if content.Type == "tool_use" && content.Name == name {
+			return json.Unmarshal(content.Input, v)
+		}
+	}
+	return fmt.Errorf("the model returned no %v (stop reason: %v)", name, response.StopReason)
+}
+
+// GenerateSlide generates a single slide from the content.
+func (a *Anthropic) GenerateSlide(ctx context.Context, preprompt string, content []byte) (*structure.Slide, error) {
+	return generateSlide(ctx, a, preprompt, content)
+}
+
+// GeneratePresentationFromText generates a presentation from the content.
+func (a *Anthropic) GeneratePresentationFromText(ctx context.Context, preprompt string, content []byte) (*structure.Presentation, error) {
+	return generatePresentation(ctx, a, preprompt, content)
+}
+
+// GenerateImageFromText is not supported by Anthropic.
+func (a *Anthropic) GenerateImageFromText(ctx context.Context, prompt string) (image.Image, error) {
+	return nil, fmt.Errorf("image generation: %w", ErrNotSupported)
+}
+
+// ExtractTextFromAudio is not supported by Anthropic.
+func (a *Anthropic) ExtractTextFromAudio(ctx context.Context, filePath string) (audio.Transcript, error) {
+	return audio.Transcript{}, fmt.Errorf("audio transcription: %w", ErrNotSupported)
+}
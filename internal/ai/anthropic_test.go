@@ -0,0 +1,90 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+func TestAnthropicGenerateStructured(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		answer  string
+		want    string
+		wantErr string
+	}{
+		{
+			"tool use",
+			http.StatusOK,
+			`{"content":[{"type":"text","text":"Here it is"},{"type":"tool_use","name":"slide","input":{"title":"Budget","body":"- Q1"}}],"stop_reason":"tool_use"}`,
+			"Budget",
+			"",
+		},
+		{
+			"error",
+			http.StatusBadRequest,
+			`{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens is too large"}}`,
+			"",
+			"invalid_request_error: max_tokens is too large",
+		},
+		{
+			"no tool use",
+			http.StatusOK,
+			`{"content":[{"type":"text","text":"I cannot"}],"stop_reason":"max_tokens"}`,
+			"",
+			"the model returned no slide (stop reason: max_tokens)",
+		},
+		{"unexpected status", http.StatusBadGateway, `{}`, "", "unexpected status 502 Bad Gateway"},
+		{"not json", http.StatusBadGateway, `Bad gateway`, "", "cannot decode answer (502 Bad Gateway)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request anthropicRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/messages" {
+					t.Errorf("path = %v", r.URL.Path)
+				}
+				if r.Header.Get("x-api-key") != "key" || r.Header.Get("anthropic-version") != anthropicVersion {
+					t.Errorf("headers = %v", r.Header)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+					t.Error(err)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.answer))
+			}))
+			defer server.Close()
+
+			a := &Anthropic{Client: server.Client(), BaseURL: server.URL, APIKey: "key", Model: "model", MaxTokens: 100}
+			var slide structure.Slide
+			err := a.GenerateStructured(context.Background(), "slide", "a slide", structure.SlideResponseSchema, "the prompt", &slide)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if slide.Title != tt.want {
+				t.Errorf("title = %q, want %q", slide.Title, tt.want)
+			}
+			if request.Model != "model" || request.MaxTokens != 100 || len(request.Messages) != 1 || request.Messages[0].Content != "the prompt" {
+				t.Errorf("request = %+v", request)
+			}
+			if len(request.Tools) != 1 || request.Tools[0].Name != "slide" || request.Tools[0].InputSchema == nil {
+				t.Errorf("tools = %+v", request.Tools)
+			}
+			if request.ToolChoice != (anthropicChoice{Type: "tool", Name: "slide"}) {
+				t.Errorf("tool choice = %+v", request.ToolChoice)
+			}
+		})
+	}
+}
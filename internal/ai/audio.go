@@ -1,43 +1,110 @@
 package ai
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
-	"io"
+	"log"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/openai/openai-go"
 	"github.com/owulveryck/gptslideshow/config"
+	"github.com/owulveryck/gptslideshow/internal/audio"
 )
 
-// ExtractTextFromAudio extracts text from an audio file using OpenAI's Whisper model.
-// It takes an audio file path as input and returns the transcribed text.
+// The bounds of the uploaded audio segments: the transcription API accepts files of up to 25 MB.
+const (
+	maxSegmentSize = 24 << 20
+	segmentOverlap = 3 * time.Second
+)
+
+// contentTypes gives the media type of the audio formats.
+var contentTypes = map[string]string{
+	audio.MP3:  "audio/mpeg",
+	audio.WAV:  "audio/wav",
+	audio.MP4:  "audio/mp4",
+	audio.M4A:  "audio/mp4",
+	audio.WebM: "audio/webm",
+	audio.Ogg:  "audio/ogg",
+	audio.FLAC: "audio/flac",
+}
+
+// ExtractTextFromAudio extracts text from an audio file using OpenAI's Whisper model, with the times of its passages
+// (the segments of the verbose transcription). The files larger than the upload limit or longer than AUDIO_SEGMENT_DURATION are split into overlapping segments
+// (see audio.Split), transcribed concurrently (AUDIO_PARALLELISM at a time), and their transcripts are stitched.
 //
 // Parameters:
 //   - ctx: The context for managing request deadlines and cancellation signals.
 //   - filePath: The path to the audio file to be transcribed.
 //
 // Returns:
-//   - A string containing the transcribed text.
-//   - An error if the transcription fails or if there is an issue with file handling.
-func (ai *AI) ExtractTextFromAudio(ctx context.Context, filePath string) (string, error) {
-	// Open the audio file for reading.
-	file, err := os.Open(filePath)
+//   - The transcript of the file, made of timed passages.
+//   - An error if the format of the file is not supported, if the transcription fails or if there is an issue with file handling.
+func (ai *AI) ExtractTextFromAudio(ctx context.Context, filePath string) (audio.Transcript, error) {
+	// Read the audio file and check its format before any upload.
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return audio.Transcript{}, fmt.Errorf("failed to open audio file: %w", err)
+	}
+	format, err := audio.Detect(filePath, data)
+	if err != nil {
+		return audio.Transcript{}, err
+	}
+	segments, err := audio.Split(format, data, audio.Limits{
+		MaxBytes:    maxSegmentSize,
+		MaxDuration: config.ConfigInstance.AudioSegmentDuration,
+		Overlap:     segmentOverlap,
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to open audio file: %w", err)
+		return audio.Transcript{}, fmt.Errorf("cannot split %v: %w", filePath, err)
+	}
+	if len(segments) > 1 {
+		log.Printf("%v is split into %d segments", filePath, len(segments))
 	}
-	defer file.Close()
 
-	// Request transcription from OpenAI's API using the Whisper model.
-	transcription, err := ai.Client.Audio.Transcriptions.New(ctx, openai.AudioTranscriptionNewParams{
-		Model:    openai.F(openai.AudioModelWhisper1),
-		File:     openai.F[io.Reader](file),
-		Language: openai.F(config.ConfigInstance.AudioLanguage),
+	// Request the transcription of each segment from OpenAI's API using the Whisper model.
+	name := filepath.Base(filePath)
+	transcript, err := audio.Transcribe(ctx, segments, config.ConfigInstance.AudioParallelism, func(ctx context.Context, s audio.Segment) (audio.Transcript, error) {
+		transcription, err := ai.Client.Audio.Transcriptions.New(ctx, openai.AudioTranscriptionNewParams{
+			Model:          openai.F(openai.AudioModelWhisper1),
+			File:           openai.FileParam(bytes.NewReader(s.Data), name, contentTypes[format]),
+			Language:       openai.F(config.ConfigInstance.AudioLanguage),
+			ResponseFormat: openai.F(openai.AudioResponseFormatVerboseJSON),
+			TimestampGranularities: openai.F([]openai.AudioTranscriptionNewParamsTimestampGranularity{
+				openai.AudioTranscriptionNewParamsTimestampGranularitySegment,
+			}),
+		})
+		if err != nil {
+			return audio.Transcript{}, err
+		}
+		return timedTranscript(transcription, s.End-s.Start), nil
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to transcribe audio: %w", err)
+		return audio.Transcript{}, fmt.Errorf("failed to transcribe audio: %w", err)
 	}
+	return transcript, nil
+}
 
-	// Return the transcribed text.
-	return transcription.Text, nil
+// timedTranscript returns the passages of a verbose transcription. A transcription without segments, such as the one
+// of a compatible server ignoring the response format, is a single passage lasting the whole segment.
+func timedTranscript(transcription *openai.Transcription, duration time.Duration) audio.Transcript {
+	var verbose struct {
+		Segments []struct {
+			Start float64 `json:"start"`
+			End   float64 `json:"end"`
+			Text  string  `json:"text"`
+		} `json:"segments"`
+	}
+	if err := json.Unmarshal([]byte(transcription.JSON.RawJSON()), &verbose); err != nil || len(verbose.Segments) == 0 {
+		return audio.Transcript{Cues: []audio.Cue{{End: duration, Text: transcription.Text}}}
+	}
+	seconds := func(s float64) time.Duration { return time.Duration(s * float64(time.Second)) }
+	var t audio.Transcript
+	for _, segment := range verbose.Segments {
+		t.Cues = append(t.Cues, audio.Cue{Start: seconds(segment.Start), End: seconds(segment.End), Text: segment.Text})
+	}
+	return t
 }
@@ -0,0 +1,37 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+)
+
+func TestTimedTranscript(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			"verbose",
+			`{"text":"Hello. The budget.","duration":4.2,"segments":[{"id":0,"start":0.0,"end":1.5,"text":" Hello."},{"id":1,"start":1.5,"end":4.2,"text":" The budget."}]}`,
+			"[{0s 1.5s  Hello.} {1.5s 4.2s  The budget.}]",
+		},
+		{"without segments", `{"text":"Hello. The budget."}`, "[{0s 10s Hello. The budget.}]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var transcription openai.Transcription
+			if err := json.Unmarshal([]byte(tt.body), &transcription); err != nil {
+				t.Fatal(err)
+			}
+			got := timedTranscript(&transcription, 10*time.Second)
+			if fmt.Sprint(got.Cues) != tt.want {
+				t.Errorf("timedTranscript() = %v, want %v", got.Cues, tt.want)
+			}
+		})
+	}
+}
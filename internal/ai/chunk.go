@@ -0,0 +1,151 @@
+package ai
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// headingLine matches an ATX heading: one to six # followed by a space (or the end of the line), so that a
+// #hashtag at the start of a line is not a heading.
+var headingLine = regexp.MustCompile(`^ {0,3}#{1,6}(\s|$)`)
+
+// EstimateTokens returns a local estimation of the number of tokens of the text.
+// It counts one token every four characters, which is the usual ratio for English text with the OpenAI tokenizers.
+// The estimation is deterministic so that the split of the content is reproducible.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// SplitContent splits the content into chunks of at most budget tokens (as estimated by EstimateTokens).
+// The content is first split into sections at the Markdown headings, then the consecutive sections are grouped
+// as long as they fit in the budget. A section larger than the budget is split at its paragraphs, then at its lines.
+func SplitContent(content string, budget int) []string {
+	if budget <= 0 || EstimateTokens(content) <= budget {
+		return []string{content}
+	}
+	var pieces []string
+	for _, section := range splitSections(content) {
+		pieces = append(pieces, splitPiece(section, budget)...)
+	}
+	return pack(pieces, budget)
+}
+
+// splitSections splits a Markdown document before each heading; the headings within fenced code blocks are ignored,
+// and a heading directly followed by another one (such as a chapter and its first section) stays with it.
+func splitSections(content string) []string {
+	var sections []string
+	var current strings.Builder
+	inCode := false
+	headingsOnly := true
+	for _, line := range strings.SplitAfter(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+		}
+		heading := !inCode && headingLine.MatchString(line)
+		if heading && current.Len() > 0 && !headingsOnly {
+			sections = append(sections, current.String())
+			current.Reset()
+			headingsOnly = true
+		}
+		if !heading && trimmed != "" {
+			headingsOnly = false
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		sections = append(sections, current.String())
+	}
+	return sections
+}
+
+// splitPiece splits the text so that every piece fits in the budget: at the paragraphs, then at the lines,
+// and as a last resort at the characters.
+func splitPiece(text string, budget int) []string {
+	if EstimateTokens(text) <= budget {
+		return []string{text}
+	}
+	for _, sep := range []string{"\n\n", "\n"} {
+		parts := strings.SplitAfter(strings.TrimSuffix(text, sep), sep)
+		parts[len(parts)-1] += text[len(strings.TrimSuffix(text, sep)):]
+		if len(parts) > 1 {
+			var pieces []string
+			for _, part := range parts {
+				pieces = append(pieces, splitPiece(part, budget)...)
+			}
+			return pack(pieces, budget)
+		}
+	}
+	runes := []rune(text)
+	size := budget * 4
+	var pieces []string
+	for len(runes) > size {
+		pieces = append(pieces, string(runes[:size]))
+		runes = runes[size:]
+	}
+	return append(pieces, string(runes))
+}
+
+// pack groups the consecutive pieces as long as they fit in the budget.
+func pack(pieces []string, budget int) []string {
+	var chunks []string
+	var current strings.Builder
+	var size int // number of runes of the current chunk
+	for _, piece := range pieces {
+		n := utf8.RuneCountInString(piece)
+		if size > 0 && (size+n+3)/4 > budget {
+			chunks = append(chunks, current.String())
+			current.Reset()
+			size = 0
+		}
+		current.WriteString(piece)
+		size += n
+	}
+	if size > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// RelevantChunk returns the content if it fits in budget tokens, or else the chunk of the content (see SplitContent)
+// sharing the most words with the text, such as the chunk a slide was generated from; the first one on a tie.
+// The words shorter than four letters are ignored, as most of them are stop words.
+func RelevantChunk(content, text string, budget int) string {
+	chunks := SplitContent(content, budget)
+	if len(chunks) == 1 {
+		return chunks[0]
+	}
+	words := make(map[string]bool)
+	for _, w := range significantWords(text) {
+		words[w] = true
+	}
+	best, bestScore := chunks[0], 0
+	for _, chunk := range chunks {
+		score := 0
+		for _, w := range significantWords(chunk) {
+			if words[w] {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = chunk, score
+		}
+	}
+	return best
+}
+
+// significantWords returns the lower case words of the text of at least four letters or digits.
+func significantWords(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words := fields[:0]
+	for _, w := range fields {
+		if utf8.RuneCountInString(w) >= 4 {
+			words = append(words, w)
+		}
+	}
+	return words
+}
@@ -0,0 +1,114 @@
+package ai
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abc", 1},
+		{"abcd", 1},
+		{"abcde", 2},
+		{"héhé", 1},
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSplitContent(t *testing.T) {
+	section := func(title string, size int) string {
+		return "# " + title + "\n" + strings.Repeat("a", size) + "\n\n"
+	}
+	tests := []struct {
+		name    string
+		content string
+		budget  int
+		want    []string
+	}{
+		{
+			name:    "fits in the budget",
+			content: section("one", 10) + section("two", 10),
+			budget:  100,
+			want:    []string{section("one", 10) + section("two", 10)},
+		},
+		{
+			name:    "split at the headings and grouped",
+			content: section("one", 30) + section("two", 30) + section("three", 100),
+			budget:  30,
+			want:    []string{section("one", 30) + section("two", 30), section("three", 100)},
+		},
+		{
+			name:    "headings in code blocks are ignored",
+			content: "# one\n```\n# not a heading\n```\n" + strings.Repeat("a", 40) + "\n# two\n" + strings.Repeat("b", 40) + "\n",
+			budget:  20,
+			want:    []string{"# one\n```\n# not a heading\n```\n" + strings.Repeat("a", 40) + "\n", "# two\n" + strings.Repeat("b", 40) + "\n"},
+		},
+		{
+			name:    "consecutive headings stay together",
+			content: "# Source: a.md\n\n# one\n" + strings.Repeat("a", 40) + "\n# Source: b.md\n# two\n" + strings.Repeat("b", 40) + "\n",
+			budget:  20,
+			want:    []string{"# Source: a.md\n\n# one\n" + strings.Repeat("a", 40) + "\n", "# Source: b.md\n# two\n" + strings.Repeat("b", 40) + "\n"},
+		},
+		{
+			name:    "large section split at the paragraphs",
+			content: "# one\n" + strings.Repeat("a", 30) + "\n\n" + strings.Repeat("b", 30) + "\n\n" + strings.Repeat("c", 30),
+			budget:  10,
+			want:    []string{"# one\n" + strings.Repeat("a", 30) + "\n\n", strings.Repeat("b", 30) + "\n\n", strings.Repeat("c", 30)},
+		},
+		{
+			name:    "large line split at the characters",
+			content: strings.Repeat("a", 10),
+			budget:  1,
+			want:    []string{"aaaa", "aaaa", "aa"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitContent(tt.content, tt.budget)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitContent() = %q, want %q", got, tt.want)
+			}
+			if strings.Join(got, "") != tt.content {
+				t.Errorf("SplitContent() lost some content")
+			}
+		})
+	}
+}
+
+func TestSplitSections(t *testing.T) {
+	content := "# one\n#budget is a tag\n\n    # indented code\n##two\n## three\n#\n"
+	want := []string{"# one\n#budget is a tag\n\n    # indented code\n##two\n", "## three\n#\n"}
+	if got := splitSections(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("splitSections() = %q, want %q", got, want)
+	}
+}
+
+func TestRelevantChunk(t *testing.T) {
+	content := "# Budget\n" + strings.Repeat("The budget grows every year. ", 4) + "\n# Hiring\n" + strings.Repeat("The team hires engineers. ", 4) + "\n"
+	tests := []struct {
+		name   string
+		text   string
+		budget int
+		want   string
+	}{
+		{"fits in the budget", "Hiring", 1000, content},
+		{"most relevant chunk", "Title: Hiring plan\nBody:\n- Two engineers", 40, "# Hiring\n" + strings.Repeat("The team hires engineers. ", 4) + "\n"},
+		{"first chunk on a tie", "Title: Roadmap", 40, "# Budget\n" + strings.Repeat("The budget grows every year. ", 4) + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RelevantChunk(content, tt.text, tt.budget); got != tt.want {
+				t.Errorf("RelevantChunk() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"strings"
 
 	"github.com/openai/openai-go"
 
@@ -12,87 +12,75 @@ import (
 	"github.com/owulveryck/gptslideshow/internal/structure"
 )
 
-// GenerateSlide
-func (ai *AI) GenerateSlide(ctx context.Context, preprompt string, content []byte) (*structure.Slide, error) {
-	schemaParam := openai.ResponseFormatJSONSchemaJSONSchemaParam{
-		Name:        openai.F("presentation"),
-		Description: openai.F("A structured slide from content"),
-		Schema:      openai.F(structure.SlideResponseSchema),
-		Strict:      openai.Bool(true),
+// GenerateStructured prompts the model and decodes its answer, constrained by the JSON schema, into v.
+// In compatibility mode, the schema is given in the prompt and the model is only asked for a JSON object.
+func (ai *AI) GenerateStructured(ctx context.Context, name, description string, schema interface{}, prompt string, v interface{}) error {
+	var responseFormat openai.ChatCompletionNewParamsResponseFormatUnion = openai.ResponseFormatJSONSchemaParam{
+		Type: openai.F(openai.ResponseFormatJSONSchemaTypeJSONSchema),
+		JSONSchema: openai.F(openai.ResponseFormatJSONSchemaJSONSchemaParam{
+			Name:        openai.F(name),
+			Description: openai.F(description),
+			Schema:      openai.F(schema),
+			Strict:      openai.Bool(true),
+		}),
+	}
+	if ai.Compatible {
+		var err error
+		if prompt, err = schemaPrompt(name, description, schema, prompt); err != nil {
+			return err
+		}
+		responseFormat = openai.ResponseFormatJSONObjectParam{
+			Type: openai.F(openai.ResponseFormatJSONObjectTypeJSONObject),
+		}
 	}
-
-	// Prompt to guide the model
-	prompt := fmt.Sprintf(preprompt+`
-
-		%s`, string(content))
-	log.Printf("\n\nPrompting with: %s ...\n\n", prompt[:50])
 
 	// Query OpenAI API for validation or enhancement (optional)
 	chat, err := ai.Client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
 		Messages: openai.F([]openai.ChatCompletionMessageParamUnion{
 			openai.UserMessage(prompt),
 		}),
-		ResponseFormat: openai.F[openai.ChatCompletionNewParamsResponseFormatUnion](
-			openai.ResponseFormatJSONSchemaParam{
-				Type:       openai.F(openai.ResponseFormatJSONSchemaTypeJSONSchema),
-				JSONSchema: openai.F(schemaParam),
-			},
-		),
-		Model: openai.F(config.ConfigInstance.OpenAIModel),
+		ResponseFormat: openai.F(responseFormat),
+		Model:          openai.F(config.ConfigInstance.OpenAIModel),
 		// Model: openai.F(openai.ChatModelGPT4o2024_08_06),
 	})
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if len(chat.Choices) == 0 {
+		return fmt.Errorf("the model returned no answer")
 	}
 
 	// Parse the model's response
-	var slide structure.Slide
-	err = json.Unmarshal([]byte(chat.Choices[0].Message.Content), &slide)
+	return json.Unmarshal([]byte(jsonAnswer(chat.Choices[0].Message.Content)), v)
+}
+
+// schemaPrompt returns the prompt followed by the instruction to answer with the named JSON object valid against the
+// schema.
+func schemaPrompt(name, description string, schema interface{}, prompt string) (string, error) {
+	b, err := json.Marshal(schema)
 	if err != nil {
-		return nil, err
+		return "", fmt.Errorf("cannot encode the schema of %v: %w", name, err)
 	}
-	return &slide, err
+	return fmt.Sprintf("%v\n\nAnswer with a single JSON object, the %v (%v), valid against this JSON schema:\n%s", prompt, name, description, b), nil
 }
 
-// GeneratePresentationFromText generates a presentation from Markdown content
-func (ai *AI) GeneratePresentationFromText(ctx context.Context, preprompt string, content []byte) (*structure.Presentation, error) {
-	schemaParam := openai.ResponseFormatJSONSchemaJSONSchemaParam{
-		Name:        openai.F("presentation"),
-		Description: openai.F("A structured presentation from content"),
-		Schema:      openai.F(structure.PresentationResponseSchema),
-		Strict:      openai.Bool(true),
+// jsonAnswer returns the JSON object of the answer, without the code fence some models wrap it in.
+func jsonAnswer(content string) string {
+	content = strings.TrimSpace(content)
+	if strings.HasPrefix(content, "```") {
+		content = strings.TrimPrefix(content, "```json")
+		content = strings.TrimPrefix(content, "```")
+		content = strings.TrimSuffix(content, "```")
 	}
+	return strings.TrimSpace(content)
+}
 
-	// Prompt to guide the model
-	prompt := fmt.Sprintf(preprompt+`
-
-		%s`, string(content))
-	log.Printf("\n\nPrompting with: %s ...\n\n", prompt[:500])
-
-	// Query OpenAI API for validation or enhancement (optional)
-	chat, err := ai.Client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
-		Messages: openai.F([]openai.ChatCompletionMessageParamUnion{
-			openai.UserMessage(prompt),
-		}),
-		ResponseFormat: openai.F[openai.ChatCompletionNewParamsResponseFormatUnion](
-			openai.ResponseFormatJSONSchemaParam{
-				Type:       openai.F(openai.ResponseFormatJSONSchemaTypeJSONSchema),
-				JSONSchema: openai.F(schemaParam),
-			},
-		),
-		Model: openai.F(config.ConfigInstance.OpenAIModel),
-		// Model: openai.F(openai.ChatModelGPT4o2024_08_06),
-	})
-	if err != nil {
-		return nil, err
-	}
+// GenerateSlide
+func (ai *AI) GenerateSlide(ctx context.Context, preprompt string, content []byte) (*structure.Slide, error) {
+	return generateSlide(ctx, ai, preprompt, content)
+}
 
-	// Parse the model's response
-	var presentation structure.Presentation
-	err = json.Unmarshal([]byte(chat.Choices[0].Message.Content), &presentation)
-	if err != nil {
-		return nil, err
-	}
-	log.Printf("Generated %d slides", len(presentation.Slides))
-	return &presentation, err
+// GeneratePresentationFromText generates a presentation from Markdown content
+func (ai *AI) GeneratePresentationFromText(ctx context.Context, preprompt string, content []byte) (*structure.Presentation, error) {
+	return generatePresentation(ctx, ai, preprompt, content)
 }
@@ -0,0 +1,72 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+func TestGenerateStructured(t *testing.T) {
+	tests := []struct {
+		name       string
+		compatible bool
+		answer     string
+		wantFormat string
+		wantSchema bool // whether the schema is in the prompt
+	}{
+		{"strict", false, `{"title":"Budget"}`, "json_schema", false},
+		{"compatible", true, `{"title":"Budget"}`, "json_object", true},
+		{"compatible fenced", true, "```json\n{\"title\":\"Budget\"}\n```", "json_object", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request struct {
+				Messages []struct {
+					Content json.RawMessage `json:"content"` // a string or text parts
+				} `json:"messages"`
+				ResponseFormat struct {
+					Type string `json:"type"`
+				} `json:"response_format"`
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+					t.Error(err)
+				}
+				answer, _ := json.Marshal(tt.answer)
+				w.Header().Set("content-type", "application/json")
+				w.Write([]byte(`{"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":` + string(answer) + `}}]}`))
+			}))
+			defer server.Close()
+
+			client := &AI{
+				Client:     openai.NewClient(option.WithBaseURL(server.URL), option.WithAPIKey("key"), option.WithMaxRetries(0)),
+				Compatible: tt.compatible,
+			}
+			var slide structure.Slide
+			if err := client.GenerateStructured(context.Background(), "slide", "a slide", structure.SlideResponseSchema, "the prompt", &slide); err != nil {
+				t.Fatal(err)
+			}
+			if slide.Title != "Budget" {
+				t.Errorf("title = %q", slide.Title)
+			}
+			if request.ResponseFormat.Type != tt.wantFormat {
+				t.Errorf("response format = %v, want %v", request.ResponseFormat.Type, tt.wantFormat)
+			}
+			if len(request.Messages) != 1 {
+				t.Fatalf("messages = %+v", request.Messages)
+			}
+			prompt := string(request.Messages[0].Content)
+			if !strings.Contains(prompt, "the prompt") || strings.Contains(prompt, "properties") != tt.wantSchema {
+				t.Errorf("prompt = %v", prompt)
+			}
+		})
+	}
+}
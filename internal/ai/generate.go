@@ -0,0 +1,45 @@
+package ai
+
+import (
+	"context"
+	"log"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// generateSlide implements Provider.GenerateSlide on top of Provider.GenerateStructured.
+func generateSlide(ctx context.Context, p Provider, preprompt string, content []byte) (*structure.Slide, error) {
+	// Prompt to guide the model
+	prompt := preprompt + "\n\n\t\t" + string(content)
+	log.Printf("\n\nPrompting with: %s ...\n\n", truncate(prompt, 50))
+
+	var slide structure.Slide
+	err := p.GenerateStructured(ctx, "presentation", "A structured slide from content", structure.SlideResponseSchema, prompt, &slide)
+	if err != nil {
+		return nil, err
+	}
+	return &slide, nil
+}
+
+// generatePresentation implements Provider.GeneratePresentationFromText on top of Provider.GenerateStructured.
+func generatePresentation(ctx context.Context, p Provider, preprompt string, content []byte) (*structure.Presentation, error) {
+	// Prompt to guide the model
+	prompt := preprompt + "\n\n\t\t" + string(content)
+	log.Printf("\n\nPrompting with: %s ...\n\n", truncate(prompt, 500))
+
+	var presentation structure.Presentation
+	err := p.GenerateStructured(ctx, "presentation", "A structured presentation from content", structure.PresentationResponseSchema, prompt, &presentation)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Generated %d slides", len(presentation.Slides))
+	return &presentation, nil
+}
+
+// truncate returns the n first bytes of s.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
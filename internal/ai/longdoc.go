@@ -0,0 +1,135 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// sectionPrompt is appended to the prompt of each chunk of a long document (the map step).
+const sectionPrompt = `
+This content is the part %d of %d of a larger document. Convert only this part; do not generate an executive summary, it will be generated for the whole document.
+`
+
+// mergePrompt is the prompt of the reduce step.
+const mergePrompt = `The following JSON document holds the partial presentations generated from the consecutive parts of a long document.
+Merge them into a single coherent presentation:
+- keep the order of the content and the level of detail of the slides,
+- make the chapters consistent and remove the duplicated slides or chapters,
+- the first slide must be an executive summary of the whole document,
+- give a title and a subtitle to the whole presentation.
+
+Here are the partial presentations:
+
+`
+
+// GenerateLongPresentation generates a presentation from a content that may exceed the context window of the model.
+// If the content fits in budget tokens, it is equivalent to GeneratePresentationFromText.
+// Otherwise the content is split with SplitContent, each chunk is converted into slides (map),
+// and the partial presentations are merged by the model into a coherent presentation with a global executive summary (reduce).
+// If the partial presentations exceed the budget, or the output tokens the model can emit in its answer, they are
+// concatenated and only the title and the executive summary are generated.
+func GenerateLongPresentation(ctx context.Context, p Provider, preprompt string, content []byte, budget, output int) (*structure.Presentation, error) {
+	chunks := SplitContent(string(content), budget)
+	if len(chunks) == 1 {
+		return p.GeneratePresentationFromText(ctx, preprompt, content)
+	}
+	log.Printf("The content (about %d tokens) is split into %d parts", EstimateTokens(string(content)), len(chunks))
+
+	partials := make([]*structure.Presentation, len(chunks))
+	for i, chunk := range chunks {
+		partial, err := p.GeneratePresentationFromText(ctx, preprompt+fmt.Sprintf(sectionPrompt, i+1, len(chunks)), []byte(chunk))
+		if err != nil {
+			return nil, fmt.Errorf("cannot generate the part %d of %d: %w", i+1, len(chunks), err)
+		}
+		partials[i] = partial
+	}
+	return mergePresentations(ctx, p, preprompt, partials, budget, output)
+}
+
+// mergePresentations merges the partial presentations (the reduce step). The model re-emits the whole presentation,
+// so it merges them only if they fit both in the budget of the prompt and in the output tokens of its answer.
+func mergePresentations(ctx context.Context, p Provider, preprompt string, partials []*structure.Presentation, budget, output int) (*structure.Presentation, error) {
+	b, err := json.Marshal(partials)
+	if err != nil {
+		return nil, err
+	}
+	prompt := preprompt + "\n" + mergePrompt + string(b)
+	if EstimateTokens(prompt) <= budget && EstimateTokens(string(b)) <= output {
+		var merged structure.Presentation
+		err := p.GenerateStructured(ctx, "presentation", "A structured presentation merged from partial presentations", structure.PresentationResponseSchema, prompt, &merged)
+		if err != nil {
+			return nil, fmt.Errorf("cannot merge the partial presentations: %w", err)
+		}
+		log.Printf("Merged %d parts into %d slides", len(partials), len(merged.Slides))
+		return &merged, nil
+	}
+
+	// The partial presentations are too large to be merged by the model: concatenate them and generate the summary only
+	merged := &structure.Presentation{}
+	for _, partial := range partials {
+		merged.Slides = append(merged.Slides, partial.Slides...)
+	}
+	if err := summarize(ctx, p, preprompt, merged, partials); err != nil {
+		return nil, err
+	}
+	log.Printf("Concatenated %d parts into %d slides", len(partials), len(merged.Slides))
+	return merged, nil
+}
+
+// ChapterPresentations assembles the presentations generated from several sources into a single presentation with
+// a chapter per source. The chapter slide is titled after the presentation of the source (or the name of the source),
+// and its body is the executive summary of the source; the other slides of the source follow.
+// The title, the subtitle and the executive summary of the whole presentation are generated from its outline,
+// following the preprompt.
+func ChapterPresentations(ctx context.Context, p Provider, preprompt string, partials []*structure.Presentation, sources []string) (*structure.Presentation, error) {
+	merged := &structure.Presentation{}
+	for i, partial := range partials {
+		chapter := structure.Slide{Title: partial.Title, Subtitle: partial.Subtitle, Notes: "Source: " + sources[i], Chapter: true}
+		if chapter.Title == "" {
+			chapter.Title = sources[i]
+		}
+		slides := partial.Slides
+		if len(slides) > 0 && !slides[0].Chapter {
+			// The executive summary of the source describes the chapter
+			chapter.Body = slides[0].Body
+			slides = slides[1:]
+		}
+		merged.Slides = append(merged.Slides, chapter)
+		merged.Slides = append(merged.Slides, slides...)
+	}
+	if err := summarize(ctx, p, preprompt, merged, partials); err != nil {
+		return nil, err
+	}
+	log.Printf("Assembled %d sources into %d slides", len(partials), len(merged.Slides))
+	return merged, nil
+}
+
+// summarize generates the title, the subtitle and the executive summary of the presentation from the outline of its
+// partial presentations, following the preprompt (the language and the tone of the presentation);
+// the executive summary is inserted as the first slide.
+func summarize(ctx context.Context, p Provider, preprompt string, merged *structure.Presentation, partials []*structure.Presentation) error {
+	var outline strings.Builder
+	for _, partial := range partials {
+		fmt.Fprintf(&outline, "# %s\n%s\n", partial.Title, partial.Subtitle)
+		for _, slide := range partial.Slides {
+			fmt.Fprintf(&outline, "- %s: %s\n", slide.Title, slide.Subtitle)
+		}
+	}
+	var summary structure.Presentation
+	err := p.GenerateStructured(ctx, "presentation", "A presentation title with its executive summary", structure.PresentationResponseSchema,
+		preprompt+"\nHere is the outline of a presentation. Give it a title, a subtitle, and generate a single slide: its executive summary.\n\n"+outline.String(), &summary)
+	if err != nil {
+		return fmt.Errorf("cannot generate the executive summary: %w", err)
+	}
+	merged.Title = summary.Title
+	merged.Subtitle = summary.Subtitle
+	if len(summary.Slides) > 0 {
+		merged.Slides = append([]structure.Slide{summary.Slides[0]}, merged.Slides...)
+	}
+	return nil
+}
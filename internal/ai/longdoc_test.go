@@ -0,0 +1,135 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/owulveryck/gptslideshow/internal/audio"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// fakeProvider generates one slide per call, titled after the first line of the content.
+type fakeProvider struct {
+	generations int
+	merges      int
+	prompts     []string // the prompts of GenerateStructured
+}
+
+func (f *fakeProvider) GenerateStructured(ctx context.Context, name, description string, schema interface{}, prompt string, v interface{}) error {
+	f.merges++
+	f.prompts = append(f.prompts, prompt)
+	_, partialsJSON, merge := strings.Cut(prompt, mergePrompt)
+	if !merge {
+		// The executive summary of the concatenated presentations
+		summary := v.(*structure.Presentation)
+		summary.Title = "summary"
+		summary.Slides = []structure.Slide{{Title: "Executive summary"}}
+		return nil
+	}
+	var partials []*structure.Presentation
+	if err := json.Unmarshal([]byte(partialsJSON), &partials); err != nil {
+		return err
+	}
+	merged := v.(*structure.Presentation)
+	merged.Title = "merged"
+	merged.Slides = []structure.Slide{{Title: "Executive summary"}}
+	for _, partial := range partials {
+		merged.Slides = append(merged.Slides, partial.Slides...)
+	}
+	return nil
+}
+
+func (f *fakeProvider) GeneratePresentationFromText(ctx context.Context, preprompt string, content []byte) (*structure.Presentation, error) {
+	f.generations++
+	title, _, _ := strings.Cut(string(content), "\n")
+	return &structure.Presentation{Slides: []structure.Slide{{Title: title}}}, nil
+}
+
+func (f *fakeProvider) GenerateSlide(ctx context.Context, preprompt string, content []byte) (*structure.Slide, error) {
+	return nil, ErrNotSupported
+}
+
+func (f *fakeProvider) GenerateImageFromText(ctx context.Context, prompt string) (image.Image, error) {
+	return nil, ErrNotSupported
+}
+
+func (f *fakeProvider) ExtractTextFromAudio(ctx context.Context, filePath string) (audio.Transcript, error) {
+	return audio.Transcript{}, ErrNotSupported
+}
+
+func TestGenerateLongPresentation(t *testing.T) {
+	content := "# one\n" + strings.Repeat("a", 600) + "\n# two\n" + strings.Repeat("b", 600) + "\n"
+	tests := []struct {
+		name        string
+		budget      int
+		output      int
+		generations int
+		merges      int
+		title       string
+		slides      string
+	}{
+		{"fits in the budget", 1000, 1000, 1, 0, "", "# one"},
+		{"merged by the model", 280, 1000, 2, 1, "merged", "Executive summary,# one,# two"},
+		{"too large to be merged", 160, 1000, 2, 1, "summary", "Executive summary,# one,# two"},
+		{"too large to be emitted", 280, 10, 2, 1, "summary", "Executive summary,# one,# two"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeProvider{}
+			p, err := GenerateLongPresentation(context.Background(), f, "prompt", []byte(content), tt.budget, tt.output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.generations != tt.generations || f.merges != tt.merges {
+				t.Errorf("got %v generations and %v merges, want %v and %v", f.generations, f.merges, tt.generations, tt.merges)
+			}
+			var titles []string
+			for _, slide := range p.Slides {
+				titles = append(titles, slide.Title)
+			}
+			if p.Title != tt.title || strings.Join(titles, ",") != tt.slides {
+				t.Errorf("got presentation %q with slides %v, want %q with %v", p.Title, strings.Join(titles, ","), tt.title, tt.slides)
+			}
+			for _, prompt := range f.prompts {
+				if !strings.HasPrefix(prompt, "prompt\n") {
+					t.Errorf("got prompt %q, want the preprompt first", prompt)
+				}
+			}
+		})
+	}
+}
+
+func TestChapterPresentations(t *testing.T) {
+	partials := []*structure.Presentation{
+		{Title: "Week 1", Slides: []structure.Slide{{Title: "Summary", Body: "What happened"}, {Title: "Done"}}},
+		{Slides: []structure.Slide{{Title: "Chapter", Chapter: true}, {Title: "Todo"}}},
+	}
+	f := &fakeProvider{}
+	p, err := ChapterPresentations(context.Background(), f, "prompt", partials, []string{"week1.md", "week2.mp3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []structure.Slide{
+		{Title: "Executive summary"},
+		{Title: "Week 1", Body: "What happened", Notes: "Source: week1.md", Chapter: true},
+		{Title: "Done"},
+		{Title: "week2.mp3", Notes: "Source: week2.mp3", Chapter: true},
+		{Title: "Chapter", Chapter: true},
+		{Title: "Todo"},
+	}
+	if p.Title != "summary" || f.merges != 1 {
+		t.Errorf("got title %q after %v calls, want %q after 1", p.Title, f.merges, "summary")
+	}
+	if len(p.Slides) != len(want) {
+		t.Fatalf("got %v slides, want %v", len(p.Slides), len(want))
+	}
+	for i := range want {
+		got := p.Slides[i]
+		if got.Title != want[i].Title || got.Body != want[i].Body || got.Notes != want[i].Notes || got.Chapter != want[i].Chapter {
+			t.Errorf("slide %v = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"sync"
+
+	"github.com/owulveryck/gptslideshow/config"
+	"github.com/owulveryck/gptslideshow/internal/audio"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// The supported values of the AI_PROVIDER configuration.
+const (
+	// ProviderOpenAI is the OpenAI API.
+	ProviderOpenAI = "openai"
+	// ProviderOpenAICompatible is any server implementing the OpenAI API (such as llama.cpp or Ollama) reachable at AI_BASE_URL.
+	ProviderOpenAICompatible = "openai-compatible"
+	// ProviderAnthropic is the Anthropic messages API.
+	ProviderAnthropic = "anthropic"
+)
+
+// ErrNotSupported is returned when a provider does not support a feature (such as image generation).
+var ErrNotSupported = errors.New("not supported by the provider")
+
+// Provider is a large language model able to generate the content of a presentation.
+type Provider interface {
+	// GenerateStructured prompts the model and decodes its answer, constrained by the JSON schema, into v.
+	GenerateStructured(ctx context.Context, name, description string, schema interface{}, prompt string, v interface{}) error
+
+	// GeneratePresentationFromText generates a presentation from the content.
+	GeneratePresentationFromText(ctx context.Context, preprompt string, content []byte) (*structure.Presentation, error)
+
+	// GenerateSlide generates a single slide from the content.
+	GenerateSlide(ctx context.Context, preprompt string, content []byte) (*structure.Slide, error)
+
+	// GenerateImageFromText generates an illustration from its description.
+	GenerateImageFromText(ctx context.Context, prompt string) (image.Image, error)
+
+	// ExtractTextFromAudio transcribes an audio file, with the times of its passages.
+	ExtractTextFromAudio(ctx context.Context, filePath string) (audio.Transcript, error)
+}
+
+// New returns the provider selected by the configuration.
+func New(cfg *config.Config) (Provider, error) {
+	if err := Check(cfg); err != nil {
+		return nil, err
+	}
+	switch cfg.AIProvider {
+	case ProviderOpenAICompatible:
+		client := NewAI()
+		client.Compatible = true
+		return client, nil
+	case ProviderAnthropic:
+		return NewAnthropic(cfg)
+	default:
+		return NewAI(), nil
+	}
+}
+
+// Check reports why the provider selected by the configuration cannot be used, without creating it: the provider
+// is unknown, it needs a setting, or it does not support a feature the configuration asks for.
+func Check(cfg *config.Config) error {
+	switch cfg.AIProvider {
+	case ProviderOpenAI, "":
+	case ProviderOpenAICompatible:
+		if cfg.AIBaseURL == "" {
+			return fmt.Errorf("the %v provider needs AI_BASE_URL", ProviderOpenAICompatible)
+		}
+	case ProviderAnthropic:
+		if cfg.WithImage {
+			return fmt.Errorf("the %v provider does not generate images: unset WITH_IMAGE", ProviderAnthropic)
+		}
+	default:
+		return fmt.Errorf("unknown AI provider %q", cfg.AIProvider)
+	}
+	return nil
+}
+
+// NewLazy returns the provider selected by the configuration, created by New on its first call, so that the
+// commands that do not call the model (such as the outline mode) need no API key. The configuration should be
+// checked first with Check.
+func NewLazy(cfg *config.Config) Provider {
+	return &lazyProvider{cfg: cfg}
+}
+
+// lazyProvider is a provider created on its first call.
+type lazyProvider struct {
+	cfg  *config.Config
+	once sync.Once
+	p    Provider
+	err  error
+}
+
+// provider creates the provider on the first call, and returns it or the error of its creation.
+func (l *lazyProvider) provider() (Provider, error) {
+	l.once.Do(func() {
+		l.p, l.err = New(l.cfg)
+	})
+	return l.p, l.err
+}
+
+// GenerateStructured creates the provider if needed and calls its GenerateStructured.
+func (l *lazyProvider) GenerateStructured(ctx context.Context, name, description string, schema interface{}, prompt string, v interface{}) error {
+	p, err := l.provider()
+	if err != nil {
+		return err
+	}
+	return p.GenerateStructured(ctx, name, description, schema, prompt, v)
+}
+
+// GeneratePresentationFromText creates the provider if needed and calls its GeneratePresentationFromText.
+func (l *lazyProvider) GeneratePresentationFromText(ctx context.Context, preprompt string, content []byte) (*structure.Presentation, error) {
+	p, err := l.provider()
+	if err != nil {
+		return nil, err
+	}
+	return p.GeneratePresentationFromText(ctx, preprompt, content)
+}
+
+// GenerateSlide creates the provider if needed and calls its GenerateSlide.
+func (l *lazyProvider) GenerateSlide(ctx context.Context, preprompt string, content []byte) (*structure.Slide, error) {
+	p, err := l.provider()
+	if err != nil {
+		return nil, err
+	}
+	return p.GenerateSlide(ctx, preprompt, content)
+}
+
+// GenerateImageFromText creates the provider if needed and calls its GenerateImageFromText.
+func (l *lazyProvider) GenerateImageFromText(ctx context.Context, prompt string) (image.Image, error) {
+	p, err := l.provider()
+	if err != nil {
+		return nil, err
+	}
+	return p.GenerateImageFromText(ctx, prompt)
+}
+
+// ExtractTextFromAudio creates the provider if needed and calls its ExtractTextFromAudio.
+func (l *lazyProvider) ExtractTextFromAudio(ctx context.Context, filePath string) (audio.Transcript, error) {
+	p, err := l.provider()
+	if err != nil {
+		return audio.Transcript{}, err
+	}
+	return p.ExtractTextFromAudio(ctx, filePath)
+}
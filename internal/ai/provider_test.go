@@ -0,0 +1,45 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/owulveryck/gptslideshow/config"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		wantErr string
+	}{
+		{"openai", config.Config{AIProvider: ProviderOpenAI, WithImage: true}, ""},
+		{"compatible", config.Config{AIProvider: ProviderOpenAICompatible, AIBaseURL: "http://localhost:11434/v1"}, ""},
+		{"compatible without url", config.Config{AIProvider: ProviderOpenAICompatible}, "needs AI_BASE_URL"},
+		{"anthropic without key", config.Config{AIProvider: ProviderAnthropic}, ""},
+		{"anthropic with images", config.Config{AIProvider: ProviderAnthropic, WithImage: true}, "does not generate images"},
+		{"unknown", config.Config{AIProvider: "mistral"}, "unknown AI provider"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(&tt.cfg)
+			if (err == nil) != (tt.wantErr == "") || err != nil && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewLazy(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	// The missing key is only reported by the first call of the model
+	p := NewLazy(&config.Config{AIProvider: ProviderAnthropic})
+	_, err := p.GenerateSlide(context.Background(), "prompt", []byte("content"))
+	if err == nil || !strings.Contains(err.Error(), "needs AI_API_KEY") {
+		t.Errorf("got error %v, want the missing key", err)
+	}
+	if _, err := p.GeneratePresentationFromText(context.Background(), "prompt", []byte("content")); err == nil {
+		t.Error("got no error on the second call")
+	}
+}
@@ -0,0 +1,109 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// critiquePrompt is the prompt of the critique of a presentation against its content.
+const critiquePrompt = `You are reviewing a presentation generated from a content. Compare the presentation with the content and critique it:
+- coverage: list the sections, facts or figures of the content the presentation misses,
+- redundancy: list the slides that repeat another slide, by title,
+- ordering: list the slides that break the order of the content or the logical flow, and where they belong,
+- slide count: give the number of slides the presentation should have to cover the content without redundancy.
+Only report actual problems: a presentation needing no revision is satisfactory, with empty lists.
+The markers such as [[table 1]] stand for tables inserted after the review: a slide whose body is a marker covers its table.
+
+`
+
+// revisePrompt is the prompt of the revision of a presentation according to its critique.
+const revisePrompt = `Revise the following presentation according to its critique and to the content it is generated from: add the slides covering what is missing, merge or remove the redundant slides, reorder the slides, and aim at the slide count of the critique.
+Keep the title, the subtitle, the executive summary as the first slide, the chapters, and the fields of the slides that the critique does not question: the speaker notes, the code, the tables, the charts, the audio spans, and the table markers such as [[table 1]].
+
+`
+
+// Critique is the review of a presentation against the content it is generated from.
+type Critique struct {
+	Missing      []string `json:"missing" jsonschema_description:"The sections, facts or figures of the content the presentation does not cover"`
+	Redundant    []string `json:"redundant" jsonschema_description:"The titles of the slides repeating another slide, with the slide they repeat"`
+	Ordering     []string `json:"ordering" jsonschema_description:"The slides out of the order of the content or of the logical flow, and where they belong"`
+	TargetSlides int      `json:"target_slides" jsonschema_description:"The number of slides the presentation should have to cover the content without redundancy"`
+	Satisfactory bool     `json:"satisfactory" jsonschema_description:"True if the presentation needs no revision"`
+}
+
+// CritiqueResponseSchema is the JSON schema of a Critique.
+var CritiqueResponseSchema = structure.GenerateSchema[Critique]()
+
+// settled reports whether the critique of a presentation of n slides asks for no revision.
+func (c Critique) settled(n int) bool {
+	if c.Satisfactory {
+		return true
+	}
+	return len(c.Missing) == 0 && len(c.Redundant) == 0 && len(c.Ordering) == 0 && (c.TargetSlides <= 0 || c.TargetSlides == n)
+}
+
+// ReviewPresentation improves a generated presentation by successive passes of critique and revision: the model
+// critiques the presentation against its content (coverage, redundancy, ordering and slide count), then revises it
+// according to the critique. The review stops after passes passes, or as soon as a critique asks for no revision.
+// A content too long to be sent along with the presentation in budget tokens is not reviewed, and a presentation
+// too long to be re-emitted in output tokens is not revised.
+// If a critique or a revision fails, the last revision is returned along with the error.
+//
+// Parameters:
+//   - ctx: A context to manage request lifetime.
+//   - p: The model.
+//   - presentation: The generated presentation.
+//   - content: The content the presentation is generated from.
+//   - passes: The maximum number of revisions; 0 does not review the presentation.
+//   - budget: The maximum number of tokens of a prompt (see EstimateTokens).
+//   - output: The maximum number of tokens of an answer of the model.
+//
+// Returns:
+//   - *structure.Presentation: The revised presentation, or the presentation if it is not revised.
+//   - []Critique: The critiques of the passes, in order.
+//   - error: An error if a critique or a revision fails.
+func ReviewPresentation(ctx context.Context, p Provider, presentation *structure.Presentation, content []byte, passes int, budget, output int) (*structure.Presentation, []Critique, error) {
+	var critiques []Critique
+	for pass := 1; pass <= passes; pass++ {
+		b, err := json.Marshal(presentation)
+		if err != nil {
+			return presentation, critiques, err
+		}
+		document := "Presentation:\n\n" + string(b) + "\n\nContent:\n\n" + string(content)
+		if EstimateTokens(revisePrompt+document) > budget {
+			log.Printf("The presentation and its content (about %d tokens) are too long to be reviewed", EstimateTokens(document))
+			break
+		}
+		if EstimateTokens(string(b)) > output {
+			log.Printf("The presentation (about %d tokens) is too long to be revised", EstimateTokens(string(b)))
+			break
+		}
+
+		var critique Critique
+		if err := p.GenerateStructured(ctx, "critique", "A critique of a presentation against its content", CritiqueResponseSchema, critiquePrompt+document, &critique); err != nil {
+			return presentation, critiques, fmt.Errorf("cannot critique the presentation (pass %d): %w", pass, err)
+		}
+		critiques = append(critiques, critique)
+		if critique.settled(len(presentation.Slides)) {
+			log.Printf("Review pass %d: the presentation needs no revision", pass)
+			break
+		}
+		log.Printf("Review pass %d: %d missing, %d redundant and %d misplaced, %d slides wanted instead of %d", pass, len(critique.Missing), len(critique.Redundant), len(critique.Ordering), critique.TargetSlides, len(presentation.Slides))
+
+		c, err := json.Marshal(critique)
+		if err != nil {
+			return presentation, critiques, err
+		}
+		var revised structure.Presentation
+		if err := p.GenerateStructured(ctx, "presentation", "A structured presentation revised according to its critique", structure.PresentationResponseSchema, revisePrompt+"Critique:\n\n"+string(c)+"\n\n"+document, &revised); err != nil {
+			return presentation, critiques, fmt.Errorf("cannot revise the presentation (pass %d): %w", pass, err)
+		}
+		revised.OriginalContent = presentation.OriginalContent
+		presentation = &revised
+	}
+	return presentation, critiques, nil
+}
@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// reviewProvider critiques the presentations with the critiques in turn, and revises them by adding a slide.
+type reviewProvider struct {
+	fakeProvider
+	critiques []Critique
+	calls     []string
+	failAt    int // the number of the failing call, if any
+}
+
+func (r *reviewProvider) GenerateStructured(ctx context.Context, name, description string, schema interface{}, prompt string, v interface{}) error {
+	r.calls = append(r.calls, name)
+	if len(r.calls) == r.failAt {
+		return errors.New("unavailable")
+	}
+	switch v := v.(type) {
+	case *Critique:
+		*v = r.critiques[0]
+		r.critiques = r.critiques[1:]
+	case *structure.Presentation:
+		count := strings.Count(prompt, `"title":"slide`)
+		v.Title = "revised"
+		for i := 0; i <= count; i++ {
+			v.Slides = append(v.Slides, structure.Slide{Title: "slide"})
+		}
+	}
+	return nil
+}
+
+func TestReviewPresentation(t *testing.T) {
+	missing := Critique{Missing: []string{"the conclusion"}, TargetSlides: 3}
+	tests := []struct {
+		name      string
+		passes    int
+		budget    int
+		output    int
+		critiques []Critique
+		calls     string
+		slides    int
+	}{
+		{"no review", 0, 10000, 10000, nil, "", 1},
+		{"satisfactory", 3, 10000, 10000, []Critique{{Satisfactory: true}}, "critique", 1},
+		{"revised until settled", 3, 10000, 10000, []Critique{missing, missing, {TargetSlides: 3}}, "critique,presentation,critique,presentation,critique", 3},
+		{"at most passes revisions", 1, 10000, 10000, []Critique{missing}, "critique,presentation", 2},
+		{"too long", 3, 10, 10000, nil, "", 1},
+		{"too long to be re-emitted", 3, 10000, 10, nil, "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &reviewProvider{critiques: tt.critiques}
+			presentation := &structure.Presentation{Title: "generated", Slides: []structure.Slide{{Title: "slide"}}, OriginalContent: []byte("content")}
+			got, critiques, err := ReviewPresentation(context.Background(), r, presentation, []byte("content"), tt.passes, tt.budget, tt.output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if calls := strings.Join(r.calls, ","); calls != tt.calls {
+				t.Errorf("got the calls %v, want %v", calls, tt.calls)
+			}
+			if len(got.Slides) != tt.slides || len(critiques) != len(tt.critiques) || string(got.OriginalContent) != "content" {
+				t.Errorf("got %v slides and %v critiques, want %v and %v", len(got.Slides), len(critiques), tt.slides, len(tt.critiques))
+			}
+		})
+	}
+}
+
+func TestReviewPresentationErrors(t *testing.T) {
+	missing := Critique{Missing: []string{"the conclusion"}, TargetSlides: 3}
+	tests := []struct {
+		name      string
+		failAt    int
+		critiques int
+	}{
+		{"critique", 3, 1},
+		{"revision", 4, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &reviewProvider{critiques: []Critique{missing, missing}, failAt: tt.failAt}
+			presentation := &structure.Presentation{Title: "generated", Slides: []structure.Slide{{Title: "slide"}}}
+			got, critiques, err := ReviewPresentation(context.Background(), r, presentation, []byte("content"), 3, 10000, 10000)
+			if err == nil || !strings.Contains(err.Error(), "pass 2") {
+				t.Errorf("got error %v, want an error of the second pass", err)
+			}
+			// The revision of the first pass is kept
+			if got == nil || got.Title != "revised" || len(got.Slides) != 2 || len(critiques) != tt.critiques {
+				t.Errorf("got %v critiques and the presentation %+v, want %v critiques and the first revision", len(critiques), got, tt.critiques)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Package audio prepares the audio files for their transcription, in pure Go: it checks their format, splits the files
+too large to be uploaded into segments, transcribes the segments concurrently and stitches their transcripts.
+
+The MP3 files are split at their frames and the WAV files at their sample frames, each segment overlapping the previous
+one by a few seconds so that no word is lost at the cuts; the words transcribed twice are removed by Stitch.
+The other formats are sent as they are.
+*/
+package audio
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// The supported formats.
+const (
+	MP3  = "mp3"
+	WAV  = "wav"
+	MP4  = "mp4"
+	M4A  = "m4a"
+	WebM = "webm"
+	Ogg  = "ogg"
+	FLAC = "flac"
+)
+
+// extensions gives the format of the supported extensions.
+var extensions = map[string]string{
+	".mp3": MP3, ".mpeg": MP3, ".mpga": MP3,
+	".wav":  WAV,
+	".mp4":  MP4,
+	".m4a":  M4A,
+	".webm": WebM,
+	".ogg":  Ogg, ".oga": Ogg,
+	".flac": FLAC,
+}
+
+// Supported reports whether the extension of the file is the extension of a supported audio format.
+func Supported(filename string) bool {
+	_, ok := extensions[strings.ToLower(filepath.Ext(filename))]
+	return ok
+}
+
+// Detect returns the format of the audio file, given by its extension and checked against its content.
+//
+// Parameters:
+//   - filename: The name of the file.
+//   - data: The content of the file.
+//
+// Returns:
+//   - The format, such as MP3.
+//   - An error if the extension is not supported, or if the content is not of the format of the extension.
+func Detect(filename string, data []byte) (string, error) {
+	format, ok := extensions[strings.ToLower(filepath.Ext(filename))]
+	if !ok {
+		return "", fmt.Errorf("%v: unsupported audio format (expected mp3, mpeg, mpga, wav, mp4, m4a, webm, ogg or flac)", filename)
+	}
+	if !sniff(format, data) {
+		return "", fmt.Errorf("%v: the content is not %v audio", filename, format)
+	}
+	return format, nil
+}
+
+// sniff reports whether the data starts as a file of the format.
+func sniff(format string, data []byte) bool {
+	switch format {
+	case MP3:
+		if bytes.HasPrefix(data, []byte("ID3")) {
+			return true
+		}
+		_, ok := parseFrame(data)
+		return ok
+	case WAV:
+		return len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WAVE"
+	case MP4, M4A:
+		return len(data) >= 8 && string(data[4:8]) == "ftyp"
+	case WebM:
+		return bytes.HasPrefix(data, []byte{0x1A, 0x45, 0xDF, 0xA3})
+	case Ogg:
+		return bytes.HasPrefix(data, []byte("OggS"))
+	case FLAC:
+		return bytes.HasPrefix(data, []byte("fLaC"))
+	}
+	return false
+}
+
+// Segment is a part of an audio file, a valid file of the same format.
+type Segment struct {
+	Data       []byte
+	Start, End time.Duration // the position of the segment in the file; zero if the duration of the format is unknown
+}
+
+// Limits bounds the segments of a file.
+type Limits struct {
+	MaxBytes    int           // the maximum size of a segment
+	MaxDuration time.Duration // the maximum duration of a segment, 0 for no limit
+	Overlap     time.Duration // the duration of the audio of the end of a segment repeated at the start of the next one
+}
+
+// Split returns the segments of the audio file, a single one if the file is within the limits.
+//
+// Parameters:
+//   - format: The format of the file, as returned by Detect.
+//   - data: The content of the file.
+//   - limits: The bounds of the segments.
+//
+// Returns:
+//   - The segments of the file, in order.
+//   - An error if the file exceeds the limits but its format cannot be split, or if it holds no audio.
+func Split(format string, data []byte, limits Limits) ([]Segment, error) {
+	switch format {
+	case MP3:
+		return splitMP3(data, limits)
+	case WAV:
+		return splitWAV(data, limits)
+	}
+	if len(data) > limits.MaxBytes {
+		return nil, fmt.Errorf("the %v file of %v MB is too large and cannot be split: convert it to mp3 or wav", format, len(data)>>20)
+	}
+	return []Segment{{Data: data}}, nil
+}
+
+// span is the range [start, end) of the units (frames) of a file making a segment, and its position.
+type span struct {
+	start, end int
+	from, to   time.Duration
+}
+
+// spans returns the spans of the units of a file making the segments: each span fits in the limits, with the size of
+// the header of the segments, and starts at the first unit beginning after the end of the previous span minus the overlap.
+// size and duration give the size and the duration of the units.
+func spans(n int, size func(i int) int, duration func(i int) time.Duration, header int, limits Limits) []span {
+	starts := make([]time.Duration, n+1)
+	for i := 0; i < n; i++ {
+		starts[i+1] = starts[i] + duration(i)
+	}
+	var result []span
+	for start := 0; start < n; {
+		end, total := start, header
+		for end < n {
+			if end > start && (total+size(end) > limits.MaxBytes || (limits.MaxDuration > 0 && starts[end+1]-starts[start] > limits.MaxDuration)) {
+				break
+			}
+			total += size(end)
+			end++
+		}
+		result = append(result, span{start: start, end: end, from: starts[start], to: starts[end]})
+		if end == n {
+			break
+		}
+		next := end
+		for next > start+1 && starts[next-1] >= starts[end]-limits.Overlap {
+			next--
+		}
+		start = next
+	}
+	return result
+}
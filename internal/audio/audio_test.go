@@ -0,0 +1,278 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mp3File returns an MPEG 1 layer III file of n frames at 128 kbit/s and 44.1 kHz (417 bytes and 26.1 ms each),
+// after an ID3 tag and a Xing header frame. The first byte of the payload of each frame is its number.
+func mp3File(n int) []byte {
+	frame := func(i int) []byte {
+		f := make([]byte, 417)
+		copy(f, []byte{0xFF, 0xFB, 0x90, 0x00})
+		f[4] = byte(i)
+		return f
+	}
+	var b bytes.Buffer
+	b.Write([]byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0, 5, 'T', 'I', 'T', '2', 0})
+	xing := frame(0)
+	copy(xing[36:], "Xing")
+	b.Write(xing)
+	for i := 0; i < n; i++ {
+		b.Write(frame(i))
+	}
+	return b.Bytes()
+}
+
+// wavFile16 returns a 16 bit mono WAV file at 8 kHz (16000 bytes per second) of n samples.
+func wavFile16(n int) []byte {
+	format := make([]byte, 16)
+	binary.LittleEndian.PutUint16(format[0:], 1)
+	binary.LittleEndian.PutUint16(format[2:], 1)
+	binary.LittleEndian.PutUint32(format[4:], 8000)
+	binary.LittleEndian.PutUint32(format[8:], 16000)
+	binary.LittleEndian.PutUint16(format[12:], 2)
+	binary.LittleEndian.PutUint16(format[14:], 16)
+	samples := make([]byte, 2*n)
+	for i := range samples {
+		samples[i] = byte(i)
+	}
+	return wavFile(format, samples)
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		filename string
+		data     []byte
+		want     string
+		err      bool
+	}{
+		{"talk.mp3", mp3File(1), MP3, false},
+		{"talk.MPGA", []byte{0xFF, 0xFB, 0x90, 0x00}, MP3, false},
+		{"talk.wav", wavFile16(10), WAV, false},
+		{"talk.m4a", []byte("\x00\x00\x00\x20ftypM4A "), M4A, false},
+		{"talk.flac", []byte("fLaC\x00"), FLAC, false},
+		{"talk.aiff", []byte("FORM"), "", true},
+		{"talk.mp3", []byte("<html>"), "", true},
+		{"talk.wav", mp3File(1), "", true},
+	}
+	for _, tt := range tests {
+		got, err := Detect(tt.filename, tt.data)
+		if got != tt.want || (err != nil) != tt.err {
+			t.Errorf("Detect(%v) = %q, %v; want %q (error: %v)", tt.filename, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestSplitMP3(t *testing.T) {
+	data := mp3File(100)
+	tests := []struct {
+		name   string
+		limits Limits
+		want   []int // the number of frames of the segments
+	}{
+		{"within the limits", Limits{MaxBytes: len(data)}, nil},
+		{"by size", Limits{MaxBytes: 417 * 40}, []int{40, 40, 20}},
+		{"by duration", Limits{MaxBytes: len(data), MaxDuration: time.Second}, []int{38, 38, 24}},
+		{"with overlap", Limits{MaxBytes: 417 * 40, Overlap: 100 * time.Millisecond}, []int{40, 40, 26}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments, err := Split(MP3, data, tt.limits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == nil {
+				if len(segments) != 1 || !bytes.Equal(segments[0].Data, data) {
+					t.Fatalf("got %v segments, want the file itself", len(segments))
+				}
+				return
+			}
+			var got []int
+			next := 0
+			for i, s := range segments {
+				if len(s.Data)%417 != 0 || len(s.Data) > tt.limits.MaxBytes {
+					t.Fatalf("segment %v has %v bytes", i, len(s.Data))
+				}
+				got = append(got, len(s.Data)/417)
+				first := int(s.Data[4])
+				if i > 0 && first > next {
+					t.Errorf("segment %v starts at frame %v, after the end of the previous one %v", i, first, next)
+				}
+				if i > 0 && tt.limits.Overlap == 0 && first != next {
+					t.Errorf("segment %v starts at frame %v, want %v", i, first, next)
+				}
+				next = first + len(s.Data)/417
+				if s.End-s.Start > tt.limits.MaxDuration && tt.limits.MaxDuration > 0 {
+					t.Errorf("segment %v lasts %v", i, s.End-s.Start)
+				}
+			}
+			if next != 100 {
+				t.Errorf("the segments end at frame %v, want 100", next)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got segments of %v frames, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitWAV(t *testing.T) {
+	data := wavFile16(8000 * 25 / 10) // 2.5 s
+	segments, err := Split(WAV, data, Limits{MaxBytes: 1 << 20, MaxDuration: time.Second, Overlap: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, s := range segments {
+		if _, err := Detect("segment.wav", s.Data); err != nil {
+			t.Fatal(err)
+		}
+		size := binary.LittleEndian.Uint32(s.Data[40:44])
+		if int(size) != len(s.Data)-44 || int(binary.LittleEndian.Uint32(s.Data[4:8])) != len(s.Data)-8 {
+			t.Errorf("invalid sizes in the header of the segment from %v", s.Start)
+		}
+		got = append(got, fmt.Sprintf("%v-%v", s.Start, s.End))
+	}
+	want := "[0s-1s 800ms-1.8s 1.6s-2.5s]"
+	if fmt.Sprint(got) != want {
+		t.Errorf("got segments %v, want %v", got, want)
+	}
+	if _, err := Split(Ogg, make([]byte, 100), Limits{MaxBytes: 10}); err == nil {
+		t.Error("Split() of a large ogg file succeeded, want an error")
+	}
+}
+
+func TestStitch(t *testing.T) {
+	tests := []struct {
+		name  string
+		texts []string
+		want  string
+	}{
+		{"single", []string{"Hello world."}, "Hello world."},
+		{"no overlap", []string{"one two", "three four"}, "one two three four"},
+		{
+			"overlap with cut words",
+			[]string{"We will now talk about the budget for the next quar", "get for the next quarter, which is up."},
+			"We will now talk about the budget for the next quarter, which is up.",
+		},
+		{
+			"case and punctuation",
+			[]string{"and then, the results were", "The results were good."},
+			"and then, the results were good.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transcripts := make([]Transcript, len(tt.texts))
+			for i, text := range tt.texts {
+				transcripts[i] = Transcript{Cues: []Cue{{Text: text}}}
+			}
+			if got := Stitch(transcripts).Text(); got != tt.want {
+				t.Errorf("Stitch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// The passages cut by the overlap keep their times and their remaining words
+	got := Stitch([]Transcript{
+		{Cues: []Cue{{0, 5 * time.Second, "Welcome everyone."}, {5 * time.Second, 10 * time.Second, "Let us talk about the bud"}}},
+		{Cues: []Cue{{8 * time.Second, 12 * time.Second, "talk about the budget today."}, {12 * time.Second, 15 * time.Second, "First, the costs."}}},
+	})
+	want := []Cue{
+		{0, 5 * time.Second, "Welcome everyone."},
+		{5 * time.Second, 10 * time.Second, "Let us talk about the"},
+		{8 * time.Second, 12 * time.Second, "budget today."},
+		{12 * time.Second, 15 * time.Second, "First, the costs."},
+	}
+	if fmt.Sprint(got.Cues) != fmt.Sprint(want) {
+		t.Errorf("Stitch() = %v, want %v", got.Cues, want)
+	}
+}
+
+func TestTranscript(t *testing.T) {
+	transcript := Transcript{Cues: []Cue{
+		{0, 4 * time.Second, "Hello."},
+		{4 * time.Second, 65 * time.Second, " The budget. "},
+		{65 * time.Second, 3725 * time.Second, "The costs."},
+	}}
+	if got, want := transcript.Marked(), "[00:00] Hello.\n[00:04] The budget.\n[01:05] The costs.\n"; got != want {
+		t.Errorf("Marked() = %q, want %q", got, want)
+	}
+	tests := []struct {
+		start, end string
+		from, to   time.Duration
+		ok         bool
+	}{
+		{"00:04", "01:05", 4 * time.Second, 3725 * time.Second, true},
+		{"[00:03]", "[00:05]", 4 * time.Second, 65 * time.Second, true},
+		{"1:05", "", 65 * time.Second, 3725 * time.Second, true},
+		{"01:05", "00:00", 65 * time.Second, 3725 * time.Second, true},
+		{"0:00:00", "4.5", 0, 65 * time.Second, true},
+		{"", "01:05", 0, 0, false},
+		{"01:75", "", 0, 0, false},
+	}
+	for _, tt := range tests {
+		from, to, ok := transcript.Locate(tt.start, tt.end)
+		if from != tt.from || to != tt.to || ok != tt.ok {
+			t.Errorf("Locate(%q, %q) = %v, %v, %v; want %v, %v, %v", tt.start, tt.end, from, to, ok, tt.from, tt.to, tt.ok)
+		}
+	}
+	for d, want := range map[time.Duration]string{0: "00:00", 83500 * time.Millisecond: "01:23", 3723 * time.Second: "1:02:03"} {
+		if got := Marker(d); got != want {
+			t.Errorf("Marker(%v) = %q, want %q", d, got, want)
+		}
+		if got, err := ParseMarker(want); err != nil || got != d.Truncate(time.Second) {
+			t.Errorf("ParseMarker(%q) = %v, %v; want %v", want, got, err, d.Truncate(time.Second))
+		}
+	}
+}
+
+func TestTranscribe(t *testing.T) {
+	segments := make([]Segment, 6)
+	for i := range segments {
+		segments[i] = Segment{Data: []byte(fmt.Sprintf("word%d word%d word%d word%d", i, i, i, i+1)), Start: time.Duration(i) * time.Second}
+	}
+	var running, peak int32
+	transcribe := func(ctx context.Context, s Segment) (Transcript, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return Transcript{Cues: []Cue{{0, time.Second, string(s.Data)}}}, nil
+	}
+	transcript, err := Transcribe(context.Background(), segments, 2, transcribe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := transcript.Text(); !strings.HasPrefix(text, "word0 word0 word0 word1 word1") || peak > 2 {
+		t.Errorf("got %q with %v concurrent transcriptions", text, peak)
+	}
+	if last := transcript.Cues[len(transcript.Cues)-1]; last.Start != 5*time.Second || last.End != 6*time.Second {
+		t.Errorf("the last passage lasts from %v to %v, want from 5s to 6s", last.Start, last.End)
+	}
+
+	failure := errors.New("quota exceeded")
+	_, err = Transcribe(context.Background(), segments, 2, func(ctx context.Context, s Segment) (Transcript, error) {
+		if bytes.HasPrefix(s.Data, []byte("word3")) {
+			return Transcript{}, failure
+		}
+		return transcribe(ctx, s)
+	})
+	if !errors.Is(err, failure) || !strings.Contains(err.Error(), "segment 4 of 6") {
+		t.Errorf("Transcribe() error = %v, want the failure of segment 4", err)
+	}
+}
@@ -0,0 +1,139 @@
+package audio
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// frame is an MPEG audio frame of a file.
+type frame struct {
+	offset, size int
+	duration     time.Duration
+}
+
+// The bitrates in kbit/s of the bitrate indexes 1 to 14, for MPEG 1 then MPEG 2 and 2.5, and for the layers I, II and III.
+var bitrates = [2][3][14]int{
+	{
+		{32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+		{32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+		{32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	},
+	{
+		{32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		{8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		{8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	},
+}
+
+// The sample rates in Hz of the sample rate indexes, for MPEG 1, 2 and 2.5.
+var sampleRates = [3][3]int{
+	{44100, 48000, 32000},
+	{22050, 24000, 16000},
+	{11025, 12000, 8000},
+}
+
+// parseFrame decodes the header of the frame at the start of the data; it reports false if there is no valid header.
+// The free format frames, whose size is not given by their header, are not supported.
+func parseFrame(data []byte) (frame, bool) {
+	if len(data) < 4 || data[0] != 0xFF || data[1]&0xE0 != 0xE0 {
+		return frame{}, false
+	}
+	var version int // 0 for MPEG 1, 1 for MPEG 2 and 2 for MPEG 2.5
+	switch (data[1] >> 3) & 3 {
+	case 3:
+		version = 0
+	case 2:
+		version = 1
+	case 0:
+		version = 2
+	default:
+		return frame{}, false
+	}
+	layer := 3 - int((data[1]>>1)&3) // 0 for layer I, 1 for layer II and 2 for layer III
+	bitrateIndex := int(data[2] >> 4)
+	rateIndex := int((data[2] >> 2) & 3)
+	if layer == 3 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return frame{}, false
+	}
+	bitrate := bitrates[min(version, 1)][layer][bitrateIndex-1] * 1000
+	rate := sampleRates[version][rateIndex]
+	padding := int((data[2] >> 1) & 1)
+	var size, samples int
+	switch {
+	case layer == 0:
+		samples = 384
+		size = (12*bitrate/rate + padding) * 4
+	case layer == 2 && version > 0:
+		samples = 576
+		size = 72*bitrate/rate + padding
+	default:
+		samples = 1152
+		size = 144*bitrate/rate + padding
+	}
+	return frame{size: size, duration: time.Duration(samples) * time.Second / time.Duration(rate)}, true
+}
+
+// mp3Frames returns the audio frames of the file: the ID3 tags, the VBR header frame and the garbage between the frames
+// are skipped.
+func mp3Frames(data []byte) []frame {
+	var frames []frame
+	pos := 0
+	for pos+4 <= len(data) {
+		if bytes.HasPrefix(data[pos:], []byte("ID3")) && pos+10 <= len(data) {
+			// An ID3v2 tag: its size is a syncsafe integer, and a footer may follow
+			size := int(data[pos+6])<<21 | int(data[pos+7])<<14 | int(data[pos+8])<<7 | int(data[pos+9])
+			if data[pos+5]&0x10 != 0 {
+				size += 10
+			}
+			pos += 10 + size
+			continue
+		}
+		f, ok := parseFrame(data[pos:])
+		if !ok || pos+f.size > len(data) {
+			pos++
+			continue
+		}
+		f.offset = pos
+		if len(frames) == 0 && isVBRHeader(data[pos:pos+f.size]) {
+			// The header of a variable bitrate file gives the number of frames of the whole file
+			pos += f.size
+			continue
+		}
+		frames = append(frames, f)
+		pos += f.size
+	}
+	return frames
+}
+
+// isVBRHeader reports whether the frame holds a Xing, Info or VBRI header rather than audio.
+func isVBRHeader(f []byte) bool {
+	f = f[4:min(len(f), 64)]
+	return bytes.Contains(f, []byte("Xing")) || bytes.Contains(f, []byte("Info")) || bytes.Contains(f, []byte("VBRI"))
+}
+
+// splitMP3 splits the file at its frames; the segments are the concatenations of consecutive frames.
+func splitMP3(data []byte, limits Limits) ([]Segment, error) {
+	frames := mp3Frames(data)
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("no MPEG audio frame found")
+	}
+	var total time.Duration
+	for _, f := range frames {
+		total += f.duration
+	}
+	if len(data) <= limits.MaxBytes && (limits.MaxDuration == 0 || total <= limits.MaxDuration) {
+		return []Segment{{Data: data, End: total}}, nil
+	}
+	var segments []Segment
+	size := func(i int) int { return frames[i].size }
+	duration := func(i int) time.Duration { return frames[i].duration }
+	for _, s := range spans(len(frames), size, duration, 0, limits) {
+		var b bytes.Buffer
+		for _, f := range frames[s.start:s.end] {
+			b.Write(data[f.offset : f.offset+f.size])
+		}
+		segments = append(segments, Segment{Data: b.Bytes(), Start: s.from, End: s.to})
+	}
+	return segments, nil
+}
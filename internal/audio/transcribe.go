@@ -0,0 +1,141 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Transcribe transcribes the segments, at most parallelism at a time, and stitches their transcripts.
+// The times of the transcript of a segment are relative to the segment: they are shifted to the start of the segment.
+// The first error cancels the transcriptions in progress.
+//
+// Parameters:
+//   - ctx: The context for managing request deadlines and cancellation signals.
+//   - segments: The segments of the audio file, in order.
+//   - parallelism: The maximum number of concurrent transcriptions.
+//   - transcribe: The transcription of a segment.
+//
+// Returns:
+//   - The transcript of the file.
+//   - An error if the transcription of a segment fails.
+func Transcribe(ctx context.Context, segments []Segment, parallelism int, transcribe func(ctx context.Context, s Segment) (Transcript, error)) (Transcript, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	transcripts := make([]Transcript, len(segments))
+	slots := make(chan struct{}, max(1, parallelism))
+	var wg sync.WaitGroup
+	var once sync.Once
+	var failure error
+	fail := func(i int, err error) {
+		once.Do(func() {
+			failure = fmt.Errorf("segment %d of %d (from %v): %w", i+1, len(segments), segments[i].Start, err)
+			cancel()
+		})
+	}
+	for i, s := range segments {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				fail(i, ctx.Err())
+				return
+			}
+			defer func() { <-slots }()
+			t, err := transcribe(ctx, s)
+			if err != nil {
+				fail(i, err)
+				return
+			}
+			for j := range t.Cues {
+				t.Cues[j].Start += s.Start
+				t.Cues[j].End += s.Start
+			}
+			transcripts[i] = t
+		}()
+	}
+	wg.Wait()
+	if failure != nil {
+		return Transcript{}, failure
+	}
+	return Stitch(transcripts), nil
+}
+
+// The search of the words transcribed twice: at the end of a transcript and the start of the next one, within window
+// words, a run of at least minOverlap words.
+const (
+	window     = 60
+	minOverlap = 3
+)
+
+// Stitch joins the transcripts of consecutive overlapping segments. The longest run of words found both at the end of
+// a transcript and at the start of the next one is the overlap: the words of the first transcript after it and of the
+// next transcript before it, cut in the middle by the split, are dropped, and the run is kept once.
+// The words are compared regardless of their case and punctuation. The passages keep their times, those cut by the
+// overlap keeping their remaining words.
+func Stitch(transcripts []Transcript) Transcript {
+	var cues []Cue
+	var words []word
+	for _, t := range transcripts {
+		var next []word
+		for _, c := range t.Cues {
+			for _, w := range strings.Fields(c.Text) {
+				next = append(next, word{text: w, cue: len(cues)})
+			}
+			cues = append(cues, c)
+		}
+		i, j, n := longestRun(words[max(0, len(words)-window):], next[:min(len(next), window)])
+		if n >= minOverlap {
+			i += max(0, len(words)-window)
+			words = append(words[:i+n], next[j+n:]...)
+		} else {
+			words = append(words, next...)
+		}
+	}
+
+	// Gather the remaining words of each passage
+	var result Transcript
+	for k, w := range words {
+		if k > 0 && words[k-1].cue == w.cue {
+			last := &result.Cues[len(result.Cues)-1]
+			last.Text += " " + w.text
+			continue
+		}
+		result.Cues = append(result.Cues, Cue{Start: cues[w.cue].Start, End: cues[w.cue].End, Text: w.text})
+	}
+	return result
+}
+
+// word is a word of a transcript, with the index of its passage.
+type word struct {
+	text string
+	cue  int
+}
+
+// longestRun returns the longest run of words found in both a and b: its start in a, in b, and its length.
+func longestRun(a, b []word) (int, int, int) {
+	var bestI, bestJ, best int
+	for i := range a {
+		for j := range b {
+			n := 0
+			for i+n < len(a) && j+n < len(b) && sameWord(a[i+n].text, b[j+n].text) {
+				n++
+			}
+			if n > best {
+				bestI, bestJ, best = i, j, n
+			}
+		}
+	}
+	return bestI, bestJ, best
+}
+
+func sameWord(a, b string) bool {
+	trim := func(s string) string {
+		return strings.TrimFunc(s, func(r rune) bool { return unicode.IsPunct(r) || unicode.IsSymbol(r) })
+	}
+	return strings.EqualFold(trim(a), trim(b))
+}
@@ -0,0 +1,101 @@
+package audio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cue is a passage of a transcript, timed from the start of the audio file.
+type Cue struct {
+	Start, End time.Duration
+	Text       string
+}
+
+// Transcript is the transcript of an audio file: its passages, in order.
+type Transcript struct {
+	Cues []Cue
+}
+
+// Text returns the text of the transcript, without its times.
+func (t Transcript) Text() string {
+	texts := make([]string, 0, len(t.Cues))
+	for _, c := range t.Cues {
+		if text := strings.TrimSpace(c.Text); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, " ")
+}
+
+// Marked returns the text of the transcript with a line per passage, starting with the time marker of the passage
+// (see Marker), such as "[01:23] And now, the budget."
+func (t Transcript) Marked() string {
+	var b strings.Builder
+	for _, c := range t.Cues {
+		if text := strings.TrimSpace(c.Text); text != "" {
+			fmt.Fprintf(&b, "[%v] %v\n", Marker(c.Start), text)
+		}
+	}
+	return b.String()
+}
+
+// Locate returns the time range of the passages from the one marked start to the one marked end (see Marker): from
+// the start of the first passage to the end of the last one. The markers are matched to the passage starting the
+// closest to them; a missing or invalid end marker, or one before the start, stands for the first passage.
+// It reports false if the start marker is invalid or if the transcript is empty.
+func (t Transcript) Locate(start, end string) (time.Duration, time.Duration, bool) {
+	if len(t.Cues) == 0 {
+		return 0, 0, false
+	}
+	from, err := ParseMarker(start)
+	if err != nil {
+		return 0, 0, false
+	}
+	first := t.closest(from)
+	last := first
+	if to, err := ParseMarker(end); err == nil {
+		last = max(first, t.closest(to))
+	}
+	return t.Cues[first].Start, max(t.Cues[first].Start, t.Cues[last].End), true
+}
+
+// closest returns the index of the passage starting the closest to d.
+func (t Transcript) closest(d time.Duration) int {
+	best := 0
+	for i, c := range t.Cues {
+		if (c.Start - d).Abs() < (t.Cues[best].Start - d).Abs() {
+			best = i
+		}
+	}
+	return best
+}
+
+// Marker returns the time marker of d in the transcripts, to the second: minutes and seconds such as "01:23", and the
+// hours beyond the first hour, such as "1:02:03".
+func Marker(d time.Duration) string {
+	s := int(d / time.Second)
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%02d:%02d", s/60, s%60)
+}
+
+// ParseMarker parses a time marker (see Marker): seconds, minutes and seconds, or hours, minutes and seconds,
+// the seconds possibly with a fraction. The brackets and spaces around it are ignored.
+func ParseMarker(s string) (time.Duration, error) {
+	fields := strings.Split(strings.Trim(s, "[] "), ":")
+	if len(fields) > 3 {
+		return 0, fmt.Errorf("invalid time marker %q", s)
+	}
+	var d time.Duration
+	for i, field := range fields {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil || v < 0 || (i > 0 && v >= 60) {
+			return 0, fmt.Errorf("invalid time marker %q", s)
+		}
+		d = d*60 + time.Duration(v*float64(time.Second))
+	}
+	return d, nil
+}
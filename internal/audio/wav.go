@@ -0,0 +1,73 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// wavHeaderSize is the size of the header of a segment, without its fmt chunk: the RIFF header and the chunk headers.
+const wavHeaderSize = 12 + 8 + 8
+
+// splitWAV splits the samples of the data chunk into segments of a tenth of a second, each segment being a file made of
+// the fmt chunk of the file and its own data chunk.
+func splitWAV(data []byte, limits Limits) ([]Segment, error) {
+	var format, samples []byte
+	for pos := 12; pos+8 <= len(data); {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		pos += 8
+		if size < 0 || pos+size > len(data) {
+			// The size of the data chunk of a stream may be unknown: it lasts until the end of the file
+			size = len(data) - pos
+		}
+		switch id {
+		case "fmt ":
+			format = data[pos : pos+size]
+		case "data":
+			samples = data[pos : pos+size]
+		}
+		pos += size + size%2
+	}
+	if len(format) < 16 || samples == nil {
+		return nil, fmt.Errorf("no fmt or data chunk in the WAV file")
+	}
+	byteRate := int(binary.LittleEndian.Uint32(format[8:12]))
+	blockAlign := int(binary.LittleEndian.Uint16(format[12:14]))
+	if byteRate == 0 || blockAlign == 0 {
+		return nil, fmt.Errorf("invalid fmt chunk in the WAV file")
+	}
+	total := time.Duration(len(samples)) * time.Second / time.Duration(byteRate)
+	if len(data) <= limits.MaxBytes && (limits.MaxDuration == 0 || total <= limits.MaxDuration) {
+		return []Segment{{Data: data, End: total}}, nil
+	}
+
+	// The units of the split are blocks of a tenth of a second
+	unit := max(blockAlign, byteRate/10/blockAlign*blockAlign)
+	n := (len(samples) + unit - 1) / unit
+	size := func(i int) int { return min(unit, len(samples)-i*unit) }
+	duration := func(i int) time.Duration { return time.Duration(size(i)) * time.Second / time.Duration(byteRate) }
+	var segments []Segment
+	for _, s := range spans(n, size, duration, wavHeaderSize+len(format), limits) {
+		chunk := samples[s.start*unit : min(len(samples), s.end*unit)]
+		segments = append(segments, Segment{Data: wavFile(format, chunk), Start: s.from, End: s.to})
+	}
+	return segments, nil
+}
+
+// wavFile returns a WAV file made of the fmt chunk and the samples.
+func wavFile(format, samples []byte) []byte {
+	b := make([]byte, 0, wavHeaderSize+len(format)+len(samples)+1)
+	b = append(b, "RIFF"...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(4+8+len(format)+8+len(samples)+len(samples)%2))
+	b = append(b, "WAVEfmt "...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(format)))
+	b = append(b, format...)
+	b = append(b, "data"...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(samples)))
+	b = append(b, samples...)
+	if len(samples)%2 == 1 {
+		b = append(b, 0)
+	}
+	return b
+}
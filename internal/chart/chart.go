@@ -0,0 +1,422 @@
+/*
+Package chart renders the chart of a chart slide as an image, in pure Go.
+
+The bar, line and pie charts are drawn with the standard image packages and the Go font,
+so that the image can be uploaded and inserted like the illustration of a chapter.
+FromTable builds a chart from a table of figures, without going through the model.
+*/
+package chart
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// The kinds of charts.
+const (
+	KindBar  = "bar"
+	KindLine = "line"
+	KindPie  = "pie"
+)
+
+// Palette holds the colors of the series, or of the slices of a pie chart, in order.
+var Palette = []color.RGBA{
+	{0x42, 0x85, 0xF4, 0xFF},
+	{0xEA, 0x43, 0x35, 0xFF},
+	{0xFB, 0xBC, 0x04, 0xFF},
+	{0x34, 0xA8, 0x53, 0xFF},
+	{0xFF, 0x6D, 0x01, 0xFF},
+	{0x46, 0xBD, 0xC6, 0xFF},
+}
+
+var (
+	backgroundColor = color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	textColor       = color.RGBA{0x37, 0x41, 0x51, 0xFF}
+	gridColor       = color.RGBA{0xE5, 0xE7, 0xEB, 0xFF}
+	axisColor       = color.RGBA{0x9C, 0xA3, 0xAF, 0xFF}
+)
+
+// ticks is the approximate number of intervals of the vertical axis.
+const ticks = 5
+
+// Render draws the chart on a white image of width x height pixels.
+// The kind defaults to a bar chart. The values of a series beyond the number of labels are ignored,
+// and a pie chart shows the first series, ignoring its negative values.
+func Render(c structure.Chart, width, height int) (image.Image, error) {
+	if err := Validate(c); err != nil {
+		return nil, err
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(backgroundColor), image.Point{}, draw.Src)
+	r := renderer{img: img, chart: c, scale: max(1, height/300)}
+	r.pad = 4 * r.scale
+	face, err := newFace(r.scale)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load the font: %w", err)
+	}
+	defer face.Close()
+	r.face = face
+	switch c.Kind {
+	case KindPie:
+		if err := r.pie(); err != nil {
+			return nil, err
+		}
+	case KindLine:
+		r.axes(r.line)
+	default:
+		r.axes(r.bars)
+	}
+	return img, nil
+}
+
+// Validate reports why the chart cannot be rendered (see Render): it has no labels or no series, or it is a pie chart
+// without positive value among the values of its first series that have a label.
+func Validate(c structure.Chart) error {
+	if c.Empty() || len(c.Labels) == 0 {
+		return fmt.Errorf("the chart has no data")
+	}
+	if c.Kind == KindPie {
+		values := c.Series[0].Values
+		for i := 0; i < len(values) && i < len(c.Labels); i++ {
+			if values[i] > 0 {
+				return nil
+			}
+		}
+		return fmt.Errorf("the pie chart has no positive value")
+	}
+	return nil
+}
+
+// renderer holds the state of the drawing of a chart.
+type renderer struct {
+	img   *image.RGBA
+	chart structure.Chart
+	scale int       // the scale of the text and the lines
+	face  font.Face // the face of the text, at the scale
+	pad   int
+	plot  image.Rectangle // the area of the bars and lines
+	lo    float64         // the value at the bottom of the plot
+	hi    float64         // the value at the top of the plot
+}
+
+// lineHeight is the height of a line of text.
+func (r *renderer) lineHeight() int {
+	return r.textHeight() + r.pad
+}
+
+// axes draws the grid, the labels and the legend around the plot, then the series with draw.
+func (r *renderer) axes(draw func()) {
+	bounds := r.img.Bounds()
+	lo, hi := 0.0, 0.0
+	for _, s := range r.chart.Series {
+		for _, v := range r.values(s) {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+	if hi == lo {
+		hi = lo + 1
+	}
+	step := niceStep((hi - lo) / ticks)
+	r.lo, r.hi = math.Floor(lo/step)*step, math.Ceil(hi/step)*step
+
+	var labels []string
+	labelWidth := 0
+	for v := r.lo; v <= r.hi+step/2; v += step {
+		label := formatValue(v, step)
+		labels = append(labels, label)
+		labelWidth = max(labelWidth, r.textWidth(label))
+	}
+	bottom := bounds.Max.Y - 2*r.pad - r.lineHeight()
+	if r.hasLegend() {
+		bottom -= r.lineHeight() + r.pad
+	}
+	r.plot = image.Rect(bounds.Min.X+labelWidth+3*r.pad, bounds.Min.Y+2*r.pad+r.textHeight()/2, bounds.Max.X-2*r.pad, bottom)
+
+	// The horizontal grid and its labels
+	for i, label := range labels {
+		y := r.y(r.lo + float64(i)*step)
+		fillRect(r.img, image.Rect(r.plot.Min.X, y, r.plot.Max.X, y+max(1, r.scale/2)), gridColor)
+		r.drawText(r.plot.Min.X-2*r.pad-r.textWidth(label), y-r.textHeight()/2, label, textColor)
+	}
+
+	// The labels of the horizontal axis, centered on their slot
+	slot := r.plot.Dx() / len(r.chart.Labels)
+	for i, label := range r.chart.Labels {
+		label = r.fitText(label, slot-r.pad)
+		x := r.plot.Min.X + i*slot + (slot-r.textWidth(label))/2
+		r.drawText(x, r.plot.Max.Y+r.pad, label, textColor)
+	}
+
+	draw()
+
+	// The axis, over the series
+	y := r.y(0)
+	fillRect(r.img, image.Rect(r.plot.Min.X, y, r.plot.Max.X, y+r.scale), axisColor)
+	if r.hasLegend() {
+		var names []string
+		for _, s := range r.chart.Series {
+			names = append(names, s.Name)
+		}
+		r.legend(names, r.plot.Max.Y+r.lineHeight()+2*r.pad)
+	}
+}
+
+// bars draws the series as groups of bars, one group per label.
+func (r *renderer) bars() {
+	slot := float64(r.plot.Dx()) / float64(len(r.chart.Labels))
+	width := slot * 0.8 / float64(len(r.chart.Series))
+	for s, series := range r.chart.Series {
+		for i, v := range r.values(series) {
+			x := r.plot.Min.X + int(slot*(float64(i)+0.1)+width*float64(s))
+			y0, y1 := r.y(0), r.y(v)
+			fillRect(r.img, image.Rect(x+r.scale/2, min(y0, y1), x+int(width)-r.scale/2, max(y0, y1)), Palette[s%len(Palette)])
+		}
+	}
+}
+
+// line draws each series as a line joining its values, marked by squares.
+func (r *renderer) line() {
+	slot := float64(r.plot.Dx()) / float64(len(r.chart.Labels))
+	thickness := r.scale + 1
+	for s, series := range r.chart.Series {
+		c := Palette[s%len(Palette)]
+		var previous image.Point
+		for i, v := range r.values(series) {
+			p := image.Pt(r.plot.Min.X+int(slot*(float64(i)+0.5)), r.y(v))
+			if i > 0 {
+				drawLine(r.img, previous, p, thickness, c)
+			}
+			fillRect(r.img, image.Rect(p.X-2*thickness, p.Y-2*thickness, p.X+2*thickness, p.Y+2*thickness), c)
+			previous = p
+		}
+	}
+}
+
+// pie draws the first series as a pie chart, with a legend on its right giving the share of each slice.
+func (r *renderer) pie() error {
+	values := r.values(r.chart.Series[0])
+	total := 0.0
+	for i, v := range values {
+		values[i] = math.Max(v, 0)
+		total += values[i]
+	}
+	if total == 0 {
+		return fmt.Errorf("the pie chart has no positive value")
+	}
+	bounds := r.img.Bounds()
+	radius := (min(bounds.Dy(), bounds.Dx()/2) - 4*r.pad) / 2
+	center := image.Pt(bounds.Min.X+2*r.pad+radius, bounds.Min.Y+bounds.Dy()/2)
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			if x*x+y*y > radius*radius {
+				continue
+			}
+			// The slices start at the top and go clockwise
+			angle := math.Atan2(float64(x), float64(-y))
+			if angle < 0 {
+				angle += 2 * math.Pi
+			}
+			share, slice := angle/(2*math.Pi)*total, 0
+			for slice < len(values)-1 && share >= values[slice] {
+				share -= values[slice]
+				slice++
+			}
+			r.img.SetRGBA(center.X+x, center.Y+y, Palette[slice%len(Palette)])
+		}
+	}
+	var names []string
+	for i, v := range values {
+		names = append(names, fmt.Sprintf("%s (%s%%)", r.chart.Labels[i], formatValue(100*v/total, 0.1)))
+	}
+	left := center.X + radius + 6*r.pad
+	top := center.Y - len(names)*(r.lineHeight()+r.pad)/2
+	for i, name := range names {
+		r.legendEntry(left, top+i*(r.lineHeight()+r.pad), bounds.Max.X-left, name, Palette[i%len(Palette)])
+	}
+	return nil
+}
+
+// hasLegend reports whether the series are named in a legend: when there are several, or when the only one has a name.
+func (r *renderer) hasLegend() bool {
+	return len(r.chart.Series) > 1 || r.chart.Series[0].Name != ""
+}
+
+// legend draws the names on a line starting at y, one column per name, each one after a square of the color of its series.
+func (r *renderer) legend(names []string, y int) {
+	bounds := r.img.Bounds()
+	width := bounds.Dx() / len(names)
+	for i, name := range names {
+		r.legendEntry(bounds.Min.X+i*width+2*r.pad, y, width-4*r.pad, name, Palette[i%len(Palette)])
+	}
+}
+
+// legendEntry draws a square of the color followed by the name, within width pixels.
+func (r *renderer) legendEntry(x, y, width int, name string, c color.RGBA) {
+	size := r.textHeight()
+	fillRect(r.img, image.Rect(x, y, x+size, y+size), c)
+	r.drawText(x+size+2*r.pad, y, r.fitText(name, width-size-2*r.pad), textColor)
+}
+
+// values returns the values of the series that have a label.
+func (r *renderer) values(s structure.Series) []float64 {
+	if len(s.Values) > len(r.chart.Labels) {
+		return s.Values[:len(r.chart.Labels)]
+	}
+	return append([]float64(nil), s.Values...)
+}
+
+// y returns the ordinate of the value in the plot.
+func (r *renderer) y(v float64) int {
+	return r.plot.Max.Y - int((v-r.lo)/(r.hi-r.lo)*float64(r.plot.Dy()))
+}
+
+func fillRect(img draw.Image, rect image.Rectangle, c color.Color) {
+	draw.Draw(img, rect, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// drawLine draws a segment from a to b of the given thickness.
+func drawLine(img draw.Image, a, b image.Point, thickness int, c color.Color) {
+	steps := max(abs(b.X-a.X), abs(b.Y-a.Y), 1)
+	for i := 0; i <= steps; i++ {
+		x := a.X + (b.X-a.X)*i/steps
+		y := a.Y + (b.Y-a.Y)*i/steps
+		fillRect(img, image.Rect(x-thickness/2, y-thickness/2, x-thickness/2+thickness, y-thickness/2+thickness), c)
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// niceStep rounds the step of the axis up to 1, 2 or 5 times a power of ten.
+func niceStep(step float64) float64 {
+	magnitude := math.Pow(10, math.Floor(math.Log10(step)))
+	switch f := step / magnitude; {
+	case f <= 1:
+		return magnitude
+	case f <= 2:
+		return 2 * magnitude
+	case f <= 5:
+		return 5 * magnitude
+	}
+	return 10 * magnitude
+}
+
+// formatValue formats the value with the precision of the step; the large values are abbreviated (K, M, B).
+func formatValue(v, step float64) string {
+	for _, unit := range []struct {
+		suffix string
+		size   float64
+	}{{"B", 1e9}, {"M", 1e6}, {"K", 1e3}} {
+		if math.Abs(v) >= unit.size && step >= unit.size/10 {
+			return formatValue(v/unit.size, step/unit.size) + unit.suffix
+		}
+	}
+	decimals := 0
+	if step > 0 && step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step) - 1e-9))
+	}
+	return strconv.FormatFloat(v, 'f', decimals, 64)
+}
+
+// FromTable builds a chart from a table of figures: the first column holds the labels, and each other column is a series
+// named after its header. It fails unless the table has a header, two rows and a number in each other cell (such as
+// 1,234.5, 12%, $3.2 or 4 500 €).
+// A single series of percentages adding up to 100 is a pie chart, a series of more than 6 values a line chart,
+// and the other ones bar charts.
+func FromTable(t structure.Table) (structure.Chart, bool) {
+	if len(t.Header) < 2 || len(t.Rows) < 2 {
+		return structure.Chart{}, false
+	}
+	c := structure.Chart{Kind: KindBar}
+	for _, name := range t.Header[1:] {
+		c.Series = append(c.Series, structure.Series{Name: name})
+	}
+	percentages, total := true, 0.0
+	for _, row := range t.Rows {
+		if len(row) != len(t.Header) {
+			return structure.Chart{}, false
+		}
+		c.Labels = append(c.Labels, row[0])
+		for i, cell := range row[1:] {
+			v, percentage, ok := parseNumber(cell)
+			if !ok {
+				return structure.Chart{}, false
+			}
+			c.Series[i].Values = append(c.Series[i].Values, v)
+			percentages = percentages && percentage
+			total += v
+		}
+	}
+	switch {
+	case len(c.Series) == 1 && percentages && math.Abs(total-100) <= 1:
+		c.Kind = KindPie
+	case len(c.Labels) > 6:
+		c.Kind = KindLine
+	}
+	return c, true
+}
+
+// ToTable returns the data of the chart as a table, the inverse of FromTable: the first column holds the labels,
+// and each other column a series.
+func ToTable(c structure.Chart) structure.Table {
+	t := structure.Table{Header: []string{""}}
+	for _, series := range c.Series {
+		t.Header = append(t.Header, series.Name)
+	}
+	for i, label := range c.Labels {
+		row := []string{label}
+		for _, series := range c.Series {
+			var cell string
+			if i < len(series.Values) {
+				cell = strconv.FormatFloat(series.Values[i], 'f', -1, 64)
+			}
+			row = append(row, cell)
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+var thousands = regexp.MustCompile(`^\d{1,3}(,\d{3})+(\.\d+)?$`)
+
+// parseNumber parses a figure of a table, ignoring the currency symbols and the thousands separators.
+// It reports whether the figure is a percentage.
+func parseNumber(s string) (float64, bool, bool) {
+	s = strings.TrimSpace(s)
+	percentage := strings.HasSuffix(s, "%")
+	s = strings.TrimSpace(strings.TrimSuffix(s, "%"))
+	s = strings.Trim(s, "$€£¥ ")
+	s = strings.ReplaceAll(strings.ReplaceAll(s, " ", ""), " ", "")
+	s = strings.Replace(s, "−", "-", 1)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	switch {
+	case thousands.MatchString(s):
+		s = strings.ReplaceAll(s, ",", "")
+	case strings.Count(s, ",") == 1 && !strings.Contains(s, "."):
+		// A decimal comma
+		s = strings.Replace(s, ",", ".", 1)
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false, false
+	}
+	if negative {
+		v = -v
+	}
+	return v, percentage, true
+}
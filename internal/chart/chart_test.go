@@ -0,0 +1,170 @@
+package chart
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+func TestFromTable(t *testing.T) {
+	tests := []struct {
+		name  string
+		table structure.Table
+		want  structure.Chart
+		ok    bool
+	}{
+		{
+			name: "bar",
+			table: structure.Table{Header: []string{"Quarter", "Revenue", "Costs"}, Rows: [][]string{
+				{"Q1", "$1,234.5", "-12"},
+				{"Q2", "4 500 €", "3,5"},
+			}},
+			want: structure.Chart{Kind: KindBar, Labels: []string{"Q1", "Q2"}, Series: []structure.Series{
+				{Name: "Revenue", Values: []float64{1234.5, 4500}},
+				{Name: "Costs", Values: []float64{-12, 3.5}},
+			}},
+			ok: true,
+		},
+		{
+			name:  "pie",
+			table: structure.Table{Header: []string{"Browser", "Share"}, Rows: [][]string{{"A", "60%"}, {"B", "30 %"}, {"C", "10%"}}},
+			want:  structure.Chart{Kind: KindPie, Labels: []string{"A", "B", "C"}, Series: []structure.Series{{Name: "Share", Values: []float64{60, 30, 10}}}},
+			ok:    true,
+		},
+		{
+			name: "line",
+			table: structure.Table{Header: []string{"Year", "Users"}, Rows: [][]string{
+				{"2018", "1"}, {"2019", "2"}, {"2020", "3"}, {"2021", "4"}, {"2022", "5"}, {"2023", "6"}, {"2024", "7"},
+			}},
+			want: structure.Chart{Kind: KindLine, Labels: []string{"2018", "2019", "2020", "2021", "2022", "2023", "2024"},
+				Series: []structure.Series{{Name: "Users", Values: []float64{1, 2, 3, 4, 5, 6, 7}}}},
+			ok: true,
+		},
+		{
+			name:  "text",
+			table: structure.Table{Header: []string{"Language", "Typing"}, Rows: [][]string{{"Go", "static"}, {"Python", "dynamic"}}},
+		},
+		{
+			name:  "single row",
+			table: structure.Table{Header: []string{"Quarter", "Revenue"}, Rows: [][]string{{"Q1", "1"}}},
+		},
+		{
+			name:  "missing cell",
+			table: structure.Table{Header: []string{"Quarter", "Revenue"}, Rows: [][]string{{"Q1", "1"}, {"Q2"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := FromTable(tt.table)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromTable() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestToTable(t *testing.T) {
+	c := structure.Chart{Kind: KindBar, Labels: []string{"Q1", "Q2"}, Series: []structure.Series{
+		{Name: "Revenue", Values: []float64{1234.5, 4500}},
+		{Name: "Costs", Values: []float64{-12}},
+	}}
+	want := structure.Table{Header: []string{"", "Revenue", "Costs"}, Rows: [][]string{{"Q1", "1234.5", "-12"}, {"Q2", "4500", ""}}}
+	if got := ToTable(c); !reflect.DeepEqual(got, want) {
+		t.Errorf("ToTable() = %+v, want %+v", got, want)
+	}
+	c.Series[1].Values = append(c.Series[1].Values, 3.5)
+	if got, ok := FromTable(ToTable(c)); !ok || !reflect.DeepEqual(got, c) {
+		t.Errorf("FromTable(ToTable()) = %+v, %v, want %+v", got, ok, c)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		chart   structure.Chart
+		wantErr bool
+	}{
+		{"bar", structure.Chart{Labels: []string{"a"}, Series: []structure.Series{{Values: []float64{-1}}}}, false},
+		{"no labels", structure.Chart{Series: []structure.Series{{Values: []float64{1}}}}, true},
+		{"no series", structure.Chart{Labels: []string{"a"}}, true},
+		{"pie", structure.Chart{Kind: KindPie, Labels: []string{"a", "b"}, Series: []structure.Series{{Values: []float64{0, 2}}}}, false},
+		{"pie without positive value", structure.Chart{Kind: KindPie, Labels: []string{"a"}, Series: []structure.Series{{Values: []float64{-1, 2}}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.chart); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	series := []structure.Series{{Name: "Revenue", Values: []float64{10, -5, 30}}, {Name: "Costs", Values: []float64{3, 4}}}
+	for _, kind := range []string{KindBar, KindLine, KindPie} {
+		t.Run(kind, func(t *testing.T) {
+			img, err := Render(structure.Chart{Kind: kind, Labels: []string{"Q1", "Q2", "Q3"}, Series: series}, 800, 450)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if img.Bounds() != image.Rect(0, 0, 800, 450) {
+				t.Errorf("unexpected bounds %v", img.Bounds())
+			}
+			// The first colors of the palette are drawn: one per series, or per slice of the pie chart
+			colors := map[color.RGBA]bool{}
+			for y := 0; y < 450; y++ {
+				for x := 0; x < 800; x++ {
+					colors[img.(*image.RGBA).RGBAAt(x, y)] = true
+				}
+			}
+			for i := range series {
+				if !colors[Palette[i]] {
+					t.Errorf("the color of series %v is not drawn", i)
+				}
+			}
+		})
+	}
+	if _, err := Render(structure.Chart{Kind: KindPie, Labels: []string{"a"}, Series: []structure.Series{{Values: []float64{-1}}}}, 800, 450); err == nil {
+		t.Error("expected an error for a pie chart without positive value")
+	}
+	if _, err := Render(structure.Chart{}, 800, 450); err == nil {
+		t.Error("expected an error for an empty chart")
+	}
+}
+
+func TestText(t *testing.T) {
+	face, err := newFace(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer face.Close()
+	// draw returns the pixels of the text
+	draw := func(text string) []uint8 {
+		r := renderer{img: image.NewRGBA(image.Rect(0, 0, 40, 20)), face: face}
+		r.drawText(0, 0, text, textColor)
+		return r.img.Pix
+	}
+	tests := []struct {
+		text  string
+		other string
+	}{
+		{"a", "A"},
+		{"é", "e"},
+		{"Ж", "?"},
+		{"€", "?"},
+	}
+	for _, tt := range tests {
+		if bytes.Equal(draw(tt.text), draw(tt.other)) {
+			t.Errorf("%q is drawn as %q", tt.text, tt.other)
+		}
+	}
+	r := renderer{face: face}
+	if got := r.fitText("Chiffre d'affaires", 40); got == "Chiffre d'affaires" || !strings.HasSuffix(got, "..") || r.textWidth(got) > 40 {
+		t.Errorf("fitText() = %q, %v pixels wide", got, r.textWidth(got))
+	}
+}
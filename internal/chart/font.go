@@ -0,0 +1,66 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// fontSize is the size of the text in pixels at the scale 1.
+const fontSize = 11
+
+// goRegular parses the Go font once; it covers the Latin, Greek and Cyrillic scripts.
+var goRegular = sync.OnceValues(func() (*opentype.Font, error) {
+	return opentype.Parse(goregular.TTF)
+})
+
+// newFace returns the face of the text at the given scale. A face is not safe for concurrent use.
+func newFace(scale int) (font.Face, error) {
+	f, err := goRegular()
+	if err != nil {
+		return nil, err
+	}
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: float64(fontSize * scale), DPI: 72, Hinting: font.HintingFull})
+}
+
+// textHeight returns the height in pixels of a line of text, without spacing.
+func (r *renderer) textHeight() int {
+	m := r.face.Metrics()
+	return (m.Ascent + m.Descent).Ceil()
+}
+
+// textWidth returns the width in pixels of the text.
+func (r *renderer) textWidth(text string) int {
+	return font.MeasureString(r.face, text).Ceil()
+}
+
+// drawText draws the text with its top left corner at (x, y).
+func (r *renderer) drawText(x, y int, text string, c color.Color) {
+	d := font.Drawer{
+		Dst:  r.img,
+		Src:  image.NewUniform(c),
+		Face: r.face,
+		Dot:  fixed.P(x, y+r.face.Metrics().Ascent.Ceil()),
+	}
+	d.DrawString(text)
+}
+
+// fitText shortens the text with an ellipsis so that it is at most width pixels wide.
+func (r *renderer) fitText(text string, width int) string {
+	runes := []rune(text)
+	if r.textWidth(text) <= width {
+		return text
+	}
+	for len(runes) > 0 && r.textWidth(string(runes)+"..") > width {
+		runes = runes[:len(runes)-1]
+	}
+	if len(runes) == 0 {
+		return ""
+	}
+	return string(runes) + ".."
+}
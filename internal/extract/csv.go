@@ -0,0 +1,29 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// csvText returns the CSV file as a Markdown table under a heading named after the file, so that its figures
+// become a chart (or a table) without going through the model.
+func csvText(filename string, data []byte) (string, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return "", err
+	}
+	if len(records) == 0 {
+		return "", fmt.Errorf("no records")
+	}
+	title := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	table := structure.Table{Header: records[0], Rows: records[1:]}
+	return "# " + title + "\n\n" + slidesutils.MarkdownTable(table), nil
+}
@@ -0,0 +1,417 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// The namespaces of the WordprocessingML elements and of the relationship attributes.
+const (
+	wordNamespace = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	relNamespace  = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+)
+
+// docxRun is a run of text of a paragraph, with its formatting.
+type docxRun struct {
+	text                 string
+	bold, italic, strike bool
+	link                 string
+}
+
+// docxParagraph is a paragraph of the document being read.
+type docxParagraph struct {
+	style   string
+	outline int // the outline level of the paragraph plus one, 0 if none
+	list    bool
+	numID   string
+	level   int
+	runs    []docxRun
+}
+
+// docxText returns the body of the document as Markdown: the paragraphs styled as headings (Title, Heading 1 and so on)
+// are headings, the numbered paragraphs list items, and the tables pipe tables.
+func docxText(filename string, data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+	document, err := readZip(zr, "word/document.xml")
+	if err != nil {
+		return "", err
+	}
+	d := docx{
+		headings: docxHeadings(zr),
+		ordered:  docxOrdered(zr),
+		links:    docxLinks(zr),
+	}
+	if err := d.read(document); err != nil {
+		return "", err
+	}
+	return d.sb.String(), nil
+}
+
+// isDOCX reports whether the data is a ZIP archive holding a Word document.
+func isDOCX(data []byte) bool {
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return false
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return false
+	}
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			return true
+		}
+	}
+	return false
+}
+
+// docx reads a document.
+type docx struct {
+	converter
+	headings map[string]int          // the heading level of the paragraph styles
+	ordered  map[string]map[int]bool // whether the levels of the numberings are numbered rather than bulleted
+	links    map[string]string       // the targets of the hyperlink relationships
+	counters map[string]map[int]int  // the current number of the items of the numberings
+	tables   []*structure.Table      // the tables being read, the innermost last
+}
+
+// read converts the document.xml part.
+func (d *docx) read(document []byte) error {
+	dec := xml.NewDecoder(bytes.NewReader(document))
+	var p *docxParagraph
+	var run docxRun
+	var link string
+	var cell *strings.Builder
+	deleted := 0
+	inRun := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Space != wordNamespace {
+				continue
+			}
+			switch t.Name.Local {
+			case "del":
+				deleted++
+			case "p":
+				p = &docxParagraph{}
+			case "pStyle":
+				if p != nil {
+					p.style = wordAttr(t, "val")
+				}
+			case "outlineLvl":
+				if n, err := strconv.Atoi(wordAttr(t, "val")); err == nil && p != nil {
+					p.outline = n + 1
+				}
+			case "numId":
+				if p != nil {
+					p.numID = wordAttr(t, "val")
+					p.list = p.numID != "0"
+				}
+			case "ilvl":
+				if n, err := strconv.Atoi(wordAttr(t, "val")); err == nil && p != nil {
+					p.level = n
+				}
+			case "r":
+				run = docxRun{link: link}
+				inRun = true
+			case "b":
+				run.bold = isOn(t)
+			case "i":
+				run.italic = isOn(t)
+			case "strike", "dstrike":
+				run.strike = isOn(t)
+			case "hyperlink":
+				for _, a := range t.Attr {
+					if a.Name.Space == relNamespace && a.Name.Local == "id" {
+						link = d.links[a.Value]
+					}
+				}
+			case "tab":
+				// The tab stops of the paragraph properties are tab elements as well
+				if p != nil && inRun && deleted == 0 {
+					p.runs = append(p.runs, docxRun{text: " "})
+				}
+			case "br", "cr":
+				if p != nil && deleted == 0 {
+					p.runs = append(p.runs, docxRun{text: "\n"})
+				}
+			case "t":
+				var text string
+				if err := dec.DecodeElement(&text, &t); err != nil {
+					return err
+				}
+				if p != nil && deleted == 0 {
+					r := run
+					r.text = text
+					p.runs = append(p.runs, r)
+				}
+			case "tbl":
+				d.tables = append(d.tables, &structure.Table{})
+			case "tr":
+				if len(d.tables) == 1 {
+					t := d.tables[0]
+					if t.Header == nil {
+						t.Header = []string{}
+					} else {
+						t.Rows = append(t.Rows, []string{})
+					}
+				}
+			case "tc":
+				if len(d.tables) == 1 {
+					cell = &strings.Builder{}
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Space != wordNamespace {
+				continue
+			}
+			switch t.Name.Local {
+			case "del":
+				deleted--
+			case "r":
+				inRun = false
+			case "hyperlink":
+				link = ""
+			case "p":
+				if p == nil {
+					continue
+				}
+				if cell != nil {
+					// The paragraphs of a cell are joined
+					text := strings.TrimSpace(strings.Join(strings.Fields(runsMarkdown(p.runs)), " "))
+					if text != "" {
+						if cell.Len() > 0 {
+							cell.WriteString(" ")
+						}
+						cell.WriteString(text)
+					}
+				} else {
+					d.paragraph(p)
+				}
+				p = nil
+			case "tc":
+				if len(d.tables) == 1 && cell != nil {
+					t := d.tables[0]
+					if len(t.Rows) == 0 {
+						t.Header = append(t.Header, cell.String())
+					} else {
+						t.Rows[len(t.Rows)-1] = append(t.Rows[len(t.Rows)-1], cell.String())
+					}
+					cell = nil
+				}
+			case "tbl":
+				table := d.tables[len(d.tables)-1]
+				d.tables = d.tables[:len(d.tables)-1]
+				if len(d.tables) == 0 && !table.Empty() {
+					d.emit(strings.TrimSuffix(slidesutils.MarkdownTable(*table), "\n"), false)
+				}
+			}
+		}
+	}
+}
+
+// paragraph writes a paragraph of the body.
+func (d *docx) paragraph(p *docxParagraph) {
+	level := d.headings[p.style]
+	if level == 0 {
+		level = p.outline
+	}
+	if level > 0 {
+		var sb strings.Builder
+		for _, r := range p.runs {
+			sb.WriteString(r.text)
+		}
+		if text := strings.Join(strings.Fields(sb.String()), " "); text != "" {
+			d.emit(strings.Repeat("#", min(6, level))+" "+text, false)
+		}
+		return
+	}
+	text := strings.TrimSpace(runsMarkdown(p.runs))
+	if text == "" {
+		return
+	}
+	if !p.list {
+		d.emit(text, false)
+		return
+	}
+	bullet := "- "
+	if d.ordered[p.numID][p.level] {
+		if d.counters == nil {
+			d.counters = make(map[string]map[int]int)
+		}
+		counters := d.counters[p.numID]
+		if counters == nil {
+			counters = make(map[int]int)
+			d.counters[p.numID] = counters
+		}
+		// A new item restarts the numbering of the deeper levels
+		for level := range counters {
+			if level > p.level {
+				delete(counters, level)
+			}
+		}
+		counters[p.level]++
+		bullet = strconv.Itoa(counters[p.level]) + ". "
+	}
+	indent := strings.Repeat("  ", p.level)
+	d.emit(indent+bullet+strings.ReplaceAll(text, "\n", "\n"+indent+strings.Repeat(" ", len(bullet))), true)
+}
+
+// runsMarkdown returns the Markdown of the runs; the consecutive runs with the same formatting are merged.
+func runsMarkdown(runs []docxRun) string {
+	var merged []docxRun
+	for _, r := range runs {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			if last.bold == r.bold && last.italic == r.italic && last.strike == r.strike && last.link == r.link {
+				last.text += r.text
+				continue
+			}
+		}
+		merged = append(merged, r)
+	}
+	var sb strings.Builder
+	for _, r := range merged {
+		text := r.text
+		if r.strike {
+			text = wrap(text, "~~", "~~")
+		}
+		if r.italic {
+			text = wrap(text, "*", "*")
+		}
+		if r.bold {
+			text = wrap(text, "**", "**")
+		}
+		if strings.HasPrefix(r.link, "http://") || strings.HasPrefix(r.link, "https://") {
+			text = wrap(text, "[", "]("+r.link+")")
+		}
+		sb.WriteString(text)
+	}
+	return sb.String()
+}
+
+// wordAttr returns the value of the attribute of the WordprocessingML namespace.
+func wordAttr(t xml.StartElement, name string) string {
+	for _, a := range t.Attr {
+		if a.Name.Local == name && (a.Name.Space == wordNamespace || a.Name.Space == "") {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// isOn reports whether a toggle property, such as w:b, is set: it is unless its value is false.
+func isOn(t xml.StartElement) bool {
+	switch wordAttr(t, "val") {
+	case "0", "false", "off", "none":
+		return false
+	}
+	return true
+}
+
+var headingStyle = regexp.MustCompile(`^heading\s*([1-6])$`)
+
+// docxHeadings returns the heading level of the paragraph styles, from their names (Title, Heading 1 and so on)
+// or their outline level. The styles part is optional.
+func docxHeadings(zr *zip.Reader) map[string]int {
+	var styles struct {
+		Styles []struct {
+			ID   string `xml:"styleId,attr"`
+			Name struct {
+				Val string `xml:"val,attr"`
+			} `xml:"name"`
+			Outline *struct {
+				Val int `xml:"val,attr"`
+			} `xml:"pPr>outlineLvl"`
+		} `xml:"style"`
+	}
+	headings := map[string]int{"Title": 1}
+	if err := decodeXML(zr, "word/styles.xml", &styles); err != nil {
+		return headings
+	}
+	for _, s := range styles.Styles {
+		name := strings.ToLower(s.Name.Val)
+		switch m := headingStyle.FindStringSubmatch(name); {
+		case name == "title":
+			headings[s.ID] = 1
+		case m != nil:
+			headings[s.ID], _ = strconv.Atoi(m[1])
+		case s.Outline != nil && s.Outline.Val < 6:
+			headings[s.ID] = s.Outline.Val + 1
+		}
+	}
+	return headings
+}
+
+// docxOrdered returns, for each numbering, whether its levels are numbered. The numbering part is optional.
+func docxOrdered(zr *zip.Reader) map[string]map[int]bool {
+	var numbering struct {
+		Abstract []struct {
+			ID     string `xml:"abstractNumId,attr"`
+			Levels []struct {
+				Level  int `xml:"ilvl,attr"`
+				Format struct {
+					Val string `xml:"val,attr"`
+				} `xml:"numFmt"`
+			} `xml:"lvl"`
+		} `xml:"abstractNum"`
+		Nums []struct {
+			ID       string `xml:"numId,attr"`
+			Abstract struct {
+				Val string `xml:"val,attr"`
+			} `xml:"abstractNumId"`
+		} `xml:"num"`
+	}
+	ordered := make(map[string]map[int]bool)
+	if err := decodeXML(zr, "word/numbering.xml", &numbering); err != nil {
+		return ordered
+	}
+	abstract := make(map[string]map[int]bool)
+	for _, a := range numbering.Abstract {
+		levels := make(map[int]bool)
+		for _, l := range a.Levels {
+			levels[l.Level] = l.Format.Val != "bullet" && l.Format.Val != "none" && l.Format.Val != ""
+		}
+		abstract[a.ID] = levels
+	}
+	for _, n := range numbering.Nums {
+		ordered[n.ID] = abstract[n.Abstract.Val]
+	}
+	return ordered
+}
+
+// docxLinks returns the targets of the relationships of the document. The relationships part is optional.
+func docxLinks(zr *zip.Reader) map[string]string {
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	links := make(map[string]string)
+	if err := decodeXML(zr, "word/_rels/document.xml.rels", &rels); err != nil {
+		return links
+	}
+	for _, r := range rels.Relationships {
+		links[r.ID] = r.Target
+	}
+	return links
+}
@@ -0,0 +1,129 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// epubContainer is META-INF/container.xml, giving the package document of the book.
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// epubPackage is the package document: the metadata, the files and their reading order.
+type epubPackage struct {
+	Title    string `xml:"metadata>title"`
+	Manifest []struct {
+		ID        string `xml:"id,attr"`
+		Href      string `xml:"href,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef  string `xml:"idref,attr"`
+		Linear string `xml:"linear,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// epubText returns the documents of the book, in reading order, as Markdown under the title of the book.
+// The headings of the documents are shifted so that the highest one is a level 2 heading, a chapter of the book.
+func epubText(filename string, data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+	var container epubContainer
+	if err := decodeXML(zr, "META-INF/container.xml", &container); err != nil {
+		return "", err
+	}
+	if len(container.Rootfiles) == 0 {
+		return "", fmt.Errorf("no package document")
+	}
+	opf := container.Rootfiles[0].FullPath
+	var pkg epubPackage
+	if err := decodeXML(zr, opf, &pkg); err != nil {
+		return "", err
+	}
+	hrefs := make(map[string]string)
+	for _, item := range pkg.Manifest {
+		if item.MediaType == "application/xhtml+xml" || item.MediaType == "text/html" {
+			href, err := url.PathUnescape(item.Href)
+			if err != nil {
+				href = item.Href
+			}
+			hrefs[item.ID] = path.Join(path.Dir(opf), href)
+		}
+	}
+
+	// Parse the documents first, to find their highest heading
+	var docs []*html.Node
+	highest := 7
+	for _, itemref := range pkg.Spine {
+		href, ok := hrefs[itemref.IDRef]
+		if !ok || itemref.Linear == "no" {
+			continue
+		}
+		b, err := readZip(zr, href)
+		if err != nil {
+			return "", err
+		}
+		doc, err := html.Parse(bytes.NewReader(b))
+		if err != nil {
+			return "", fmt.Errorf("%v: %w", href, err)
+		}
+		root := mainContent(doc)
+		docs = append(docs, root)
+		findFunc(root, func(n *html.Node) bool {
+			if level, ok := headings[n.DataAtom]; ok {
+				highest = min(highest, level)
+			}
+			return false
+		})
+	}
+
+	c := converter{shift: max(0, 2-highest)}
+	if title := strings.TrimSpace(pkg.Title); title != "" {
+		c.emit("# "+title, false)
+	}
+	for _, doc := range docs {
+		c.walk(doc)
+		c.flush()
+	}
+	return c.sb.String(), nil
+}
+
+// isEPUB reports whether the data is a ZIP archive whose first file declares the EPUB media type.
+func isEPUB(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("PK\x03\x04")) && bytes.Contains(data[:min(len(data), 100)], []byte("application/epub+zip"))
+}
+
+// readZip returns the content of a file of the archive.
+func readZip(zr *zip.Reader, name string) ([]byte, error) {
+	f, err := zr.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// decodeXML decodes an XML file of the archive.
+func decodeXML(zr *zip.Reader, name string, v interface{}) error {
+	b, err := readZip(zr, name)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("%v: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Package extract converts the documents given as content (PDF, DOCX, HTML, EPUB, CSV and plain text) to Markdown text,
+in pure Go.
+
+The headings of the documents are kept as Markdown headings, so that the model (or the outline of the content) sees
+the structure of the document, and their tables as pipe tables. The extractor of a file is chosen by its extension or,
+for an unknown extension, by sniffing its content; more formats can be added with Register.
+*/
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// Extractor converts the content of a document to Markdown.
+// The filename is the name of the document, to title the documents that have no title of their own.
+type Extractor func(filename string, data []byte) (string, error)
+
+// format is a registered document format.
+type format struct {
+	name       string
+	extract    Extractor
+	sniff      func(data []byte) bool
+	extensions []string
+}
+
+var formats []format
+
+// Register adds the extractor of the documents with one of the extensions (such as ".pdf") or, whatever their extension,
+// recognized by sniff (which may be nil). The formats registered last take precedence.
+func Register(name string, e Extractor, sniff func(data []byte) bool, extensions ...string) {
+	formats = append(formats, format{name: name, extract: e, sniff: sniff, extensions: extensions})
+}
+
+func init() {
+	Register("text", plainText, isText, ".txt", ".md", ".markdown", ".text")
+	Register("csv", csvText, nil, ".csv")
+	Register("html", htmlText, isHTML, ".html", ".htm", ".xhtml")
+	Register("epub", epubText, isEPUB, ".epub")
+	Register("docx", docxText, isDOCX, ".docx")
+	Register("pdf", pdfText, isPDF, ".pdf")
+}
+
+// Text returns the content of the file as Markdown text, converted by the extractor of its format.
+// The format is given by the extension of the file, or recognized from the content if the extension is unknown.
+func Text(filename string, data []byte) (string, error) {
+	f, ok := detect(filename, data)
+	if !ok {
+		return "", fmt.Errorf("%v: unsupported format %v", filename, http.DetectContentType(data))
+	}
+	text, err := f.extract(filename, data)
+	if err != nil {
+		return "", fmt.Errorf("cannot extract the %v content of %v: %w", f.name, filename, err)
+	}
+	return text, nil
+}
+
+// Supported reports whether the extension of the file is the extension of a registered format.
+func Supported(filename string) bool {
+	extension := strings.ToLower(filepath.Ext(filename))
+	for _, f := range formats {
+		for _, e := range f.extensions {
+			if e == extension {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// detect returns the format of the file.
+func detect(filename string, data []byte) (format, bool) {
+	extension := strings.ToLower(filepath.Ext(filename))
+	for i := len(formats) - 1; i >= 0; i-- {
+		for _, e := range formats[i].extensions {
+			if e == extension {
+				return formats[i], true
+			}
+		}
+	}
+	for i := len(formats) - 1; i >= 0; i-- {
+		if formats[i].sniff != nil && formats[i].sniff(data) {
+			return formats[i], true
+		}
+	}
+	return format{}, false
+}
+
+// plainText returns the text as is: plain text and Markdown are read by the model directly.
+func plainText(filename string, data []byte) (string, error) {
+	return string(bytes.TrimPrefix(data, []byte("\ufeff"))), nil
+}
+
+func isText(data []byte) bool {
+	return strings.HasPrefix(http.DetectContentType(data), "text/plain")
+}
+
+func isHTML(data []byte) bool {
+	return strings.HasPrefix(http.DetectContentType(data), "text/html")
+}
+
+func isPDF(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("%PDF-"))
+}
@@ -0,0 +1,312 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// zipFile returns an archive of the files, given as name and content pairs, in order.
+func zipFile(t *testing.T, files ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i := 0; i+1 < len(files); i += 2 {
+		w, err := zw.Create(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(files[i+1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pdfFile returns a document of one page per content stream, with the Helvetica font as F1.
+// The streams are compressed if deflate is true.
+func pdfFile(t testing.TB, deflate bool, contents ...string) []byte {
+	t.Helper()
+	var objects []string
+	kids := make([]string, len(contents))
+	for i, content := range contents {
+		page, stream := 4+2*i, 5+2*i
+		kids[i] = fmt.Sprintf("%v 0 R", page)
+		objects = append(objects, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents %v 0 R >>", stream))
+		data, filter := content, ""
+		if deflate {
+			var buf bytes.Buffer
+			zw := zlib.NewWriter(&buf)
+			zw.Write([]byte(content))
+			zw.Close()
+			data, filter = buf.String(), " /Filter /FlateDecode"
+		}
+		objects = append(objects, fmt.Sprintf("<< /Length %v%v >>\nstream\n%v\nendstream", len(data), filter, data))
+	}
+	objects = append([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%v] /Count %v /Resources << /Font << /F1 3 0 R >> >> >>", strings.Join(kids, " "), len(contents)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding << /Differences [150 /endash] >> >>",
+	}, objects...)
+	var sb strings.Builder
+	sb.WriteString("%PDF-1.4\n")
+	for i, o := range objects {
+		fmt.Fprintf(&sb, "%v 0 obj\n%v\nendobj\n", i+1, o)
+	}
+	sb.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return []byte(sb.String())
+}
+
+func TestText(t *testing.T) {
+	const page1 = `BT /F1 24 Tf 72 720 Td (The Title) Tj ET
+BT /F1 16 Tf 72 680 Td (A chapter) Tj ET
+BT /F1 10 Tf 72 650 Td 12 TL (This is the first para-) Tj (graph of the ) ' [(chap) 20 (ter.)] TJ ET
+BT /F1 10 Tf 72 600 Td (\225 an item) Tj T* (\226 another item.) Tj ET
+BT /F1 10 Tf 300 40 Td (1) Tj ET`
+	const page2 = `BT /F1 10 Tf 72 720 Td (It goes on) Tj ET
+q 1 0 0 1 0 -100 cm BT /F1 10 Tf 72 720 Td <416E64206F6E> Tj ET Q`
+
+	tests := []struct {
+		name     string
+		filename string
+		data     []byte
+		want     string
+	}{
+		{
+			name:     "markdown",
+			filename: "notes.md",
+			data:     []byte("\ufeff# Notes\n\nSome text"),
+			want:     "# Notes\n\nSome text",
+		},
+		{
+			name:     "csv",
+			filename: "data/sales.csv",
+			data:     []byte("Year,Sales\n2023,12\n2024,15\n"),
+			want:     "# sales\n\n| Year | Sales |\n| --- | --- |\n| 2023 | 12 |\n| 2024 | 15 |\n",
+		},
+		{
+			name:     "html",
+			filename: "page.html",
+			data: []byte(`<html><head><title>Page title</title><script>var x = 1;</script></head>
+<body>
+<nav><a href="/">Home</a> <a href="/blog">Blog</a></nav>
+<header><img src="logo.png"> Site name</header>
+<article>
+<h1>The <em>article</em></h1>
+<p>Some <b>bold</b> text
+with a <a href="https://example.com">link</a> and <code>code</code>.</p>
+<h2>A list</h2>
+<ul><li>one</li><li>two<ol><li>nested</li></ol></li></ul>
+<pre><code class="language-go">func main() {
+	fmt.Println("hello")
+}</code></pre>
+<blockquote><p>A quote</p></blockquote>
+<table><tr><th>Name</th><th>Value</th></tr><tr><td>a</td><td>1</td></tr></table>
+<aside class="share">Share this</aside>
+</article>
+<footer>Copyright</footer>
+</body></html>`),
+			want: "# The *article*\n\n" +
+				"Some **bold** text with a [link](https://example.com) and `code`.\n\n" +
+				"## A list\n\n" +
+				"- one\n- two\n  1. nested\n\n" +
+				"```go\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n```\n\n" +
+				"> A quote\n\n" +
+				"| Name | Value |\n| --- | --- |\n| a | 1 |",
+		},
+		{
+			name:     "html title",
+			filename: "page.htm",
+			data:     []byte(`<title>The title</title><main><p>Text</p></main>`),
+			want:     "# The title\n\nText",
+		},
+		{
+			name:     "docx",
+			filename: "report.docx",
+			data: zipFile(t,
+				"word/document.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<w:body>
+<w:p><w:pPr><w:pStyle w:val="Title"/></w:pPr><w:r><w:t>The report</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Introduction</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Some </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>bold</w:t></w:r><w:r><w:t xml:space="preserve"> and </w:t></w:r><w:hyperlink r:id="rId1"><w:r><w:t>a link</w:t></w:r></w:hyperlink><w:del><w:r><w:delText>deleted</w:delText></w:r></w:del></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>first</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>second</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:t>bullet</w:t></w:r></w:p>
+<w:tbl>
+<w:tr><w:tc><w:p><w:r><w:t>Name</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Value</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:p><w:r><w:t>a</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>1</w:t></w:r></w:p></w:tc></w:tr>
+</w:tbl>
+</w:body>
+</w:document>`,
+				"word/styles.xml", `<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/></w:style>
+</w:styles>`,
+				"word/numbering.xml", `<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:numFmt w:val="decimal"/></w:lvl></w:abstractNum>
+<w:abstractNum w:abstractNumId="1"><w:lvl w:ilvl="1"><w:numFmt w:val="bullet"/></w:lvl></w:abstractNum>
+<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>
+<w:num w:numId="2"><w:abstractNumId w:val="1"/></w:num>
+</w:numbering>`,
+				"word/_rels/document.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="https://example.com" TargetMode="External"/>
+</Relationships>`,
+			),
+			want: "# The report\n\n" +
+				"# Introduction\n\n" +
+				"Some **bold** and [a link](https://example.com)\n\n" +
+				"1. first\n2. second\n  - bullet\n\n" +
+				"| Name | Value |\n| --- | --- |\n| a | 1 |",
+		},
+		{
+			name:     "epub",
+			filename: "book.epub",
+			data: zipFile(t,
+				"mimetype", "application/epub+zip",
+				"META-INF/container.xml", `<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>`,
+				"OEBPS/content.opf", `<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>The Book</dc:title></metadata>
+<manifest>
+<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+<item id="c1" href="text/chapter%201.xhtml" media-type="application/xhtml+xml"/>
+<item id="c2" href="text/chapter2.xhtml" media-type="application/xhtml+xml"/>
+</manifest>
+<spine><itemref idref="nav" linear="no"/><itemref idref="c1"/><itemref idref="c2"/></spine>
+</package>`,
+				"OEBPS/nav.xhtml", `<html><body><nav><ol><li>Contents</li></ol></nav></body></html>`,
+				"OEBPS/text/chapter 1.xhtml", `<html><body><h1>First</h1><p>Once upon a time</p><h2>A part</h2><p>Text</p></body></html>`,
+				"OEBPS/text/chapter2.xhtml", `<html><body><h1>Second</h1><p>The end</p></body></html>`,
+			),
+			want: "# The Book\n\n" +
+				"## First\n\nOnce upon a time\n\n### A part\n\nText\n\n" +
+				"## Second\n\nThe end",
+		},
+		{
+			name:     "pdf",
+			filename: "paper.pdf",
+			data:     pdfFile(t, false, page1, page2),
+			want: "# The Title\n\n" +
+				"## A chapter\n\n" +
+				"This is the first paragraph of the chapter.\n\n" +
+				"- an item\n- another item.\n\n" +
+				"It goes on\n\n" +
+				"And on",
+		},
+		{
+			name:     "compressed pdf",
+			filename: "paper.pdf",
+			data:     pdfFile(t, true, page1),
+			want: "# The Title\n\n" +
+				"## A chapter\n\n" +
+				"This is the first paragraph of the chapter.\n\n" +
+				"- an item\n- another item.",
+		},
+		{
+			name:     "sniffed",
+			filename: "download",
+			data:     pdfFile(t, false, "BT /F1 12 Tf 72 720 Td (Hello) Tj ET"),
+			want:     "Hello",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Text(tt.filename, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Text() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSupported(t *testing.T) {
+	for filename, want := range map[string]bool{
+		"notes.md":       true,
+		"Report.DOCX":    true,
+		"book.epub":      true,
+		"picture.png":    false,
+		"README":         false,
+		"archive.pdf.7z": false,
+	} {
+		if got := Supported(filename); got != want {
+			t.Errorf("Supported(%q) = %v, want %v", filename, got, want)
+		}
+	}
+}
+
+func TestTextFormCycle(t *testing.T) {
+	// The form X draws itself twice
+	form := "BT /F1 12 Tf 72 700 Td (Hello) Tj ET /X Do /X Do"
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources 6 0 R >>",
+		"<< /Length 5 >>\nstream\n/X Do\nendstream",
+		fmt.Sprintf("<< /Type /XObject /Subtype /Form /Resources 6 0 R /Length %v >>\nstream\n%v\nendstream", len(form), form),
+		"<< /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >> /XObject << /X 5 0 R >> >>",
+	}
+	var sb strings.Builder
+	sb.WriteString("%PDF-1.4\n")
+	for i, o := range objects {
+		fmt.Fprintf(&sb, "%v 0 obj\n%v\nendobj\n", i+1, o)
+	}
+	sb.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	got, err := Text("cycle.pdf", []byte(sb.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(got) != "Hello" {
+		t.Errorf("Text() = %q, want the text of the form once", got)
+	}
+}
+
+func TestTextErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		data     []byte
+	}{
+		{name: "unknown format", filename: "image", data: []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")},
+		{name: "broken docx", filename: "report.docx", data: []byte("not a zip")},
+		{name: "encrypted pdf", filename: "secret.pdf", data: []byte("%PDF-1.4\ntrailer\n<< /Root 1 0 R /Encrypt 2 0 R >>\n")},
+		{name: "cyclic page tree", filename: "cycle.pdf", data: []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n2 0 obj\n<< /Type /Pages /Kids [2 0 R 2 0 R] /Count 2 >>\nendobj\ntrailer\n<< /Root 1 0 R >>\n")},
+		{name: "negative object stream offset", filename: "objstm.pdf", data: []byte("%PDF-1.5\n1 0 obj\n<< /Type /ObjStm /N 1 /First -50 /Length 6 >>\nstream\n2 0 <<\nendstream\nendobj\ntrailer\n<< /Root 2 0 R >>\n")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Text(tt.filename, tt.data); err == nil {
+				t.Error("Text() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestInflateLimit(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(make([]byte, maxStreamSize+1))
+	zw.Close()
+	if _, err := inflate(buf.Bytes()); err == nil {
+		t.Errorf("inflate() of %v bytes succeeded, want an error", maxStreamSize+1)
+	}
+}
+
+func FuzzPDF(f *testing.F) {
+	f.Add(pdfFile(f, false, "BT /F1 12 Tf 72 720 Td (Hello) Tj ET"))
+	f.Add(pdfFile(f, true, "BT /F1 24 Tf 72 720 Td (Title) Tj /F1 12 Tf 0 -20 Td [(Body) -250 (text)] TJ ET"))
+	f.Add([]byte("%PDF-1.5\n1 0 obj\n<< /Type /ObjStm /N 1 /First 4 /Length 10 >>\nstream\n2 0 << >>\nendstream\nendobj\ntrailer\n<< /Root 2 0 R >>\n"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// readPDF does not recover from the panics, unlike pdfText
+		readPDF(data)
+	})
+}
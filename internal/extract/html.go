@@ -0,0 +1,386 @@
+package extract
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// htmlText returns the main content of the page as Markdown: the article (or the main element, or the body),
+// without the navigation, the forms, the scripts and the other boilerplate.
+// The title of the page is the level 1 heading if the content has none.
+func htmlText(filename string, data []byte) (string, error) {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	root := mainContent(doc)
+	c := converter{}
+	if title := find(doc, atom.Title); title != nil && find(root, atom.H1) == nil {
+		if text := strings.TrimSpace(collapse(textContent(title))); text != "" {
+			c.emit("# "+text, false)
+		}
+	}
+	c.walk(root)
+	c.flush()
+	return c.sb.String(), nil
+}
+
+// skipped are the elements whose content is not part of the text.
+var skipped = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Nav: true, atom.Aside: true, atom.Footer: true, atom.Form: true, atom.Button: true, atom.Select: true,
+	atom.Input: true, atom.Textarea: true, atom.Svg: true, atom.Iframe: true, atom.Object: true, atom.Canvas: true,
+}
+
+var headings = map[atom.Atom]int{atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6}
+
+// mainContent returns the element holding the content of the page.
+func mainContent(doc *html.Node) *html.Node {
+	for _, a := range []atom.Atom{atom.Article, atom.Main} {
+		if n := find(doc, a); n != nil {
+			return n
+		}
+	}
+	if n := findFunc(doc, func(n *html.Node) bool { return attr(n, "role") == "main" }); n != nil {
+		return n
+	}
+	if n := find(doc, atom.Body); n != nil {
+		return n
+	}
+	return doc
+}
+
+// isBoilerplate reports whether the element is not part of the content, such as a header without any heading.
+func isBoilerplate(n *html.Node) bool {
+	if skipped[n.DataAtom] || attr(n, "hidden") != "" || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	switch attr(n, "role") {
+	case "navigation", "banner", "contentinfo", "search", "complementary":
+		return true
+	}
+	if n.DataAtom == atom.Header {
+		return findFunc(n, func(n *html.Node) bool { return headings[n.DataAtom] > 0 }) == nil
+	}
+	return false
+}
+
+// converter writes the Markdown of the blocks of a page.
+type converter struct {
+	sb     strings.Builder
+	inline strings.Builder // the text of the current paragraph
+	prefix string          // the bullet of the current list item, written before its first paragraph
+	indent string          // the indentation of the paragraphs of the current list item
+	quote  int             // the depth of the block quotes
+	lists  []*list
+	shift  int  // the number of levels added to the headings
+	item   bool // whether the last block written is a list item
+}
+
+type list struct {
+	ordered bool
+	n       int
+}
+
+// emit writes a block; the list items follow each other without a blank line.
+func (c *converter) emit(block string, item bool) {
+	if c.sb.Len() > 0 {
+		if item && c.item {
+			c.sb.WriteString("\n")
+		} else {
+			c.sb.WriteString("\n\n")
+		}
+	}
+	if c.quote > 0 {
+		q := strings.Repeat("> ", c.quote)
+		block = q + strings.ReplaceAll(block, "\n", "\n"+q)
+	}
+	c.sb.WriteString(block)
+	c.item = item
+}
+
+// flush writes the current paragraph, if any.
+func (c *converter) flush() {
+	text := strings.TrimSpace(spaces.ReplaceAllString(c.inline.String(), " "))
+	text = strings.ReplaceAll(text, "\n ", "\n")
+	c.inline.Reset()
+	if text == "" {
+		return
+	}
+	if c.prefix != "" {
+		c.emit(c.prefix+strings.ReplaceAll(text, "\n", "\n"+c.indent), true)
+		c.prefix = ""
+		return
+	}
+	c.emit(c.indent+strings.ReplaceAll(text, "\n", "\n"+c.indent), c.indent != "")
+}
+
+// walk converts the children of the node.
+func (c *converter) walk(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.node(child)
+	}
+}
+
+func (c *converter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		c.inline.WriteString(collapse(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		c.walk(n)
+		return
+	}
+	if isBoilerplate(n) {
+		return
+	}
+	if level, ok := headings[n.DataAtom]; ok {
+		c.flush()
+		if text := strings.TrimSpace(spaces.ReplaceAllString(inline(n), " ")); text != "" {
+			c.emit(strings.Repeat("#", min(6, level+c.shift))+" "+text, false)
+		}
+		return
+	}
+	switch n.DataAtom {
+	case atom.Ul, atom.Ol:
+		c.flush()
+		start := 1
+		if s, err := strconv.Atoi(attr(n, "start")); err == nil {
+			start = s
+		}
+		c.lists = append(c.lists, &list{ordered: n.DataAtom == atom.Ol, n: start})
+		c.walk(n)
+		c.flush()
+		c.lists = c.lists[:len(c.lists)-1]
+	case atom.Li:
+		c.flush()
+		indent, prefix := c.indent, c.prefix
+		bullet := "- "
+		if len(c.lists) > 0 {
+			l := c.lists[len(c.lists)-1]
+			if l.ordered {
+				bullet = strconv.Itoa(l.n) + ". "
+				l.n++
+			}
+			c.indent = strings.Repeat("  ", len(c.lists)-1)
+		}
+		c.prefix = c.indent + bullet
+		c.indent += strings.Repeat(" ", len(bullet))
+		c.walk(n)
+		c.flush()
+		c.indent, c.prefix = indent, prefix
+	case atom.Pre:
+		c.flush()
+		code := strings.Trim(textContent(n), "\n")
+		if code != "" {
+			c.emit("```"+language(n)+"\n"+code+"\n```", false)
+		}
+	case atom.Blockquote:
+		c.flush()
+		c.quote++
+		c.walk(n)
+		c.flush()
+		c.quote--
+	case atom.Table:
+		c.flush()
+		if t := table(n); !t.Empty() {
+			c.emit(strings.TrimSuffix(slidesutils.MarkdownTable(t), "\n"), false)
+		}
+	case atom.Br:
+		c.inline.WriteString("\n")
+	case atom.Hr:
+		c.flush()
+	default:
+		if isInline(n) {
+			c.inline.WriteString(inline(n))
+			return
+		}
+		// A block: its text is a paragraph of its own
+		c.flush()
+		c.walk(n)
+		c.flush()
+	}
+}
+
+// inlineElements are the elements flowing within a paragraph.
+var inlineElements = map[atom.Atom]bool{
+	atom.A: true, atom.Abbr: true, atom.B: true, atom.Cite: true, atom.Code: true, atom.Del: true, atom.Em: true,
+	atom.I: true, atom.Kbd: true, atom.Label: true, atom.Mark: true, atom.Q: true, atom.S: true, atom.Samp: true,
+	atom.Small: true, atom.Span: true, atom.Strike: true, atom.Strong: true, atom.Sub: true, atom.Sup: true,
+	atom.Time: true, atom.U: true, atom.Img: true, atom.Font: true, atom.Var: true, atom.Dfn: true, atom.Ins: true,
+}
+
+func isInline(n *html.Node) bool {
+	return inlineElements[n.DataAtom]
+}
+
+// inline returns the Markdown of the content of an inline element.
+func inline(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return collapse(n.Data)
+	}
+	if n.Type == html.ElementNode && isBoilerplate(n) {
+		return ""
+	}
+	var sb strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		sb.WriteString(inline(child))
+	}
+	text := sb.String()
+	if n.Type != html.ElementNode {
+		return text
+	}
+	switch n.DataAtom {
+	case atom.B, atom.Strong:
+		return wrap(text, "**", "**")
+	case atom.I, atom.Em, atom.Cite, atom.Dfn, atom.Var:
+		return wrap(text, "*", "*")
+	case atom.S, atom.Del, atom.Strike:
+		return wrap(text, "~~", "~~")
+	case atom.U, atom.Ins:
+		return wrap(text, "<u>", "</u>")
+	case atom.Code, atom.Kbd, atom.Samp:
+		return wrap(collapse(textContent(n)), "`", "`")
+	case atom.A:
+		href := attr(n, "href")
+		if strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") {
+			return wrap(text, "[", "]("+href+")")
+		}
+	case atom.Br:
+		return "\n"
+	case atom.Img:
+		return ""
+	}
+	return text
+}
+
+// wrap surrounds the text with the delimiters, keeping its surrounding spaces outside.
+func wrap(text, open, close string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	start := strings.Index(text, trimmed)
+	return text[:start] + open + trimmed + close + text[start+len(trimmed):]
+}
+
+// table returns the table, its first row being the header.
+func table(n *html.Node) structure.Table {
+	var rows [][]string
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			switch child.DataAtom {
+			case atom.Tr:
+				var cells []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.DataAtom == atom.Td || cell.DataAtom == atom.Th {
+						cells = append(cells, strings.TrimSpace(spaces.ReplaceAllString(inline(cell), " ")))
+					}
+				}
+				rows = append(rows, cells)
+			case atom.Table:
+				// A nested table is not a row of this one
+			default:
+				visit(child)
+			}
+		}
+	}
+	visit(n)
+	if len(rows) == 0 {
+		return structure.Table{}
+	}
+	return structure.Table{Header: rows[0], Rows: rows[1:]}
+}
+
+// language returns the language of a code block, given by a class such as language-go on the pre or code element.
+func language(pre *html.Node) string {
+	for _, n := range []*html.Node{pre, find(pre, atom.Code)} {
+		if n == nil {
+			continue
+		}
+		for _, class := range strings.Fields(attr(n, "class")) {
+			for _, prefix := range []string{"language-", "lang-"} {
+				if lang, ok := strings.CutPrefix(class, prefix); ok {
+					return lang
+				}
+			}
+		}
+	}
+	return ""
+}
+
+var spaces = regexp.MustCompile(`[ \t\r\f]+`)
+
+// collapse replaces the white space of the text by single spaces, as a browser does.
+func collapse(s string) string {
+	text := strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return ""
+	}
+	if text == "" {
+		return " "
+	}
+	if first, _ := utf8.DecodeRuneInString(s); unicode.IsSpace(first) {
+		text = " " + text
+	}
+	if last, _ := utf8.DecodeLastRuneInString(s); unicode.IsSpace(last) {
+		text += " "
+	}
+	return text
+}
+
+// textContent returns the text of the node and its descendants, as is.
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.DataAtom == atom.Br {
+			sb.WriteString("\n")
+			continue
+		}
+		sb.WriteString(textContent(child))
+	}
+	return sb.String()
+}
+
+// find returns the first element of the tree with the given tag.
+func find(n *html.Node, a atom.Atom) *html.Node {
+	return findFunc(n, func(n *html.Node) bool { return n.DataAtom == a })
+}
+
+// findFunc returns the first element of the tree, in document order, for which match returns true.
+func findFunc(n *html.Node, match func(*html.Node) bool) *html.Node {
+	if n.Type == html.ElementNode && match(n) {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := findFunc(child, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// attr returns the value of the attribute of the element.
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
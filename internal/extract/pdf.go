@@ -0,0 +1,591 @@
+package extract
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// The objects of a PDF file; the numbers are float64, the booleans bool and null nil.
+type (
+	pdfName    string
+	pdfString  string // the bytes of a literal or hexadecimal string
+	pdfKeyword string // an operator, or a delimiter such as << or [
+	pdfRef     struct{ num, gen int }
+	pdfDict    map[pdfName]interface{}
+	pdfArray   []interface{}
+	pdfStream  struct {
+		dict pdfDict
+		data []byte // the encoded data
+	}
+)
+
+// pdfLexer reads the tokens and the objects of a PDF file or of a content stream.
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// skipSpace skips the white space and the comments.
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		switch c := l.data[l.pos]; {
+		case isPDFSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// token returns the next token; it reports false at the end of the data.
+func (l *pdfLexer) token() (interface{}, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, false
+	}
+	switch c := l.data[l.pos]; c {
+	case '/':
+		l.pos++
+		return pdfName(unescapeName(l.word())), true
+	case '(':
+		return l.literalString(), true
+	case '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			return pdfKeyword("<<"), true
+		}
+		return l.hexString(), true
+	case '>':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '>' {
+			l.pos += 2
+			return pdfKeyword(">>"), true
+		}
+		l.pos++
+		return pdfKeyword(">"), true
+	case '[', ']', '{', '}', ')':
+		l.pos++
+		return pdfKeyword(string(rune(c))), true
+	}
+	word := l.word()
+	if c := word[0]; (c >= '0' && c <= '9') || c == '-' || c == '+' || c == '.' {
+		if n, err := strconv.ParseFloat(string(word), 64); err == nil {
+			return n, true
+		}
+	}
+	return pdfKeyword(word), true
+}
+
+// word returns the regular characters at the position.
+func (l *pdfLexer) word() []byte {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	if l.pos == start {
+		// An unexpected delimiter
+		l.pos++
+	}
+	return l.data[start:l.pos]
+}
+
+// unescapeName decodes the #xx escapes of a name.
+func unescapeName(b []byte) string {
+	if !bytes.ContainsRune(b, '#') {
+		return string(b)
+	}
+	var out []byte
+	for i := 0; i < len(b); i++ {
+		if b[i] == '#' && i+2 < len(b) {
+			if v, err := strconv.ParseUint(string(b[i+1:i+3]), 16, 8); err == nil {
+				out = append(out, byte(v))
+				i += 2
+				continue
+			}
+		}
+		out = append(out, b[i])
+	}
+	return string(out)
+}
+
+// literalString reads a string between balanced parentheses, decoding its escapes.
+func (l *pdfLexer) literalString() pdfString {
+	l.pos++
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return pdfString(out)
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				continue
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				// A line continuation
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				} else {
+					c = e
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return pdfString(out)
+}
+
+// hexString reads a string of hexadecimal digits between angle brackets.
+func (l *pdfLexer) hexString() pdfString {
+	l.pos++
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; !isPDFSpace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	b := make([]byte, len(digits)/2)
+	n, _ := hex.Decode(b, digits)
+	return pdfString(b[:n])
+}
+
+// object reads the next object; the keywords that are not part of an object, such as the operators, are returned as is.
+func (l *pdfLexer) object() (interface{}, bool) {
+	tok, ok := l.token()
+	if !ok {
+		return nil, false
+	}
+	return l.objectFrom(tok, 0), true
+}
+
+func (l *pdfLexer) objectFrom(tok interface{}, depth int) interface{} {
+	if depth > 100 {
+		return nil
+	}
+	switch t := tok.(type) {
+	case pdfKeyword:
+		switch t {
+		case "<<":
+			dict := pdfDict{}
+			for {
+				key, ok := l.token()
+				if !ok || key == pdfKeyword(">>") || key == pdfKeyword("endobj") {
+					return dict
+				}
+				name, ok := key.(pdfName)
+				if !ok {
+					continue
+				}
+				value, ok := l.token()
+				if !ok || value == pdfKeyword(">>") {
+					return dict
+				}
+				dict[name] = l.objectFrom(value, depth+1)
+			}
+		case "[":
+			var array pdfArray
+			for {
+				tok, ok := l.token()
+				if !ok || tok == pdfKeyword("]") || tok == pdfKeyword("endobj") {
+					return array
+				}
+				array = append(array, l.objectFrom(tok, depth+1))
+			}
+		case "true", "false":
+			return t == "true"
+		case "null":
+			return nil
+		}
+	case float64:
+		// Two integers followed by R are a reference
+		if t == float64(int(t)) && t >= 0 {
+			save := l.pos
+			if gen, ok := l.token(); ok {
+				if g, ok := gen.(float64); ok && g == float64(int(g)) {
+					if r, ok := l.token(); ok && r == pdfKeyword("R") {
+						return pdfRef{int(t), int(g)}
+					}
+				}
+			}
+			l.pos = save
+		}
+	}
+	return tok
+}
+
+// pdfDocument holds the objects of a PDF file.
+type pdfDocument struct {
+	objects map[int]interface{}
+	trailer pdfDict
+	decoded int // the size of the streams decoded so far, bounded by maxDecodedSize
+}
+
+var (
+	pdfObjectStart = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+	pdfTrailer     = regexp.MustCompile(`trailer\s*<<`)
+	endstream      = []byte("endstream")
+)
+
+// loadPDF reads the objects of the file. The cross-reference table is not used: the objects are found by scanning the
+// file, so that damaged or incrementally updated files are read as well, the last definition of an object winning.
+func loadPDF(data []byte) (*pdfDocument, error) {
+	doc := &pdfDocument{objects: make(map[int]interface{})}
+	skipUntil := 0
+	// An object is read up to the start of the next one, so that a damaged object cannot make the reading of each
+	// of the following ones run to the end of the file
+	matches := pdfObjectStart.FindAllSubmatchIndex(data, -1)
+	for i, m := range matches {
+		if m[0] < skipUntil || (m[0] > 0 && !isPDFSpace(data[m[0]-1]) && !isPDFDelimiter(data[m[0]-1])) {
+			continue
+		}
+		num, err := strconv.Atoi(string(data[m[2]:m[3]]))
+		if err != nil {
+			continue
+		}
+		end := len(data)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		l := &pdfLexer{data: data[:end], pos: m[1]}
+		obj, ok := l.object()
+		if !ok {
+			continue
+		}
+		if dict, ok := obj.(pdfDict); ok {
+			save := l.pos
+			if tok, ok := l.token(); ok && tok == pdfKeyword("stream") {
+				// The data of the stream may hold anything, up to the endstream keyword
+				l.data = data
+				obj = pdfStream{dict: dict, data: l.streamData(dict)}
+				skipUntil = l.pos
+			} else {
+				l.pos = save
+			}
+		}
+		doc.objects[num] = obj
+	}
+	trailers := pdfTrailer.FindAllIndex(data, -1)
+	for i, m := range trailers {
+		end := len(data)
+		if i+1 < len(trailers) {
+			end = trailers[i+1][0]
+		}
+		l := &pdfLexer{data: data[:end], pos: m[1] - 2}
+		if obj, ok := l.object(); ok {
+			if dict, ok := obj.(pdfDict); ok {
+				doc.trailer = dict
+			}
+		}
+	}
+
+	// The objects of the object streams (PDF 1.5), and the trailer of the cross-reference streams
+	nums := make([]int, 0, len(doc.objects))
+	for num := range doc.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	for _, num := range nums {
+		s, ok := doc.objects[num].(pdfStream)
+		if !ok {
+			continue
+		}
+		switch s.dict["Type"] {
+		case pdfName("XRef"):
+			if doc.trailer == nil || doc.trailer["Root"] == nil {
+				doc.trailer = s.dict
+			}
+		case pdfName("ObjStm"):
+			doc.loadObjectStream(s)
+		}
+	}
+	if doc.trailer != nil && doc.trailer["Encrypt"] != nil {
+		return nil, fmt.Errorf("encrypted documents are not supported")
+	}
+	return doc, nil
+}
+
+// streamData returns the data of the stream whose keyword was just read, and moves after it.
+func (l *pdfLexer) streamData(dict pdfDict) []byte {
+	start := l.pos
+	if start < len(l.data) && l.data[start] == '\r' {
+		start++
+	}
+	if start < len(l.data) && l.data[start] == '\n' {
+		start++
+	}
+	if n, ok := dict["Length"].(float64); ok && n >= 0 && start+int(n) <= len(l.data) {
+		end := start + int(n)
+		rest := bytes.TrimLeft(l.data[end:min(len(l.data), end+20)], " \t\r\n")
+		if bytes.HasPrefix(rest, endstream) {
+			l.pos = end
+			return l.data[start:end]
+		}
+	}
+	// The length is indirect or wrong: the data ends before the endstream keyword
+	end := bytes.Index(l.data[start:], endstream)
+	if end < 0 {
+		l.pos = len(l.data)
+		return l.data[start:]
+	}
+	l.pos = start + end + len(endstream)
+	return bytes.TrimRight(l.data[start:start+end], "\r\n")
+}
+
+// loadObjectStream adds the objects of the object stream that are not defined in the file itself.
+func (doc *pdfDocument) loadObjectStream(s pdfStream) {
+	data, err := doc.decode(s)
+	if err != nil {
+		return
+	}
+	n, _ := doc.resolve(s.dict["N"]).(float64)
+	first, _ := doc.resolve(s.dict["First"]).(float64)
+	l := &pdfLexer{data: data}
+	for i := 0; i < int(n); i++ {
+		num, ok1 := l.token()
+		offset, ok2 := l.token()
+		if !ok1 || !ok2 {
+			return
+		}
+		objNum, ok1 := num.(float64)
+		objOffset, ok2 := offset.(float64)
+		if !ok1 || !ok2 {
+			return
+		}
+		if _, ok := doc.objects[int(objNum)]; ok {
+			continue
+		}
+		ol := &pdfLexer{data: data, pos: int(first) + int(objOffset)}
+		if first < 0 || objOffset < 0 || ol.pos >= len(data) {
+			continue
+		}
+		if obj, ok := ol.object(); ok {
+			doc.objects[int(objNum)] = obj
+		}
+	}
+}
+
+// resolve follows the references.
+func (doc *pdfDocument) resolve(obj interface{}) interface{} {
+	for i := 0; i < 32; i++ {
+		ref, ok := obj.(pdfRef)
+		if !ok {
+			return obj
+		}
+		obj = doc.objects[ref.num]
+	}
+	return nil
+}
+
+// dict returns the dictionary of the object, or of the stream.
+func (doc *pdfDocument) dict(obj interface{}) pdfDict {
+	switch o := doc.resolve(obj).(type) {
+	case pdfDict:
+		return o
+	case pdfStream:
+		return o.dict
+	}
+	return nil
+}
+
+// decode returns the decoded data of the stream.
+// Once maxDecodedSize bytes are decoded, such as by a stream drawn on every page, the other streams are not decoded.
+func (doc *pdfDocument) decode(s pdfStream) ([]byte, error) {
+	if doc.decoded > maxDecodedSize {
+		return nil, fmt.Errorf("the document is larger than %v bytes once decoded", maxDecodedSize)
+	}
+	var filters pdfArray
+	switch f := doc.resolve(s.dict["Filter"]).(type) {
+	case pdfName:
+		filters = pdfArray{f}
+	case pdfArray:
+		filters = f
+	}
+	data := s.data
+	for _, f := range filters {
+		var err error
+		switch doc.resolve(f) {
+		case pdfName("FlateDecode"), pdfName("Fl"):
+			data, err = inflate(data)
+		case pdfName("ASCIIHexDecode"), pdfName("AHx"):
+			l := &pdfLexer{data: append([]byte{'<'}, data...)}
+			data = []byte(l.hexString())
+		case pdfName("ASCII85Decode"), pdfName("A85"):
+			data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+			if i := bytes.Index(data, []byte("~>")); i >= 0 {
+				data = data[:i]
+			}
+			data, err = io.ReadAll(ascii85.NewDecoder(bytes.NewReader(data)))
+		default:
+			return nil, fmt.Errorf("unsupported filter %v", f)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	doc.decoded += len(data)
+	return data, nil
+}
+
+// The largest sizes of a decoded stream and of all the decoded streams of a document, so that small compressed
+// streams cannot fill the memory nor take hours to interpret.
+const (
+	maxStreamSize  = 32 << 20
+	maxDecodedSize = 128 << 20
+)
+
+// inflate decompresses zlib data, or raw deflate data; the data decompressed before an error is kept.
+func inflate(data []byte) ([]byte, error) {
+	var r io.ReadCloser
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+	defer r.Close()
+	out, err := io.ReadAll(io.LimitReader(r, maxStreamSize+1))
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	if len(out) > maxStreamSize {
+		return nil, fmt.Errorf("the stream is larger than %v bytes once decoded", maxStreamSize)
+	}
+	return out, nil
+}
+
+// pages returns the pages in order; their resources include the ones inherited from the page tree.
+// A node of the page tree listed several times, such as a node among its own kids, is walked once.
+func (doc *pdfDocument) pages() []pdfDict {
+	var pages []pdfDict
+	visited := make(map[int]bool)
+	var walk func(obj interface{}, resources interface{}, depth int)
+	walk = func(obj interface{}, resources interface{}, depth int) {
+		if ref, ok := obj.(pdfRef); ok {
+			if visited[ref.num] {
+				return
+			}
+			visited[ref.num] = true
+		}
+		node := doc.dict(obj)
+		if node == nil || depth > 64 {
+			return
+		}
+		if r, ok := node["Resources"]; ok {
+			resources = r
+		}
+		kids, ok := doc.resolve(node["Kids"]).(pdfArray)
+		if node["Type"] == pdfName("Page") || !ok {
+			page := pdfDict{}
+			for k, v := range node {
+				page[k] = v
+			}
+			page["Resources"] = resources
+			pages = append(pages, page)
+			return
+		}
+		for _, kid := range kids {
+			walk(kid, resources, depth+1)
+		}
+	}
+	if root := doc.dict(doc.trailer["Root"]); root != nil {
+		walk(root["Pages"], nil, 0)
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+	// Without a page tree, the pages in the order of their object numbers
+	nums := make([]int, 0, len(doc.objects))
+	for num := range doc.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	for _, num := range nums {
+		if d := doc.dict(doc.objects[num]); d != nil && d["Type"] == pdfName("Page") {
+			pages = append(pages, d)
+		}
+	}
+	return pages
+}
+
+// contents returns the decoded content streams of the page, concatenated.
+func (doc *pdfDocument) contents(page pdfDict) []byte {
+	var streams pdfArray
+	switch c := doc.resolve(page["Contents"]).(type) {
+	case pdfStream:
+		streams = pdfArray{c}
+	case pdfArray:
+		streams = c
+	}
+	var out []byte
+	for _, s := range streams {
+		stream, ok := doc.resolve(s).(pdfStream)
+		if !ok {
+			continue
+		}
+		data, err := doc.decode(stream)
+		if err != nil {
+			continue
+		}
+		out = append(out, data...)
+		out = append(out, '\n')
+	}
+	return out
+}
@@ -0,0 +1,743 @@
+package extract
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// pdfText returns the text of the pages as Markdown. The lines set in a font larger than the body text are headings,
+// the largest size being a level 1 heading; the lines are joined into paragraphs, and the lines starting with a bullet
+// are list items. The page numbers are dropped.
+// The text is taken in the order of the content streams, which is the reading order of most documents.
+// A malformed document whose reading panics gives an error, so that it does not stop the processing of the others.
+func pdfText(filename string, data []byte) (text string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			text, err = "", fmt.Errorf("malformed document: %v", r)
+		}
+	}()
+	return readPDF(data)
+}
+
+// readPDF returns the text of the document as Markdown (see pdfText).
+func readPDF(data []byte) (string, error) {
+	doc, err := loadPDF(data)
+	if err != nil {
+		return "", err
+	}
+	pages := doc.pages()
+	if len(pages) == 0 {
+		return "", fmt.Errorf("the document has no page")
+	}
+	var lines []pdfLine
+	for i, page := range pages {
+		r := pdfReader{doc: doc, fonts: make(map[pdfRef]*pdfFont), forms: make(map[int]bool)}
+		r.run(doc.contents(page), page["Resources"], identity, 0)
+		for _, line := range r.lines() {
+			line.page = i
+			lines = append(lines, line)
+		}
+	}
+	return layout(lines), nil
+}
+
+// matrix is a transformation matrix [a b c d e f].
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+// mul returns the product m × n.
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+func translation(x, y float64) matrix {
+	return matrix{1, 0, 0, 1, x, y}
+}
+
+// pdfFont decodes the strings shown with a font.
+type pdfFont struct {
+	codeBytes    int               // the length of the character codes
+	toUnicode    map[uint32]string // the text of the codes, from the ToUnicode CMap
+	encoding     *[256]string      // the text of the codes of a simple font
+	widths       map[uint32]float64
+	defaultWidth float64 // in thousandths of the font size
+}
+
+// glyph is a character code of a string and its text.
+type glyph struct {
+	code uint32
+	text string
+}
+
+func (f *pdfFont) glyphs(s pdfString) []glyph {
+	var glyphs []glyph
+	for i := 0; i+f.codeBytes <= len(s); i += f.codeBytes {
+		var code uint32
+		for j := 0; j < f.codeBytes; j++ {
+			code = code<<8 | uint32(s[i+j])
+		}
+		text, ok := f.toUnicode[code]
+		if !ok && f.encoding != nil && code < 256 {
+			text = f.encoding[code]
+		}
+		glyphs = append(glyphs, glyph{code: code, text: text})
+	}
+	return glyphs
+}
+
+func (f *pdfFont) width(code uint32) float64 {
+	if w, ok := f.widths[code]; ok {
+		return w
+	}
+	return f.defaultWidth
+}
+
+// font returns the font of the dictionary; the fonts given by reference are read once.
+func (r *pdfReader) font(obj interface{}) *pdfFont {
+	ref, isRef := obj.(pdfRef)
+	if f, ok := r.fonts[ref]; ok && isRef {
+		return f
+	}
+	doc := r.doc
+	dict := doc.dict(obj)
+	f := &pdfFont{codeBytes: 1, defaultWidth: 500, widths: make(map[uint32]float64)}
+	if dict["Subtype"] == pdfName("Type0") {
+		f.codeBytes = 2
+		f.defaultWidth = 1000
+		if descendants, ok := doc.resolve(dict["DescendantFonts"]).(pdfArray); ok && len(descendants) > 0 {
+			cid := doc.dict(descendants[0])
+			if dw, ok := doc.resolve(cid["DW"]).(float64); ok {
+				f.defaultWidth = dw
+			}
+			f.cidWidths(doc.resolve(cid["W"]))
+		}
+	} else {
+		f.encoding = r.encoding(doc.resolve(dict["Encoding"]))
+		first, _ := doc.resolve(dict["FirstChar"]).(float64)
+		if widths, ok := doc.resolve(dict["Widths"]).(pdfArray); ok {
+			for i, w := range widths {
+				if w, ok := doc.resolve(w).(float64); ok {
+					f.widths[uint32(int(first)+i)] = w
+				}
+			}
+		}
+		if descriptor := doc.dict(dict["FontDescriptor"]); descriptor != nil {
+			if w, ok := doc.resolve(descriptor["MissingWidth"]).(float64); ok && w > 0 {
+				f.defaultWidth = w
+			}
+		}
+	}
+	if s, ok := doc.resolve(dict["ToUnicode"]).(pdfStream); ok {
+		if data, err := doc.decode(s); err == nil {
+			f.toUnicode, f.codeBytes = parseCMap(data, f.codeBytes)
+		}
+	}
+	if isRef {
+		r.fonts[ref] = f
+	}
+	return f
+}
+
+// cidWidths reads the W array of a CID font: c [w1 w2 ...] or cfirst clast w.
+func (f *pdfFont) cidWidths(w interface{}) {
+	array, ok := w.(pdfArray)
+	if !ok {
+		return
+	}
+	for i := 0; i < len(array); {
+		first, ok := array[i].(float64)
+		if !ok || i+1 >= len(array) {
+			return
+		}
+		if widths, ok := array[i+1].(pdfArray); ok {
+			for j, w := range widths {
+				if w, ok := w.(float64); ok {
+					f.widths[uint32(first)+uint32(j)] = w
+				}
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(array) {
+			return
+		}
+		last, ok1 := array[i+1].(float64)
+		width, ok2 := array[i+2].(float64)
+		if !ok1 || !ok2 || last-first > 65535 {
+			return
+		}
+		for c := first; c <= last; c++ {
+			f.widths[uint32(c)] = width
+		}
+		i += 3
+	}
+}
+
+// encoding returns the text of the codes of a simple font: the standard Windows encoding, modified by the differences.
+func (r *pdfReader) encoding(obj interface{}) *[256]string {
+	enc := winAnsi
+	dict, ok := obj.(pdfDict)
+	if !ok {
+		return &enc
+	}
+	differences, _ := r.doc.resolve(dict["Differences"]).(pdfArray)
+	code := 0
+	for _, d := range differences {
+		switch d := d.(type) {
+		case float64:
+			code = int(d)
+		case pdfName:
+			if code >= 0 && code < 256 {
+				enc[code] = glyphText(string(d))
+			}
+			code++
+		}
+	}
+	return &enc
+}
+
+// winAnsi is the text of the codes of the WinAnsiEncoding, used as well for the other standard encodings.
+var winAnsi = func() [256]string {
+	var enc [256]string
+	for c := 32; c < 256; c++ {
+		if c < 127 || c >= 160 {
+			enc[c] = string(rune(c))
+		}
+	}
+	for c, r := range map[int]rune{
+		0x80: '€', 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡', 0x88: 'ˆ', 0x89: '‰', 0x8A: 'Š',
+		0x8B: '‹', 0x8C: 'Œ', 0x8E: 'Ž', 0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—',
+		0x98: '˜', 0x99: '™', 0x9A: 'š', 0x9B: '›', 0x9C: 'œ', 0x9E: 'ž', 0x9F: 'Ÿ', 0xA0: ' ', 0xAD: '-',
+	} {
+		enc[c] = string(r)
+	}
+	return enc
+}()
+
+// glyphNames are the text of the usual glyph names that are not a single character.
+var glyphNames = map[string]string{
+	"space": " ", "exclam": "!", "quotedbl": "\"", "numbersign": "#", "dollar": "$", "percent": "%", "ampersand": "&",
+	"quotesingle": "'", "quoteright": "’", "quoteleft": "‘", "parenleft": "(", "parenright": ")", "asterisk": "*",
+	"plus": "+", "comma": ",", "hyphen": "-", "period": ".", "slash": "/", "colon": ":", "semicolon": ";", "less": "<",
+	"equal": "=", "greater": ">", "question": "?", "at": "@", "bracketleft": "[", "backslash": "\\", "bracketright": "]",
+	"asciicircum": "^", "underscore": "_", "grave": "`", "braceleft": "{", "bar": "|", "braceright": "}",
+	"asciitilde": "~", "bullet": "•", "endash": "–", "emdash": "—", "quotedblleft": "“", "quotedblright": "”",
+	"quotesinglbase": "‚", "quotedblbase": "„", "ellipsis": "…", "dagger": "†", "daggerdbl": "‡", "trademark": "™",
+	"copyright": "©", "registered": "®", "degree": "°", "section": "§", "paragraph": "¶", "periodcentered": "·",
+	"guillemotleft": "«", "guillemotright": "»", "guilsinglleft": "‹", "guilsinglright": "›", "exclamdown": "¡",
+	"questiondown": "¿", "cent": "¢", "sterling": "£", "yen": "¥", "Euro": "€", "fi": "fi", "fl": "fl", "ff": "ff",
+	"ffi": "ffi", "ffl": "ffl", "minus": "−", "multiply": "×", "divide": "÷", "nbspace": " ", "germandbls": "ß",
+	"ae": "æ", "AE": "Æ", "oe": "œ", "OE": "Œ", "oslash": "ø", "Oslash": "Ø", "dotlessi": "ı", "onehalf": "½",
+	"onequarter": "¼", "threequarters": "¾", "mu": "µ", "plusminus": "±", "logicalnot": "¬", "ordfeminine": "ª",
+	"ordmasculine": "º", "zero": "0", "one": "1", "two": "2", "three": "3", "four": "4", "five": "5", "six": "6",
+	"seven": "7", "eight": "8", "nine": "9",
+}
+
+// accents give the accented letters of the glyph names made of a letter and an accent, such as eacute.
+var accents = map[string][2]string{
+	"grave":      {"aeiouAEIOU", "àèìòùÀÈÌÒÙ"},
+	"acute":      {"aeiouyAEIOUY", "áéíóúýÁÉÍÓÚÝ"},
+	"circumflex": {"aeiouAEIOU", "âêîôûÂÊÎÔÛ"},
+	"dieresis":   {"aeiouyAEIOUY", "äëïöüÿÄËÏÖÜŸ"},
+	"tilde":      {"anoANO", "ãñõÃÑÕ"},
+	"ring":       {"aA", "åÅ"},
+	"cedilla":    {"cC", "çÇ"},
+	"caron":      {"szSZ", "šžŠŽ"},
+}
+
+// glyphText returns the text of a glyph name.
+func glyphText(name string) string {
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		// A variant, such as a.sc
+		name = name[:i]
+	}
+	if utf8.RuneCountInString(name) == 1 {
+		return name
+	}
+	if text, ok := glyphNames[name]; ok {
+		return text
+	}
+	if hexa, ok := strings.CutPrefix(name, "uni"); ok && len(hexa) >= 4 {
+		// uniXXXX, or uniXXXXYYYY for a ligature: the first character is enough
+		if v, err := strconv.ParseUint(hexa[:4], 16, 32); err == nil {
+			return string(rune(v))
+		}
+	}
+	if hexa, ok := strings.CutPrefix(name, "u"); ok && len(hexa) >= 4 && len(hexa) <= 6 {
+		if v, err := strconv.ParseUint(hexa, 16, 32); err == nil {
+			return string(rune(v))
+		}
+	}
+	if len(name) > 1 {
+		if a, ok := accents[name[1:]]; ok {
+			if i := strings.IndexByte(a[0], name[0]); i >= 0 {
+				return string([]rune(a[1])[i])
+			}
+		}
+	}
+	return ""
+}
+
+// parseCMap reads the codespace and the mappings of a ToUnicode CMap; codeBytes is the default length of the codes.
+func parseCMap(data []byte, codeBytes int) (map[uint32]string, int) {
+	mappings := make(map[uint32]string)
+	l := &pdfLexer{data: data}
+	code := func(s pdfString) uint32 {
+		var c uint32
+		for i := 0; i < len(s); i++ {
+			c = c<<8 | uint32(s[i])
+		}
+		return c
+	}
+	var operands []interface{}
+	for {
+		obj, ok := l.object()
+		if !ok {
+			return mappings, codeBytes
+		}
+		keyword, ok := obj.(pdfKeyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+		switch keyword {
+		case "endcodespacerange":
+			if len(operands) > 0 {
+				if s, ok := operands[0].(pdfString); ok && len(s) > 0 {
+					codeBytes = len(s)
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(pdfString)
+				dst, ok2 := operands[i+1].(pdfString)
+				if ok1 && ok2 {
+					mappings[code(src)] = utf16Text(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				if !ok1 || !ok2 || code(hi) < code(lo) || code(hi)-code(lo) > 65535 {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case pdfString:
+					// The last character of the destination is incremented
+					runes := []rune(utf16Text(dst))
+					if len(runes) == 0 {
+						continue
+					}
+					for c := code(lo); c <= code(hi); c++ {
+						mappings[c] = string(runes)
+						runes[len(runes)-1]++
+					}
+				case pdfArray:
+					for j, d := range dst {
+						if s, ok := d.(pdfString); ok {
+							mappings[code(lo)+uint32(j)] = utf16Text(s)
+						}
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+// utf16Text decodes UTF-16BE text.
+func utf16Text(s pdfString) string {
+	units := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// pdfFragment is a string shown on a page, in user space.
+type pdfFragment struct {
+	x, y, endX, size float64
+	text             string
+}
+
+// textState is the part of the graphics state about text.
+type textState struct {
+	font                                    *pdfFont
+	size, charSpace, wordSpace, scale, lead float64
+	rise                                    float64
+}
+
+type graphicsState struct {
+	ctm  matrix
+	text textState
+}
+
+// pdfReader interprets the content streams of a page.
+type pdfReader struct {
+	doc       *pdfDocument
+	fonts     map[pdfRef]*pdfFont
+	fragments []pdfFragment
+	forms     map[int]bool // the form XObjects being interpreted, by object number
+	drawn     int          // the number of form XObjects interpreted, bounded by maxForms
+}
+
+// maxForms is the largest number of form XObjects interpreted for a page, so that forms drawing each other many times
+// cannot multiply the work.
+const maxForms = 1000
+
+// run interprets a content stream with its resources; ctm is the initial transformation.
+func (r *pdfReader) run(content []byte, resources interface{}, ctm matrix, depth int) {
+	if depth > 8 {
+		return
+	}
+	res := r.doc.dict(resources)
+	gs := graphicsState{ctm: ctm, text: textState{scale: 1}}
+	var stack []graphicsState
+	var tm, tlm matrix
+	var operands []interface{}
+	number := func(i int) float64 {
+		if i < len(operands) {
+			if n, ok := operands[i].(float64); ok {
+				return n
+			}
+		}
+		return 0
+	}
+	l := &pdfLexer{data: content}
+	for {
+		obj, ok := l.object()
+		if !ok {
+			return
+		}
+		op, ok := obj.(pdfKeyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+		switch op {
+		case "q":
+			stack = append(stack, gs)
+		case "Q":
+			if len(stack) > 0 {
+				gs = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+		case "cm":
+			gs.ctm = matrix{number(0), number(1), number(2), number(3), number(4), number(5)}.mul(gs.ctm)
+		case "BT":
+			tm, tlm = identity, identity
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[0].(pdfName); ok {
+					if fonts := r.doc.dict(res["Font"]); fonts != nil {
+						gs.text.font = r.font(fonts[name])
+					}
+				}
+				gs.text.size = number(1)
+			}
+		case "Tc":
+			gs.text.charSpace = number(0)
+		case "Tw":
+			gs.text.wordSpace = number(0)
+		case "Tz":
+			gs.text.scale = number(0) / 100
+		case "TL":
+			gs.text.lead = number(0)
+		case "Ts":
+			gs.text.rise = number(0)
+		case "Td", "TD":
+			if op == "TD" {
+				gs.text.lead = -number(1)
+			}
+			tlm = translation(number(0), number(1)).mul(tlm)
+			tm = tlm
+		case "Tm":
+			tlm = matrix{number(0), number(1), number(2), number(3), number(4), number(5)}
+			tm = tlm
+		case "T*":
+			tlm = translation(0, -gs.text.lead).mul(tlm)
+			tm = tlm
+		case "Tj", "'", "\"":
+			if op != "Tj" {
+				if op == "\"" && len(operands) == 3 {
+					gs.text.wordSpace, gs.text.charSpace = number(0), number(1)
+				}
+				tlm = translation(0, -gs.text.lead).mul(tlm)
+				tm = tlm
+			}
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].(pdfString); ok {
+					r.show(&tm, gs, s)
+				}
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				array, _ := operands[0].(pdfArray)
+				for _, e := range array {
+					switch e := e.(type) {
+					case pdfString:
+						r.show(&tm, gs, e)
+					case float64:
+						tm = translation(-e/1000*gs.text.size*gs.text.scale, 0).mul(tm)
+					}
+				}
+			}
+		case "Do":
+			if len(operands) > 0 {
+				if name, ok := operands[0].(pdfName); ok {
+					r.form(res, name, gs.ctm, depth)
+				}
+			}
+		case "BI":
+			// An inline image: its data ends with EI
+			if i := inlineImageEnd.FindIndex(content[l.pos:]); i != nil {
+				l.pos += i[1]
+			} else {
+				return
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+// form interprets a form XObject of the resources, unless it is already being interpreted.
+func (r *pdfReader) form(res pdfDict, name pdfName, ctm matrix, depth int) {
+	xobjects := r.doc.dict(res["XObject"])
+	s, ok := r.doc.resolve(xobjects[name]).(pdfStream)
+	if !ok || s.dict["Subtype"] != pdfName("Form") {
+		return
+	}
+	if r.drawn >= maxForms {
+		return
+	}
+	r.drawn++
+	if ref, ok := xobjects[name].(pdfRef); ok {
+		// A form drawing itself, directly or not, is not interpreted again
+		if r.forms[ref.num] {
+			return
+		}
+		r.forms[ref.num] = true
+		defer delete(r.forms, ref.num)
+	}
+	data, err := r.doc.decode(s)
+	if err != nil {
+		return
+	}
+	m := identity
+	if array, ok := r.doc.resolve(s.dict["Matrix"]).(pdfArray); ok && len(array) == 6 {
+		for i, v := range array {
+			m[i], _ = v.(float64)
+		}
+	}
+	resources := s.dict["Resources"]
+	if resources == nil {
+		resources = res
+	}
+	r.run(data, resources, m.mul(ctm), depth+1)
+}
+
+// show records the string shown at the text matrix, and moves the text matrix after it.
+func (r *pdfReader) show(tm *matrix, gs graphicsState, s pdfString) {
+	t := gs.text
+	if t.font == nil {
+		t.font = &pdfFont{codeBytes: 1, encoding: &winAnsi, defaultWidth: 500}
+	}
+	render := func() matrix {
+		return matrix{t.size * t.scale, 0, 0, t.size, 0, t.rise}.mul(*tm).mul(gs.ctm)
+	}
+	start := render()
+	var sb strings.Builder
+	for _, g := range t.font.glyphs(s) {
+		sb.WriteString(g.text)
+		advance := t.font.width(g.code)/1000*t.size + t.charSpace
+		if t.font.codeBytes == 1 && g.code == 32 {
+			advance += t.wordSpace
+		}
+		*tm = translation(advance*t.scale, 0).mul(*tm)
+	}
+	end := render()
+	size := math.Hypot(start[2], start[3])
+	if sb.Len() == 0 || size == 0 {
+		return
+	}
+	r.fragments = append(r.fragments, pdfFragment{x: start[4], y: start[5], endX: end[4], size: size, text: sb.String()})
+}
+
+// pdfLine is a line of text of a page.
+type pdfLine struct {
+	text    string
+	size, y float64
+	page    int
+}
+
+// lines groups the fragments into lines: a fragment starts a new line when it is not on the baseline of the previous one.
+// A space separates the fragments that do not touch.
+func (r *pdfReader) lines() []pdfLine {
+	var lines []pdfLine
+	var current *pdfLine
+	var endX float64
+	for _, f := range r.fragments {
+		if current == nil || math.Abs(f.y-current.y) > 0.5*math.Max(f.size, current.size) {
+			lines = append(lines, pdfLine{y: f.y})
+			current = &lines[len(lines)-1]
+		} else if gap := f.x - endX; (gap > 0.15*f.size || gap < -f.size) &&
+			!strings.HasSuffix(current.text, " ") && !strings.HasPrefix(f.text, " ") {
+			current.text += " "
+		}
+		current.text += f.text
+		if strings.TrimSpace(f.text) != "" {
+			current.size = math.Max(current.size, f.size)
+		}
+		endX = f.endX
+	}
+	for i := range lines {
+		// The dot leaders of the tables of contents are dropped
+		lines[i].text = strings.Join(strings.Fields(leader.ReplaceAllString(lines[i].text, " ")), " ")
+	}
+	return lines
+}
+
+var (
+	inlineImageEnd = regexp.MustCompile(`\sEI\s`)
+	pageNumber     = regexp.MustCompile(`(?i)^((page\s*)?\d+(\s*(/|of)\s*\d+)?|[ivxlc]+)$`)
+	leader         = regexp.MustCompile(`(\s*[.:·]){4,}\s*`)
+	bullets        = []string{"•", "◦", "▪", "‣", "·", "○", "■", "□", "\uf0b7", "–", "- ", "* "}
+)
+
+// layout returns the Markdown of the lines of the document.
+func layout(lines []pdfLine) string {
+	levels := headingLevels(lines)
+	var c converter
+	var paragraph strings.Builder
+	item := false
+	flush := func() {
+		if paragraph.Len() > 0 {
+			c.emit(paragraph.String(), item)
+		}
+		paragraph.Reset()
+		item = false
+	}
+	var previous *pdfLine
+	var previousLevel int
+	for i := range lines {
+		line := &lines[i]
+		if line.text == "" || pageNumber.MatchString(line.text) {
+			continue
+		}
+		level := levels[round(line.size)]
+		if len(line.text) > 200 {
+			level = 0
+		}
+		switch {
+		case level > 0:
+			if previous != nil && previousLevel == level && previous.page == line.page && paragraph.Len() > 0 {
+				// A heading on several lines
+				paragraph.WriteString(" " + line.text)
+				break
+			}
+			flush()
+			paragraph.WriteString(strings.Repeat("#", level) + " " + line.text)
+		case isBullet(line.text):
+			flush()
+			paragraph.WriteString("- " + strings.TrimSpace(trimBullet(line.text)))
+			item = true
+		default:
+			newParagraph := previous == nil || previousLevel > 0 ||
+				(previous.page == line.page && previous.y-line.y > 1.8*line.size) ||
+				(previous.page != line.page && strings.ContainsAny(lastRune(previous.text), ".!?:"))
+			if newParagraph {
+				flush()
+				paragraph.WriteString(line.text)
+				break
+			}
+			text := paragraph.String()
+			runes := []rune(text)
+			if n := len(runes); n > 1 && runes[n-1] == '-' && unicode.IsLetter(runes[n-2]) && unicode.IsLower(firstRune(line.text)) {
+				// A hyphenated word
+				paragraph.Reset()
+				paragraph.WriteString(string(runes[:n-1]) + line.text)
+			} else {
+				paragraph.WriteString(" " + line.text)
+			}
+		}
+		previous, previousLevel = line, level
+	}
+	flush()
+	return c.sb.String()
+}
+
+// headingLevels returns the heading level of the font sizes larger than the size of the body text, which is the size
+// of most characters; the largest size is level 1.
+func headingLevels(lines []pdfLine) map[float64]int {
+	chars := make(map[float64]int)
+	for _, line := range lines {
+		chars[round(line.size)] += utf8.RuneCountInString(line.text)
+	}
+	var body float64
+	for size, n := range chars {
+		if n > chars[body] || (n == chars[body] && size < body) {
+			body = size
+		}
+	}
+	var sizes []float64
+	for size := range chars {
+		if size > body*1.15 {
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(sizes)))
+	levels := make(map[float64]int)
+	for i, size := range sizes {
+		levels[size] = min(i+1, 6)
+	}
+	return levels
+}
+
+// round rounds a font size to the half point.
+func round(size float64) float64 {
+	return math.Round(size*2) / 2
+}
+
+func isBullet(text string) bool {
+	for _, b := range bullets {
+		if strings.HasPrefix(text, b) && len(text) > len(b) {
+			return true
+		}
+	}
+	return false
+}
+
+func trimBullet(text string) string {
+	for _, b := range bullets {
+		if t, ok := strings.CutPrefix(text, b); ok {
+			return t
+		}
+	}
+	return text
+}
+
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+func lastRune(s string) string {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return string(r)
+}
@@ -0,0 +1,197 @@
+package gcputils
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryTransport is an http.RoundTripper retrying the requests failing with a retryable status
+// (429 Too Many Requests and the 5xx errors of an overloaded server). A request that is not idempotent, such as the
+// POST creating a Drive file, may have been processed despite a 5xx error: it is only retried on 429, which rejects it.
+// It honours the Retry-After header and otherwise waits with an exponential backoff and jitter.
+// It also spreads the write requests (any method but GET and HEAD) so that at most WritesPerMinute are sent in a minute,
+// which is how the Google Slides and Drive quotas are expressed.
+type RetryTransport struct {
+	Base            http.RoundTripper // The underlying transport; nil means http.DefaultTransport.
+	MaxRetries      int               // The maximum number of retries of a request.
+	MinBackoff      time.Duration     // The delay before the first retry.
+	MaxBackoff      time.Duration     // The maximum delay between two retries.
+	WritesPerMinute int               // The maximum number of write requests per minute; 0 means no limit.
+
+	window time.Duration // The period of the write quota (a minute, shorter in tests).
+	mu     sync.Mutex
+	writes []time.Time // The dates of the write requests within the window.
+}
+
+// WithRetry returns a copy of the client whose transport retries the failed requests and enforces the write quota.
+//
+// Parameters:
+//   - client: The HTTP client of the Google APIs, as returned by GetClient.
+//   - maxRetries: The maximum number of retries of a request.
+//   - writesPerMinute: The maximum number of write requests per minute; 0 means no limit.
+//
+// Returns:
+//   - *http.Client: The client to pass to the Google API services.
+func WithRetry(client *http.Client, maxRetries, writesPerMinute int) *http.Client {
+	c := *client
+	c.Transport = &RetryTransport{
+		Base:            client.Transport,
+		MaxRetries:      maxRetries,
+		MinBackoff:      time.Second,
+		MaxBackoff:      time.Minute,
+		WritesPerMinute: writesPerMinute,
+	}
+	return &c
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	// The body is sent again on each attempt
+	getBody := req.GetBody
+	if req.Body != nil && req.Body != http.NoBody && getBody == nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		getBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(b)), nil
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		r := req
+		if getBody != nil {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			if err := t.waitForQuota(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := base.RoundTrip(r)
+		if err != nil || !retryable(resp.StatusCode, idempotent(req)) || attempt >= t.MaxRetries {
+			return resp, err
+		}
+
+		delay := retryAfter(resp)
+		if delay < 0 {
+			delay = t.backoff(attempt)
+		}
+		log.Printf("%v %v: %v, retrying in %v (%d/%d)", req.Method, req.URL.Path, resp.Status, delay, attempt+1, t.MaxRetries)
+		// Drain the body so that the connection can be reused
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryable reports whether a request failing with the status may succeed later, and be sent again without
+// applying it twice: the 5xx errors are only retryable for the idempotent requests.
+func retryable(status int, idempotent bool) bool {
+	switch status {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+// idempotent reports whether sending the request twice has the effect of sending it once: its method is idempotent,
+// or it has an Idempotency-Key header, as in net/http.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	_, key := req.Header["Idempotency-Key"]
+	_, xKey := req.Header["X-Idempotency-Key"]
+	return key || xKey
+}
+
+// retryAfter returns the delay requested by the Retry-After header of the response, or -1 if there is none.
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return -1
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d
+		}
+		return 0
+	}
+	return -1
+}
+
+// backoff returns the delay before the retry following the attempt: it doubles at each attempt up to MaxBackoff,
+// and a random jitter of up to half the delay spreads the retries of concurrent clients.
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	d := t.MinBackoff << attempt
+	if d > t.MaxBackoff || d <= 0 {
+		d = t.MaxBackoff
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// waitForQuota blocks until a write request can be sent without exceeding WritesPerMinute.
+func (t *RetryTransport) waitForQuota(ctx context.Context) error {
+	if t.WritesPerMinute <= 0 {
+		return nil
+	}
+	window := t.window
+	if window == 0 {
+		window = time.Minute
+	}
+	for {
+		t.mu.Lock()
+		now := time.Now()
+		for len(t.writes) > 0 && now.Sub(t.writes[0]) >= window {
+			t.writes = t.writes[1:]
+		}
+		if len(t.writes) < t.WritesPerMinute {
+			t.writes = append(t.writes, now)
+			t.mu.Unlock()
+			return nil
+		}
+		delay := t.writes[0].Add(window).Sub(now)
+		t.mu.Unlock()
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep waits for the delay or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,96 @@
+package gcputils
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		key      bool  // whether the request has an Idempotency-Key header
+		statuses []int // the successive answers of the server
+		want     int
+		calls    int
+	}{
+		{"success", http.MethodPost, false, []int{200}, 200, 1},
+		{"quota exceeded", http.MethodPost, false, []int{429, 429, 200}, 200, 3},
+		{"unavailable", http.MethodPut, false, []int{503, 500, 200}, 200, 3},
+		{"unavailable post", http.MethodPost, false, []int{503, 200}, 503, 1},
+		{"unavailable idempotent post", http.MethodPost, true, []int{503, 500, 200}, 200, 3},
+		{"not retryable", http.MethodPut, false, []int{400, 200}, 400, 1},
+		{"too many retries", http.MethodPut, false, []int{503, 503, 503, 503, 200}, 503, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if string(body) != "payload" {
+					t.Errorf("call %d: got body %q", calls, body)
+				}
+				status := tt.statuses[calls]
+				calls++
+				if status == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "0")
+				}
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: &RetryTransport{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}}
+			// A body without GetBody has to be buffered to be sent again
+			req, err := http.NewRequest(tt.method, server.URL, io.NopCloser(strings.NewReader("payload")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.key {
+				req.Header.Set("Idempotency-Key", "create-deck")
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want || calls != tt.calls {
+				t.Errorf("got status %v after %v calls, want %v after %v", resp.StatusCode, calls, tt.want, tt.calls)
+			}
+		})
+	}
+}
+
+func TestWriteQuota(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	window := 100 * time.Millisecond
+	client := &http.Client{Transport: &RetryTransport{WritesPerMinute: 2, window: window}}
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		// The reads are not limited
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed >= window {
+		t.Errorf("reads took %v, want no wait", elapsed)
+	}
+	for i := 0; i < 5; i++ {
+		resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	// 5 writes at 2 per window need two more windows
+	if elapsed := time.Since(start); elapsed < 2*window {
+		t.Errorf("writes took %v, want at least %v", elapsed, 2*window)
+	}
+}
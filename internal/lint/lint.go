@@ -0,0 +1,298 @@
+/*
+Package lint checks a presentation against rules of good slides: the length of the bodies, the number and the depth
+of the bullets, the subtitles, the titles, the chapters, the executive summary and the Markdown left unrendered.
+
+The rules are configured by a YAML or JSON file giving, for each rule, its severity (error, warning or off) and its
+threshold. Example:
+
+	rules:
+	  max-words:
+	    max: 80
+	  empty-subtitle:
+	    severity: off
+	  markdown-leftover:
+	    severity: error
+*/
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// The severities of the rules.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityOff     = "off"
+)
+
+// The rules.
+const (
+	RuleMaxWords         = "max-words"
+	RuleMaxBullets       = "max-bullets"
+	RuleBulletDepth      = "bullet-depth"
+	RuleEmptySubtitle    = "empty-subtitle"
+	RuleDuplicateTitle   = "duplicate-title"
+	RuleEmptyChapter     = "empty-chapter"
+	RuleExecutiveSummary = "executive-summary"
+	RuleMarkdownLeftover = "markdown-leftover"
+)
+
+// Descriptions gives the description of each rule.
+var Descriptions = map[string]string{
+	RuleMaxWords:         "The body of a slide has too many words.",
+	RuleMaxBullets:       "The body of a slide has too many list items.",
+	RuleBulletDepth:      "A list item of a slide is nested too deeply.",
+	RuleEmptySubtitle:    "A content slide has no subtitle.",
+	RuleDuplicateTitle:   "Several slides have the same title.",
+	RuleEmptyChapter:     "A chapter has no content slide.",
+	RuleExecutiveSummary: "The presentation does not start with an executive summary.",
+	RuleMarkdownLeftover: "A slide holds Markdown that is not rendered and would be shown as is.",
+}
+
+// Rule is the configuration of a rule.
+type Rule struct {
+	// Severity is error, warning or off
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// Max is the threshold of the max-words, max-bullets and bullet-depth rules
+	Max int `json:"max,omitempty" yaml:"max,omitempty"`
+	// Keywords are the words of the title of an executive summary, compared regardless of the case
+	Keywords []string `json:"keywords,omitempty" yaml:"keywords,omitempty"`
+}
+
+// Config is the configuration of the rules, indexed by rule.
+type Config struct {
+	Rules map[string]Rule `json:"rules" yaml:"rules"`
+}
+
+// DefaultConfig returns the default configuration of the rules.
+func DefaultConfig() Config {
+	return Config{Rules: map[string]Rule{
+		RuleMaxWords:         {Severity: SeverityWarning, Max: 120},
+		RuleMaxBullets:       {Severity: SeverityWarning, Max: 6},
+		RuleBulletDepth:      {Severity: SeverityWarning, Max: 2},
+		RuleEmptySubtitle:    {Severity: SeverityWarning},
+		RuleDuplicateTitle:   {Severity: SeverityWarning},
+		RuleEmptyChapter:     {Severity: SeverityError},
+		RuleExecutiveSummary: {Severity: SeverityWarning, Keywords: []string{"summary", "overview", "synthèse", "résumé"}},
+		RuleMarkdownLeftover: {Severity: SeverityError},
+	}}
+}
+
+// LoadConfig reads the configuration of the rules from a YAML or JSON file (by its extension); the rules and the
+// fields it does not give keep their default (see DefaultConfig).
+//
+// Parameters:
+//   - filename: The path of the configuration file.
+//
+// Returns:
+//   - Config: The configuration of every rule.
+//   - error: An error if the file cannot be read or decoded, or if it names an unknown rule or severity.
+func LoadConfig(filename string) (Config, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return Config{}, err
+	}
+	var c Config
+	if strings.ToLower(filepath.Ext(filename)) == ".json" {
+		err = json.Unmarshal(b, &c)
+	} else {
+		err = yaml.Unmarshal(b, &c)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("cannot decode the lint configuration %v: %w", filename, err)
+	}
+	config := DefaultConfig()
+	for name, r := range c.Rules {
+		rule, ok := config.Rules[name]
+		if !ok {
+			return Config{}, fmt.Errorf("unknown lint rule %q", name)
+		}
+		switch r.Severity {
+		case "":
+		case SeverityError, SeverityWarning, SeverityOff:
+			rule.Severity = r.Severity
+		default:
+			return Config{}, fmt.Errorf("invalid severity %q of the lint rule %q (expected %v, %v or %v)", r.Severity, name, SeverityError, SeverityWarning, SeverityOff)
+		}
+		if r.Max > 0 {
+			rule.Max = r.Max
+		}
+		if r.Keywords != nil {
+			rule.Keywords = r.Keywords
+		}
+		config.Rules[name] = rule
+	}
+	return config, nil
+}
+
+// Finding is a violation of a rule.
+type Finding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	// Slide is the number of the slide in the deck, the cover being the first one; 0 for the whole presentation
+	Slide   int    `json:"slide,omitempty"`
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message"`
+}
+
+// Lint checks the presentation against the rules of the configuration, and returns the findings by slide.
+//
+// Parameters:
+//   - p: The presentation; its slides follow the cover, the second slide of the deck.
+//   - config: The configuration of the rules; the rules it does not give are not checked.
+//
+// Returns:
+//   - []Finding: The violations of the rules, ordered by slide.
+func Lint(p *structure.Presentation, config Config) []Finding {
+	var findings []Finding
+	report := func(name string, i int, format string, args ...any) {
+		rule, ok := config.Rules[name]
+		if !ok || rule.Severity == SeverityOff || rule.Severity == "" {
+			return
+		}
+		f := Finding{Rule: name, Severity: rule.Severity, Message: fmt.Sprintf(format, args...)}
+		if i >= 0 {
+			f.Slide, f.Title = i+2, p.Slides[i].Title
+		}
+		findings = append(findings, f)
+	}
+
+	titles := make(map[string]int)
+	for i, slide := range p.Slides {
+		for _, leftover := range leftovers(slide) {
+			report(RuleMarkdownLeftover, i, "%v", leftover)
+		}
+		if key := strings.ToLower(strings.TrimSpace(slide.Title)); key != "" {
+			if first, ok := titles[key]; ok {
+				report(RuleDuplicateTitle, i, "the title is also the title of slide %d", first+2)
+			} else {
+				titles[key] = i
+			}
+		}
+		if slide.Chapter {
+			if i+1 == len(p.Slides) || p.Slides[i+1].Chapter {
+				report(RuleEmptyChapter, i, "the chapter is followed by no content slide")
+			}
+			continue
+		}
+		if strings.TrimSpace(slide.Subtitle) == "" {
+			report(RuleEmptySubtitle, i, "the slide has no subtitle")
+		}
+		words, bullets, depth := measure(slide.Body)
+		if limit := config.Rules[RuleMaxWords].Max; limit > 0 && words > limit {
+			report(RuleMaxWords, i, "the body has %d words (at most %d)", words, limit)
+		}
+		if limit := config.Rules[RuleMaxBullets].Max; limit > 0 && bullets > limit {
+			report(RuleMaxBullets, i, "the body has %d list items (at most %d)", bullets, limit)
+		}
+		if limit := config.Rules[RuleBulletDepth].Max; limit > 0 && depth > limit {
+			report(RuleBulletDepth, i, "a list item is nested at level %d (at most %d)", depth, limit)
+		}
+	}
+	if !hasSummary(p, config.Rules[RuleExecutiveSummary].Keywords) {
+		report(RuleExecutiveSummary, -1, "the first content slide is not an executive summary (its title has none of the words %v)", strings.Join(config.Rules[RuleExecutiveSummary].Keywords, ", "))
+	}
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Slide < findings[j].Slide })
+	return findings
+}
+
+// measure returns the number of words of the body, of its list items, and the deepest level of its list items.
+func measure(body string) (int, int, int) {
+	var words, bullets, depth int
+	for _, p := range slidesutils.Parse(body) {
+		for _, r := range p.Runs {
+			words += len(strings.Fields(r.Text))
+		}
+		if p.Level > 0 {
+			bullets++
+			depth = max(depth, p.Level)
+		}
+	}
+	return words, bullets, depth
+}
+
+// hasSummary reports whether the first content slide of the presentation is titled with one of the keywords.
+func hasSummary(p *structure.Presentation, keywords []string) bool {
+	for _, slide := range p.Slides {
+		if slide.Chapter {
+			continue
+		}
+		title := strings.ToLower(slide.Title)
+		for _, k := range keywords {
+			if strings.Contains(title, strings.ToLower(k)) {
+				return true
+			}
+		}
+		return false
+	}
+	return len(p.Slides) == 0
+}
+
+var (
+	// The Markdown blocks not rendered by slidesutils.Parse
+	blockquote     = regexp.MustCompile(`^\s*>`)
+	horizontalRule = regexp.MustCompile(`^\s*(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	tableRow       = regexp.MustCompile(`^\s*\|.*\|\s*$`)
+	// The inline markers left in the runs once parsed, or in a plain text field
+	inlineMarkers = regexp.MustCompile("\\*\\*|__|~~|`|!\\[|\\]\\(|</?[a-zA-Z][a-zA-Z0-9]*[^<>]*>")
+	plainMarkers  = regexp.MustCompile("^\\s*#{1,6}\\s|^\\s*[-*+]\\s")
+)
+
+// leftovers returns the descriptions of the Markdown of the slide that would be shown as is: the blocks and the inline
+// markers of the body that slidesutils.Parse does not render, and any Markdown in the title and the subtitle, which
+// are plain text. The code blocks and the code spans are not checked.
+func leftovers(slide structure.Slide) []string {
+	var found []string
+	for field, text := range map[string]string{"title": slide.Title, "subtitle": slide.Subtitle} {
+		if m := inlineMarkers.FindString(text); m != "" {
+			found = append(found, fmt.Sprintf("the %v holds the Markdown marker %q", field, m))
+		} else if plainMarkers.MatchString(text) {
+			found = append(found, fmt.Sprintf("the %v starts with a Markdown marker", field))
+		}
+	}
+	sort.Strings(found)
+
+	var fence string
+	for _, line := range strings.Split(slide.Body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		case blockquote.MatchString(line):
+			found = append(found, fmt.Sprintf("the body holds a block quote: %q", trimmed))
+		case horizontalRule.MatchString(line):
+			found = append(found, fmt.Sprintf("the body holds a horizontal rule: %q", trimmed))
+		case tableRow.MatchString(line):
+			found = append(found, fmt.Sprintf("the body holds a table row: %q", trimmed))
+		}
+	}
+	for _, p := range slidesutils.Parse(slide.Body) {
+		for _, r := range p.Runs {
+			if r.Code {
+				continue
+			}
+			if m := inlineMarkers.FindString(r.Text); m != "" {
+				found = append(found, fmt.Sprintf("the body holds the Markdown marker %q in %q", m, strings.TrimSpace(r.Text)))
+			}
+		}
+	}
+	return found
+}
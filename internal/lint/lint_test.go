@@ -0,0 +1,128 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+func TestLint(t *testing.T) {
+	summary := structure.Slide{Title: "Executive summary", Subtitle: "In short", Body: "All is well."}
+	tests := []struct {
+		name   string
+		slides []structure.Slide
+		want   []string // rule@slide
+	}{
+		{"clean", []structure.Slide{summary, {Title: "Chapter", Chapter: true}, {Title: "Details", Subtitle: "More", Body: "- one\n  - two"}}, nil},
+		{"no summary", []structure.Slide{{Title: "Details", Subtitle: "More"}}, []string{"executive-summary@0"}},
+		{"too many words", []structure.Slide{summary, {Title: "Long", Subtitle: "s", Body: strings.Repeat("word ", 121)}}, []string{"max-words@3"}},
+		{
+			"bullets",
+			[]structure.Slide{summary, {Title: "List", Subtitle: "s", Body: "- 1\n- 2\n- 3\n- 4\n- 5\n- 6\n- 7\n  - 8\n    - 9"}},
+			[]string{"max-bullets@3", "bullet-depth@3"},
+		},
+		{"empty subtitle", []structure.Slide{summary, {Title: "Details", Body: "text"}}, []string{"empty-subtitle@3"}},
+		{"duplicated title", []structure.Slide{summary, {Title: "executive Summary ", Subtitle: "s"}}, []string{"duplicate-title@3"}},
+		{
+			"empty chapters",
+			[]structure.Slide{summary, {Title: "One", Chapter: true}, {Title: "Two", Chapter: true}, {Title: "Details", Subtitle: "s"}, {Title: "Three", Chapter: true}},
+			[]string{"empty-chapter@3", "empty-chapter@6"},
+		},
+		{
+			"markdown leftovers",
+			[]structure.Slide{summary, {
+				Title:    "**Bold** title",
+				Subtitle: "# Subtitle",
+				Body:     "> quote\n---\n| a | b |\nA **broken marker\n`code **not** checked`\n```\n**in a block**\n```\nRendered **bold** and <u>underline</u>",
+			}},
+			[]string{"markdown-leftover@3", "markdown-leftover@3", "markdown-leftover@3", "markdown-leftover@3", "markdown-leftover@3", "markdown-leftover@3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := Lint(&structure.Presentation{Slides: tt.slides}, DefaultConfig())
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.Rule+"@"+string(rune('0'+f.Slide)))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Lint() = %v, want %v (%+v)", got, tt.want, findings)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+	config, err := LoadConfig(write("lint.yaml", "rules:\n  max-words:\n    max: 10\n  empty-subtitle:\n    severity: off\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := config.Rules[RuleMaxWords]; r.Max != 10 || r.Severity != SeverityWarning {
+		t.Errorf("max-words = %+v, want a warning beyond 10 words", r)
+	}
+	findings := Lint(&structure.Presentation{Slides: []structure.Slide{{Title: "Summary", Body: strings.Repeat("word ", 11)}}}, config)
+	if len(findings) != 1 || findings[0].Rule != RuleMaxWords {
+		t.Errorf("Lint() = %+v, want a single max-words finding", findings)
+	}
+
+	if _, err := LoadConfig(write("lint.json", `{"rules": {"max-slides": {"max": 3}}}`)); err == nil {
+		t.Error("LoadConfig() of an unknown rule succeeded")
+	}
+	if _, err := LoadConfig(write("severity.json", `{"rules": {"max-words": {"severity": "fatal"}}}`)); err == nil {
+		t.Error("LoadConfig() of an unknown severity succeeded")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	findings := []Finding{
+		{Rule: RuleExecutiveSummary, Severity: SeverityWarning, Message: "no summary"},
+		{Rule: RuleMarkdownLeftover, Severity: SeverityError, Slide: 3, Title: "Details", Message: "leftover"},
+	}
+	var text bytes.Buffer
+	if err := Write(&text, FormatText, findings, "plan.json"); err != nil {
+		t.Fatal(err)
+	}
+	want := "plan.json: warning: no summary [executive-summary]\nplan.json: slide 3 (Details): error: leftover [markdown-leftover]\n"
+	if text.String() != want {
+		t.Errorf("text report = %q, want %q", text.String(), want)
+	}
+
+	var sarifReport bytes.Buffer
+	if err := Write(&sarifReport, FormatSARIF, findings, "plan.json"); err != nil {
+		t.Fatal(err)
+	}
+	var log sarif
+	if err := json.Unmarshal(sarifReport.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if len(log.Runs) != 1 || len(log.Runs[0].Results) != 2 || len(log.Runs[0].Tool.Driver.Rules) != len(Descriptions) {
+		t.Fatalf("invalid SARIF log %v", sarifReport.String())
+	}
+	if r := log.Runs[0].Results[1]; r.Level != "error" || r.Locations[0].PhysicalLocation.ArtifactLocation.URI != "plan.json" || r.Locations[0].LogicalLocations[0].Name != "slide 3: Details" {
+		t.Errorf("invalid SARIF result %+v", r)
+	}
+
+	var empty bytes.Buffer
+	if err := Write(&empty, FormatJSON, nil, "plan.json"); err != nil || strings.TrimSpace(empty.String()) != "[]" {
+		t.Errorf("JSON report of no finding = %q, %v", empty.String(), err)
+	}
+	if !Failed(findings) || Failed(findings[:1]) {
+		t.Error("Failed() does not report the errors")
+	}
+	if err := Write(&empty, "xml", findings, "plan.json"); err == nil {
+		t.Error("Write() in an unknown format succeeded")
+	}
+}
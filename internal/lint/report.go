@@ -0,0 +1,134 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// The formats of the reports.
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+// Write writes the report of the findings in the format.
+//
+// Parameters:
+//   - w: The destination of the report.
+//   - format: FormatText (a line per finding), FormatJSON (an array of findings) or FormatSARIF (a SARIF 2.1.0 log).
+//   - findings: The findings of Lint.
+//   - source: The plan file or the presentation linted, the location of the findings.
+//
+// Returns:
+//   - error: An error if the format is unknown or if the report cannot be written.
+func Write(w io.Writer, format string, findings []Finding, source string) error {
+	switch format {
+	case FormatText:
+		for _, f := range findings {
+			location := source
+			if f.Slide > 0 {
+				location = fmt.Sprintf("%v: slide %d (%v)", source, f.Slide, f.Title)
+			}
+			if _, err := fmt.Fprintf(w, "%v: %v: %v [%v]\n", location, f.Severity, f.Message, f.Rule); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatJSON:
+		if findings == nil {
+			findings = []Finding{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", " ")
+		return enc.Encode(findings)
+	case FormatSARIF:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", " ")
+		return enc.Encode(sarifLog(findings, source))
+	}
+	return fmt.Errorf("unknown lint report format %q (expected %v, %v or %v)", format, FormatText, FormatJSON, FormatSARIF)
+}
+
+// Failed reports whether one of the findings is an error.
+func Failed(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// The subset of the SARIF 2.1.0 format used by the reports.
+type (
+	sarif struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+		LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifLogicalLocation struct {
+		Name string `json:"name"`
+		Kind string `json:"kind"`
+	}
+)
+
+// sarifLog returns the SARIF log of the findings; the rules of the tool are all the rules, and each result is located
+// in the source and, for a slide, at the slide.
+func sarifLog(findings []Finding, source string) sarif {
+	driver := sarifDriver{Name: "gptslideshow lint", InformationURI: "https://github.com/owulveryck/gptslideshow"}
+	for id, description := range Descriptions {
+		driver.Rules = append(driver.Rules, sarifRule{ID: id, ShortDescription: sarifMessage{Text: description}})
+	}
+	sort.Slice(driver.Rules, func(i, j int) bool { return driver.Rules[i].ID < driver.Rules[j].ID })
+	results := []sarifResult{}
+	for _, f := range findings {
+		location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: source}}}
+		if f.Slide > 0 {
+			location.LogicalLocations = []sarifLogicalLocation{{Name: fmt.Sprintf("slide %d: %v", f.Slide, f.Title), Kind: "slide"}}
+		}
+		results = append(results, sarifResult{RuleID: f.Rule, Level: f.Severity, Message: sarifMessage{Text: f.Message}, Locations: []sarifLocation{location}})
+	}
+	return sarif{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+}
@@ -0,0 +1,423 @@
+/*
+Package marp converts a structure.Presentation to and from Marp compatible Markdown.
+
+The presentation title and subtitle are stored in the front matter (title and description directives),
+the slides are separated by "---" lines, and the kind of slide is given by a class directive:
+
+	---
+	marp: true
+	title: The title
+	description: The subtitle
+	---
+
+	<!-- _class: lead -->
+
+	# The title
+
+	## The subtitle
+
+	---
+
+	<!-- _class: chapter -->
+
+	# A chapter
+
+	The description of the chapter
+
+	---
+
+	# A slide
+
+	## Its subtitle
+
+	The body of the slide
+
+	<!--
+	The speaker notes
+	-->
+
+A code slide (class code) ends with its fenced code block:
+
+	---
+
+	<!-- _class: code -->
+
+	# A code slide
+
+	```go
+	fmt.Println("Hello")
+	```
+
+A table slide (class table) ends with its table, as a pipe table:
+
+	---
+
+	<!-- _class: table -->
+
+	# A table slide
+
+	| Name | Description |
+	| --- | --- |
+	| Go | A programming language |
+
+A chart slide (class chart-bar, chart-line or chart-pie) ends with its data, as a pipe table whose first column
+holds the labels and each other column a series:
+
+	---
+
+	<!-- _class: chart-bar -->
+
+	# A chart slide
+
+	|  | Revenue | Costs |
+	| --- | --- | --- |
+	| Q1 | 1200 | 800 |
+	| Q2 | 1850 | 900 |
+
+The title and the subtitle are the level 1 and level 2 headings at the top of the slide; a slide without title has no
+level 1 heading. A body starting with a heading is preceded by an empty comment, which ends the headings of the slide:
+
+	---
+
+	# A slide without subtitle
+
+	<!-- -->
+
+	## The first heading of the body
+
+The speaker notes are written as an HTML comment at the end of the slide, which Marp shows as presenter notes;
+their "-->" are written "--\>" (and their "--\>" are written "--\\>", and so on) so that they do not end the comment.
+A "---" line only separates the slides outside the fenced code blocks and the comments; a horizontal rule "---" of a
+body is written "- - -", which Marp does not take for a separator, and read back as "---".
+The cover slide (class lead) is generated for the preview and ignored by the importer.
+*/
+package marp
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/owulveryck/gptslideshow/internal/chart"
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// The classes of the slides.
+const (
+	classCover   = "lead"
+	classChapter = "chapter"
+	classCode    = "code"
+	classTable   = "table"
+	// classChart is followed by the kind of the chart, such as chart-bar.
+	classChart = "chart"
+)
+
+const separator = "---"
+
+// escapedRule is the horizontal rule of a body, written so that it is not a separator.
+const escapedRule = "- - -"
+
+// headingsEnd is the empty comment ending the headings of a slide whose body starts with a heading.
+const headingsEnd = "<!-- -->"
+
+// frontMatter holds the global directives of the document.
+type frontMatter struct {
+	Marp        bool   `yaml:"marp"`
+	Title       string `yaml:"title,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
+var classDirective = regexp.MustCompile(`^<!--\s*_class:\s*([\w-]+)\s*-->$`)
+
+// The terminators of the comments in the notes, escaped with one more backslash than they have.
+var (
+	commentEnd        = regexp.MustCompile(`--(\\*)>`)
+	escapedCommentEnd = regexp.MustCompile(`--\\(\\*)>`)
+)
+
+// Export writes the presentation as Marp Markdown.
+func Export(w io.Writer, p *structure.Presentation) error {
+	fm, err := yaml.Marshal(frontMatter{Marp: true, Title: p.Title, Description: p.Subtitle})
+	if err != nil {
+		return fmt.Errorf("cannot encode front matter: %w", err)
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n%s%s\n", separator, fm, separator)
+
+	fmt.Fprintf(&buf, "\n<!-- _class: %s -->\n", classCover)
+	if p.Title != "" {
+		fmt.Fprintf(&buf, "\n# %s\n", p.Title)
+	}
+	if p.Subtitle != "" {
+		fmt.Fprintf(&buf, "\n## %s\n", p.Subtitle)
+	}
+	for _, slide := range p.Slides {
+		fmt.Fprintf(&buf, "\n%s\n\n", separator)
+		switch {
+		case slide.Chapter:
+			fmt.Fprintf(&buf, "<!-- _class: %s -->\n\n", classChapter)
+		case !slide.Chart.Empty():
+			fmt.Fprintf(&buf, "<!-- _class: %s-%s -->\n\n", classChart, chartKind(slide.Chart))
+		case !slide.Table.Empty():
+			fmt.Fprintf(&buf, "<!-- _class: %s -->\n\n", classTable)
+		case slide.Code != "":
+			fmt.Fprintf(&buf, "<!-- _class: %s -->\n\n", classCode)
+		}
+		if title := singleLine(slide.Title); title != "" {
+			fmt.Fprintf(&buf, "# %s\n", title)
+		}
+		if subtitle := singleLine(slide.Subtitle); subtitle != "" {
+			fmt.Fprintf(&buf, "\n## %s\n", subtitle)
+		}
+		if body := strings.TrimSpace(slide.Body); body != "" {
+			if strings.HasPrefix(body, "#") {
+				fmt.Fprintf(&buf, "\n%s\n", headingsEnd)
+			}
+			fmt.Fprintf(&buf, "\n%s\n", replaceRules(strings.Trim(slide.Body, "\n"), separator, escapedRule))
+		}
+		switch {
+		case slide.Chapter:
+		case !slide.Chart.Empty():
+			fmt.Fprintf(&buf, "\n%s", slidesutils.MarkdownTable(chart.ToTable(slide.Chart)))
+		case !slide.Table.Empty():
+			fmt.Fprintf(&buf, "\n%s", slidesutils.MarkdownTable(slide.Table))
+		case slide.Code != "":
+			fmt.Fprintf(&buf, "\n%s\n", fenced(slide.Code))
+		}
+		if strings.TrimSpace(slide.Notes) != "" {
+			// A comment cannot contain its own terminator
+			fmt.Fprintf(&buf, "\n<!--\n%s\n-->\n", commentEnd.ReplaceAllString(strings.Trim(slide.Notes, "\n"), `--\$1>`))
+		}
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// Import reads a presentation from Marp Markdown as written by Export.
+// Within a slide, a level 1 heading at the top is the title, a level 2 heading following it (or at the top)
+// is the subtitle and the remaining lines are the body; an empty comment ends the headings.
+func Import(r io.Reader) (*structure.Presentation, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	fm, slides, err := split(string(b))
+	if err != nil {
+		return nil, err
+	}
+	p := &structure.Presentation{
+		Title:    fm.Title,
+		Subtitle: fm.Description,
+	}
+	for _, content := range slides {
+		slide, class := parseSlide(content)
+		if class == classCover {
+			continue
+		}
+		if slide.Title == "" && slide.Subtitle == "" && slide.Body == "" && slide.Code == "" && slide.Table.Empty() && slide.Chart.Empty() {
+			continue
+		}
+		p.Slides = append(p.Slides, slide)
+	}
+	return p, nil
+}
+
+// split separates the front matter from the slides; the separators within a fenced code block or a comment are
+// lines of the slide.
+func split(doc string) (frontMatter, []string, error) {
+	var fm frontMatter
+	var slides []string
+	var current []string
+	inFrontMatter := false
+	var fence string
+	inComment := false
+	scanner := bufio.NewScanner(strings.NewReader(doc))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	first := true
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case first && line == separator:
+			inFrontMatter = true
+		case inFrontMatter && line == separator:
+			if err := yaml.Unmarshal([]byte(strings.Join(current, "\n")), &fm); err != nil {
+				return fm, nil, fmt.Errorf("cannot decode front matter: %w", err)
+			}
+			inFrontMatter = false
+			current = nil
+		case !inFrontMatter && fence == "" && !inComment && line == separator:
+			slides = append(slides, strings.Join(current, "\n"))
+			current = nil
+		default:
+			current = append(current, line)
+			switch {
+			case inFrontMatter:
+			case inComment:
+				inComment = !strings.Contains(trimmed, "-->")
+			case fence != "":
+				if strings.HasPrefix(trimmed, fence) {
+					fence = ""
+				}
+			case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+				fence = trimmed[:3]
+			case strings.HasPrefix(trimmed, "<!--"):
+				inComment = !strings.Contains(trimmed, "-->")
+			}
+		}
+		first = false
+	}
+	if err := scanner.Err(); err != nil {
+		return fm, nil, err
+	}
+	if inFrontMatter {
+		return fm, nil, fmt.Errorf("unterminated front matter")
+	}
+	slides = append(slides, strings.Join(current, "\n"))
+	return fm, slides, nil
+}
+
+// replaceRules replaces the lines of the body equal to the rule old by the rule new, outside the fenced code blocks.
+func replaceRules(body, old, new string) string {
+	lines := strings.Split(body, "\n")
+	var fence string
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		case strings.TrimRight(line, " \t") == old:
+			lines[i] = new
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseSlide returns the slide and its class.
+// The HTML comments other than the class directive are the speaker notes.
+func parseSlide(content string) (structure.Slide, string) {
+	var slide structure.Slide
+	var class string
+	var body, notes []string
+	inComment := false
+	headings := true
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if m := classDirective.FindStringSubmatch(trimmed); m != nil {
+			class = m[1]
+			continue
+		}
+		if !inComment && trimmed == headingsEnd {
+			headings = false
+			continue
+		}
+		if !inComment && strings.HasPrefix(trimmed, "<!--") {
+			inComment = true
+			trimmed = strings.TrimPrefix(trimmed, "<!--")
+		}
+		if inComment {
+			if strings.HasSuffix(trimmed, "-->") {
+				inComment = false
+				trimmed = strings.TrimSuffix(trimmed, "-->")
+			}
+			if trimmed = strings.TrimSpace(trimmed); trimmed != "" || len(notes) > 0 {
+				notes = append(notes, trimmed)
+			}
+			continue
+		}
+		switch {
+		case headings && slide.Title == "" && slide.Subtitle == "" && strings.HasPrefix(trimmed, "# "):
+			slide.Title = strings.TrimSpace(strings.TrimPrefix(trimmed, "# "))
+			continue
+		case headings && slide.Subtitle == "" && strings.HasPrefix(trimmed, "## "):
+			slide.Subtitle = strings.TrimSpace(strings.TrimPrefix(trimmed, "## "))
+			continue
+		}
+		if len(body) == 0 && trimmed == "" {
+			continue
+		}
+		headings = false
+		body = append(body, line)
+	}
+	switch class {
+	case classCode:
+		body, slide.Code = splitCode(body)
+	case classTable:
+		body, slide.Table = splitTable(body)
+	default:
+		if kind, ok := strings.CutPrefix(class, classChart+"-"); ok {
+			body, slide.Table = splitTable(body)
+			// The data that is not a chart remains a table
+			if c, ok := chart.FromTable(slide.Table); ok {
+				c.Kind = kind
+				slide.Chart, slide.Table = c, structure.Table{}
+			}
+		}
+	}
+	slide.Body = replaceRules(strings.TrimRight(strings.Join(body, "\n"), "\n"), escapedRule, separator)
+	slide.Notes = escapedCommentEnd.ReplaceAllString(strings.TrimRight(strings.Join(notes, "\n"), "\n"), "--$1>")
+	slide.Chapter = class == classChapter
+	return slide, class
+}
+
+// chartKind returns the kind of the chart, a bar chart by default.
+func chartKind(c structure.Chart) string {
+	switch c.Kind {
+	case chart.KindLine, chart.KindPie:
+		return c.Kind
+	}
+	return chart.KindBar
+}
+
+// singleLine joins the lines of a heading.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// fenced returns the code block, with a fence if it has none.
+func fenced(code string) string {
+	code = strings.Trim(code, "\n")
+	if strings.HasPrefix(code, "```") || strings.HasPrefix(code, "~~~") {
+		return code
+	}
+	return "```\n" + code + "\n```"
+}
+
+// splitCode separates the lines of the body from its last fenced code block.
+func splitCode(lines []string) ([]string, string) {
+	start := -1
+	var fence string
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence == "" && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")):
+			fence, start = trimmed[:3], i
+		case fence != "" && strings.HasPrefix(trimmed, fence):
+			fence = ""
+		}
+	}
+	if start < 0 {
+		return lines, ""
+	}
+	return lines[:start], strings.Trim(strings.Join(lines[start:], "\n"), "\n")
+}
+
+// splitTable separates the lines of the body from the first pipe table.
+func splitTable(lines []string) ([]string, structure.Table) {
+	for i := range lines {
+		if t, ok := slidesutils.ParseTable(strings.Join(lines[i:], "\n")); ok {
+			return lines[:i], t
+		}
+	}
+	return lines, structure.Table{}
+}
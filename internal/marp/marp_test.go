@@ -0,0 +1,41 @@
+package marp
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+func TestExportImport(t *testing.T) {
+	p := &structure.Presentation{
+		Title:    "The title: a test",
+		Subtitle: "The subtitle",
+		Slides: []structure.Slide{
+			{Title: "Executive summary", Subtitle: "In short", Body: "this is a **bold** word and this is a list:\n- level 1\n  - level 2\n\nAnother paragraph"},
+			{Title: "A chapter", Body: "The description of the chapter", Chapter: true, Notes: "Introduce the chapter"},
+			{Title: "A slide without subtitle", Body: "The body", Notes: "First talking point\n\nSecond talking point"},
+			{Title: "A table slide", Table: structure.Table{Header: []string{"Name", "Pipe | inside"}, Rows: [][]string{{"a", "1"}, {"b", ""}}}},
+			{Title: "A chart slide", Chart: structure.Chart{Kind: "pie", Labels: []string{"A", "B"}, Series: []structure.Series{{Name: "Share", Values: []float64{60.5, 39.5}}}}, Notes: "Comment the chart"},
+			{Title: "A code slide", Subtitle: "In Python", Body: "Some context", Code: "```python\n# a comment\ndef f():\n\treturn 1\n```", Notes: "Explain the code"},
+			{Title: "A YAML code slide", Code: "```yaml\n---\nkey: value\n---\nother: value\n```"},
+			{Title: "A horizontal rule", Body: "Above\n\n---\n\nBelow\n\n~~~\n---\n~~~", Notes: "Notes\n---\nwith a rule"},
+			{Body: "A slide without title"},
+			{Subtitle: "A subtitle without title", Body: "# A heading"},
+			{Title: "A body starting with a heading", Body: "## Not a subtitle\n\nThe body"},
+			{Title: "Comment terminators", Notes: "a --> b\nc --\\> d -->"},
+		},
+	}
+	var buf bytes.Buffer
+	if err := Export(&buf, p); err != nil {
+		t.Fatal(err)
+	}
+	result, err := Import(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result, p) {
+		t.Errorf("Import(Export()) = %+v, want %+v", result, p)
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Package outline builds a presentation from the headings of a Markdown document, without calling the model.
+
+The first level 1 heading is the title of the presentation, and a single line paragraph following it its subtitle;
+the text preceding the first heading becomes a content slide titled after the presentation.
+The level 2 headings are chapters and the level 3 headings content slides, whose body is the Markdown up to the
+next heading of level 1 to 3 (the deeper headings remain in the body):
+
+	# The title            -> the title of the presentation
+	The subtitle           -> its subtitle
+	## A chapter           -> a chapter slide
+	Some introduction      -> a content slide titled "A chapter"
+	### A section          -> a content slide, subtitled "A chapter"
+	Paragraphs and lists   -> its body
+	#### A sub-section     -> a heading in the body
+
+The text of a chapter before its first section becomes a content slide titled after the chapter, and the body of the
+chapter slide lists its sections, to describe its illustration.
+The pipe tables become table slides following the slide of their section (see slidesutils.ExtractTables).
+The same document always gives the same presentation.
+*/
+package outline
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+var (
+	heading     = regexp.MustCompile(`^(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+	tableMarker = regexp.MustCompile(`^\[\[table (\d+)\]\]$`)
+)
+
+// section is the content following a heading of level 1 to 3, up to the next one.
+type section struct {
+	level  int
+	title  string
+	lines  []string
+	tables []structure.Slide
+}
+
+// Parse returns the presentation described by the headings of the Markdown content.
+// The title is the title of the presentation when the content has no level 1 heading.
+func Parse(content, title string) *structure.Presentation {
+	text, tables := slidesutils.ExtractTables(content)
+	sections := split(text, tables)
+
+	p := &structure.Presentation{Title: title}
+	for _, s := range sections {
+		if s.level == 1 {
+			p.Title = s.title
+			break
+		}
+	}
+	titled := false
+	var chapter string
+	for i, s := range sections {
+		body := strings.Trim(strings.Join(s.lines, "\n"), "\n")
+		switch {
+		case s.level == 0:
+			// The introduction preceding the first heading
+			p.Slides = append(p.Slides, contentSlides(p.Title, "", body, s.tables)...)
+		case s.level == 1 && !titled:
+			titled = true
+			if subtitle, ok := singleLine(body); ok {
+				p.Subtitle, body = subtitle, ""
+			}
+			p.Slides = append(p.Slides, contentSlides(p.Title, "", body, s.tables)...)
+		case s.level <= 2:
+			// The other level 1 headings are chapters as well
+			chapter = s.title
+			p.Slides = append(p.Slides, chapterSlide(s.title, body, sections[i+1:]))
+			p.Slides = append(p.Slides, contentSlides(s.title, "", body, s.tables)...)
+		default:
+			p.Slides = append(p.Slides, contentSlides(s.title, chapter, body, s.tables)...)
+		}
+	}
+	return p
+}
+
+// singleLine returns the text of the body if it is a single line paragraph.
+func singleLine(body string) (string, bool) {
+	if body == "" || strings.Contains(body, "\n") {
+		return "", false
+	}
+	paragraphs := slidesutils.Parse(body)
+	if len(paragraphs) != 1 || paragraphs[0].Level != 0 || paragraphs[0].Heading != 0 {
+		return "", false
+	}
+	return plainText(body), true
+}
+
+// split separates the sections of the text; the first one, of level 0, holds the lines preceding the first heading.
+// The table markers are replaced by the tables.
+func split(text string, tables []structure.Slide) []section {
+	sections := []section{{}}
+	var fence string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		current := &sections[len(sections)-1]
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		default:
+			if m := heading.FindStringSubmatch(trimmed); m != nil && len(m[1]) <= 3 {
+				sections = append(sections, section{level: len(m[1]), title: plainText(m[2])})
+				continue
+			}
+			if m := tableMarker.FindStringSubmatch(trimmed); m != nil {
+				if n, err := strconv.Atoi(m[1]); err == nil && n >= 1 && n <= len(tables) {
+					current.tables = append(current.tables, tables[n-1])
+					continue
+				}
+			}
+		}
+		current.lines = append(current.lines, line)
+	}
+	return sections
+}
+
+// chapterSlide returns the slide of a chapter; its body lists the sections of the chapter (the following sections of
+// level 3), or is the introduction of the chapter if it has none.
+func chapterSlide(title, body string, following []section) structure.Slide {
+	var items []string
+	for _, s := range following {
+		if s.level < 3 {
+			break
+		}
+		items = append(items, "- "+s.title)
+	}
+	description := strings.Join(items, "\n")
+	if description == "" {
+		description = body
+	}
+	return structure.Slide{Title: title, Body: description, Chapter: true}
+}
+
+// contentSlides returns the content slide of a section, unless its body is empty, followed by its tables.
+func contentSlides(title, subtitle, body string, tables []structure.Slide) []structure.Slide {
+	var slides []structure.Slide
+	if strings.TrimSpace(body) != "" {
+		slides = append(slides, structure.Slide{Title: title, Subtitle: subtitle, Body: body})
+	}
+	for _, t := range tables {
+		t.Subtitle = subtitle
+		slides = append(slides, t)
+	}
+	return slides
+}
+
+// plainText returns the text of a heading or a line without its inline Markdown.
+func plainText(s string) string {
+	var sb strings.Builder
+	for _, p := range slidesutils.Parse(s) {
+		for _, r := range p.Runs {
+			sb.WriteString(r.Text)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
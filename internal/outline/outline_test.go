@@ -0,0 +1,118 @@
+package outline
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		title   string
+		want    *structure.Presentation
+	}{
+		{
+			name: "headings",
+			content: `# The **title**
+The subtitle
+
+## First chapter
+
+An introduction
+
+### A section
+
+Some text
+- a list
+
+#### A sub-section
+
+` + "```" + `sh
+# not a heading
+` + "```" + `
+
+## Second chapter
+
+| Name | Value |
+|------|-------|
+| a    | b     |
+
+### Another section
+Text`,
+			title: "ignored",
+			want: &structure.Presentation{
+				Title:    "The title",
+				Subtitle: "The subtitle",
+				Slides: []structure.Slide{
+					{Title: "First chapter", Body: "- A section", Chapter: true},
+					{Title: "First chapter", Body: "An introduction"},
+					{Title: "A section", Subtitle: "First chapter", Body: "Some text\n- a list\n\n#### A sub-section\n\n```sh\n# not a heading\n```"},
+					{Title: "Second chapter", Body: "- Another section", Chapter: true},
+					{Title: "Second chapter", Table: structure.Table{Header: []string{"Name", "Value"}, Rows: [][]string{{"a", "b"}}}},
+					{Title: "Another section", Subtitle: "Second chapter", Body: "Text"},
+				},
+			},
+		},
+		{
+			name:    "no title",
+			content: "An introduction\n\n## A chapter\n\nIts content",
+			title:   "article",
+			want: &structure.Presentation{
+				Title: "article",
+				Slides: []structure.Slide{
+					{Title: "article", Body: "An introduction"},
+					{Title: "A chapter", Body: "Its content", Chapter: true},
+					{Title: "A chapter", Body: "Its content"},
+				},
+			},
+		},
+		{
+			name:    "long introduction",
+			content: "# Title\n\nA first paragraph\n\nA second one",
+			want: &structure.Presentation{
+				Title:  "Title",
+				Slides: []structure.Slide{{Title: "Title", Body: "A first paragraph\n\nA second one"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.content, tt.title); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseArticle(t *testing.T) {
+	content, err := os.ReadFile("../../testdata/article.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := Parse(string(content), "article")
+	var titles []string
+	for _, slide := range p.Slides {
+		if slide.Chapter {
+			titles = append(titles, "chapter "+slide.Title)
+		} else {
+			titles = append(titles, slide.Title)
+		}
+	}
+	want := []string{
+		"chapter Context", "Context",
+		"chapter Modeling the evolution", "Modeling the evolution",
+		"The model in a glimpse", "The model of the data according to Wardley", "Deriving the model", "The representation",
+		"chapter Using the diagram: Data-as-a-product and data-contract", "Using the diagram: Data-as-a-product and data-contract",
+		"chapter Conclusion", "Conclusion",
+	}
+	if p.Title != "article" || !reflect.DeepEqual(titles, want) {
+		t.Errorf("Parse() = %q, %q, want %q", p.Title, titles, want)
+	}
+	if got := Parse(string(content), "article"); !reflect.DeepEqual(got, p) {
+		t.Error("Parse() is not reproducible")
+	}
+}
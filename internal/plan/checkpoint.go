@@ -0,0 +1,94 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CheckpointVersion is the version of the checkpoint format written by this package.
+const CheckpointVersion = 1
+
+// Checkpoint is the progress of the build of a plan in a Google Slides presentation.
+// It is written during the build so that an interrupted build can resume in the same presentation,
+// without generating the content nor the illustrations again.
+type Checkpoint struct {
+	// Version of the format of the checkpoint
+	Version int `json:"version"`
+	// UpdatedAt is the date of the last update
+	UpdatedAt time.Time `json:"updated_at"`
+	// PresentationID is the presentation being built
+	PresentationID string `json:"presentation_id"`
+	// Plan is the plan being built
+	Plan *Plan `json:"plan"`
+	// Cover is true once the cover slide is built
+	Cover bool `json:"cover"`
+	// Slides is the number of slides of the plan built; the build resumes at this index
+	Slides int `json:"slides"`
+	// Chapter is the number of the next chapter
+	Chapter int `json:"chapter"`
+	// Images holds the URL of the uploaded illustrations, indexed by slide
+	Images map[int]string `json:"images,omitempty"`
+	// ObjectIDPrefix and LastObjectID are the state of the object IDs assigned by the builder
+	ObjectIDPrefix string `json:"object_id_prefix,omitempty"`
+	LastObjectID   int    `json:"last_object_id,omitempty"`
+}
+
+// NewCheckpoint returns the checkpoint of a build of the plan that has not started yet.
+func NewCheckpoint(p *Plan) *Checkpoint {
+	return &Checkpoint{
+		Version: CheckpointVersion,
+		Plan:    p,
+		Images:  make(map[int]string),
+	}
+}
+
+// Save writes the checkpoint to path; the file is replaced atomically so that a crash cannot corrupt it.
+func (c *Checkpoint) Save(path string) error {
+	c.UpdatedAt = time.Now()
+	b, err := json.MarshalIndent(c, "", " ")
+	if err != nil {
+		return fmt.Errorf("cannot encode checkpoint: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadCheckpoint reads a checkpoint from a file and checks its version.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Checkpoint
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("%v: cannot decode checkpoint: %w", path, err)
+	}
+	if c.Version != CheckpointVersion {
+		return nil, fmt.Errorf("%v: unsupported checkpoint version %v (expected %v)", path, c.Version, CheckpointVersion)
+	}
+	if c.Plan == nil || c.Plan.Presentation == nil {
+		return nil, fmt.Errorf("%v: the checkpoint has no plan", path)
+	}
+	if c.PresentationID == "" {
+		return nil, fmt.Errorf("%v: the checkpoint has no presentation", path)
+	}
+	c.Plan.Presentation.OriginalContent = []byte(c.Plan.Content)
+	if c.Images == nil {
+		c.Images = make(map[int]string)
+	}
+	return &c, nil
+}
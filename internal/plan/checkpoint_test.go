@@ -0,0 +1,88 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveLoadCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "checkpoint.json")
+	c := NewCheckpoint(New(presentation(), nil))
+	c.PresentationID = "deck"
+	c.Cover = true
+	c.Slides = 1
+	c.Chapter = 2
+	c.Images[0] = "https://example.com/chapter.png"
+	c.ObjectIDPrefix, c.LastObjectID = "gss1", 12
+	if err := c.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	// A second save replaces the file
+	c.Slides = 2
+	if err := c.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 1 {
+		t.Errorf("got the files %v (%v), want the checkpoint alone", entries, err)
+	}
+
+	got, err := LoadCheckpoint(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.PresentationID != "deck" || !got.Cover || got.Slides != 2 || got.Chapter != 2 || got.ObjectIDPrefix != "gss1" || got.LastObjectID != 12 {
+		t.Errorf("got checkpoint %+v, want %+v", got, c)
+	}
+	if got.Images[0] != c.Images[0] || got.UpdatedAt.IsZero() {
+		t.Errorf("got images %v updated at %v", got.Images, got.UpdatedAt)
+	}
+	if string(got.Plan.Presentation.OriginalContent) != c.Plan.Content || len(got.Plan.Presentation.Slides) != 2 {
+		t.Errorf("got plan %+v, want %+v", got.Plan, c.Plan)
+	}
+}
+
+func TestLoadCheckpointErrors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+	tests := []struct {
+		name     string
+		filename string
+		wantErr  string
+	}{
+		{"missing file", filepath.Join(dir, "missing.json"), "no such file"},
+		{"not json", write("bad.json", "checkpoint"), "cannot decode checkpoint"},
+		{"version mismatch", write("version.json", `{"version":2}`), "unsupported checkpoint version 2 (expected 1)"},
+		{"missing plan", write("plan.json", `{"version":1,"presentation_id":"deck"}`), "the checkpoint has no plan"},
+		{"missing presentation", write("presentation.json", `{"version":1,"plan":{"version":1,"presentation":{}}}`), "the checkpoint has no presentation"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadCheckpoint(tt.filename)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadCheckpointImages(t *testing.T) {
+	// A checkpoint without illustration can record the next ones
+	filename := filepath.Join(t.TempDir(), "checkpoint.json")
+	if err := os.WriteFile(filename, []byte(`{"version":1,"presentation_id":"deck","plan":{"version":1,"presentation":{}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := LoadCheckpoint(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Images[0] = "https://example.com/chapter.png"
+}
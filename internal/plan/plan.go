@@ -0,0 +1,106 @@
+/*
+Package plan defines the presentation plan: everything needed to build a deck without calling the model.
+
+A plan is produced by the "plan" command from the generated structure, written as a versioned JSON file
+that can be reviewed, edited and versioned, and replayed by the "apply" command.
+*/
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// Version is the version of the plan format written by this package.
+const Version = 1
+
+// Plan is a presentation ready to be built.
+type Plan struct {
+	// Version of the format of the plan
+	Version int `json:"version"`
+	// CreatedAt is the date of the generation
+	CreatedAt time.Time `json:"created_at"`
+	// Content is the original content the presentation is generated from
+	Content string `json:"content,omitempty"`
+	// Presentation is the structure of the presentation; the prompt of the illustration of a chapter is on its slide
+	Presentation *structure.Presentation `json:"presentation"`
+	// Layouts maps each slide role to the layout of the template
+	Layouts map[string]string `json:"layouts,omitempty"`
+}
+
+// New returns the plan of the presentation; the illustration of a chapter without prompt is prompted with its
+// description. The prompts are set on the slides of the presentation, so that they follow the slides moved in the plan.
+func New(presentation *structure.Presentation, layouts map[string]string) *Plan {
+	for i, slide := range presentation.Slides {
+		if slide.Chapter && slide.ImagePrompt == "" {
+			presentation.Slides[i].ImagePrompt = slide.Body
+		}
+	}
+	return &Plan{
+		Version:      Version,
+		CreatedAt:    time.Now(),
+		Content:      string(presentation.OriginalContent),
+		Presentation: presentation,
+		Layouts:      layouts,
+	}
+}
+
+// ImagePrompt returns the prompt of the illustration of the i-th slide, its body if it has none.
+func (p *Plan) ImagePrompt(i int) string {
+	if prompt := p.Presentation.Slides[i].ImagePrompt; prompt != "" {
+		return prompt
+	}
+	return p.Presentation.Slides[i].Body
+}
+
+// Write encodes the plan as indented JSON.
+func (p *Plan) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", " ")
+	return enc.Encode(p)
+}
+
+// Read decodes a plan and checks its version.
+// The prompts of the plans written before they were set on the slides, indexed by slide, are set on their slides.
+func Read(r io.Reader) (*Plan, error) {
+	var legacy struct {
+		Plan
+		ImagePrompts map[int]string `json:"image_prompts"`
+	}
+	if err := json.NewDecoder(r).Decode(&legacy); err != nil {
+		return nil, fmt.Errorf("cannot decode plan: %w", err)
+	}
+	p := legacy.Plan
+	if p.Version != Version {
+		return nil, fmt.Errorf("unsupported plan version %v (expected %v)", p.Version, Version)
+	}
+	if p.Presentation == nil {
+		return nil, fmt.Errorf("the plan has no presentation")
+	}
+	for i, prompt := range legacy.ImagePrompts {
+		if i >= 0 && i < len(p.Presentation.Slides) && p.Presentation.Slides[i].ImagePrompt == "" {
+			p.Presentation.Slides[i].ImagePrompt = prompt
+		}
+	}
+	p.Presentation.OriginalContent = []byte(p.Content)
+	return &p, nil
+}
+
+// Load reads the plan from a file.
+func Load(path string) (*Plan, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", path, err)
+	}
+	return p, nil
+}
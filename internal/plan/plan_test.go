@@ -0,0 +1,126 @@
+package plan
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// presentation returns a presentation with a chapter and a content slide.
+func presentation() *structure.Presentation {
+	return &structure.Presentation{
+		OriginalContent: []byte("# Budget\n\nThe budget grows."),
+		Title:           "Budget",
+		Slides: []structure.Slide{
+			{Title: "Results", Body: "A chart of the results", Chapter: true},
+			{Title: "Growth", Body: "- The budget grows", Notes: "notes"},
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	p := New(presentation(), map[string]string{"cover": "TITLE"})
+	if p.Version != Version || p.Content != "# Budget\n\nThe budget grows." || p.Layouts["cover"] != "TITLE" {
+		t.Errorf("got plan %+v", p)
+	}
+	if p.Presentation.Slides[0].ImagePrompt != "A chart of the results" || p.Presentation.Slides[1].ImagePrompt != "" {
+		t.Errorf("got slides %+v, want the body of the chapter as its image prompt", p.Presentation.Slides)
+	}
+	// A slide without prompt is illustrated with its body
+	if got := p.ImagePrompt(1); got != "- The budget grows" {
+		t.Errorf("got image prompt %q", got)
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	p := New(presentation(), map[string]string{"cover": "TITLE", "content": "TITLE_AND_BODY"})
+	p.Presentation.Slides[0].ImagePrompt = "a rising curve"
+	var buf bytes.Buffer
+	if err := p.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.CreatedAt.Equal(p.CreatedAt) || got.Content != p.Content || got.Layouts["content"] != "TITLE_AND_BODY" {
+		t.Errorf("got plan %+v, want %+v", got, p)
+	}
+	if got.ImagePrompt(0) != "a rising curve" {
+		t.Errorf("got image prompt %q", got.ImagePrompt(0))
+	}
+	// The original content is not encoded with the presentation, but restored from the content of the plan
+	if string(got.Presentation.OriginalContent) != p.Content {
+		t.Errorf("got original content %q, want %q", got.Presentation.OriginalContent, p.Content)
+	}
+	if len(got.Presentation.Slides) != 2 || !reflect.DeepEqual(got.Presentation.Slides[1], p.Presentation.Slides[1]) {
+		t.Errorf("got slides %+v, want %+v", got.Presentation.Slides, p.Presentation.Slides)
+	}
+}
+
+func TestImagePromptFollowsSlide(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New(presentation(), nil).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// The user inserts a slide before the chapter
+	edited := strings.Replace(buf.String(), `"slides": [`, `"slides": [{"title": "Inserted", "body": "inserted"},`, 1)
+	got, err := Read(strings.NewReader(edited))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ImagePrompt(0) != "inserted" || got.ImagePrompt(1) != "A chart of the results" {
+		t.Errorf("got image prompts %q and %q, want the prompt of the chapter on the second slide", got.ImagePrompt(0), got.ImagePrompt(1))
+	}
+}
+
+func TestReadLegacyImagePrompts(t *testing.T) {
+	plan := `{"version":1,"presentation":{"slides":[{"title":"Results","chapter":true},{"title":"Growth"}]},"image_prompts":{"0":"a rising curve","5":"out of range"}}`
+	got, err := Read(strings.NewReader(plan))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Presentation.Slides[0].ImagePrompt != "a rising curve" || got.Presentation.Slides[1].ImagePrompt != "" {
+		t.Errorf("got slides %+v, want the prompt on the first slide", got.Presentation.Slides)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		plan    string
+		wantErr string
+	}{
+		{"not json", "plan", "cannot decode plan"},
+		{"version mismatch", `{"version":2,"presentation":{"presentation_title":"Budget"}}`, "unsupported plan version 2 (expected 1)"},
+		{"no version", `{"presentation":{"presentation_title":"Budget"}}`, "unsupported plan version 0 (expected 1)"},
+		{"missing presentation", `{"version":1}`, "the plan has no presentation"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.plan))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "plan.json")
+	if err := os.WriteFile(filename, []byte(`{"version":1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(filename); err == nil || !strings.HasPrefix(err.Error(), filename+": ") {
+		t.Errorf("got error %v, want an error naming the file", err)
+	}
+	if _, err := Load(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("got error %v, want a missing file", err)
+	}
+}
@@ -0,0 +1,328 @@
+package slidesutils
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"google.golang.org/api/slides/v1"
+)
+
+// TokenKind is the syntactic category of a token of source code.
+type TokenKind int
+
+// The kinds of tokens colored by Highlight.
+const (
+	TokenPlain TokenKind = iota
+	TokenKeyword
+	TokenString
+	TokenComment
+	TokenNumber
+)
+
+// Token is a portion of source code of a single kind.
+type Token struct {
+	Text string
+	Kind TokenKind
+}
+
+// CodeColors are the colors of the tokens as hexadecimal RGB values; the plain tokens keep the color of the text.
+var CodeColors = map[TokenKind]string{
+	TokenKeyword: "D73A49",
+	TokenString:  "032F62",
+	TokenComment: "6A737D",
+	TokenNumber:  "005CC5",
+}
+
+// lexicon describes the tokens of a programming language.
+type lexicon struct {
+	keywords        []string
+	caseInsensitive bool        // the keywords are matched whatever their case (SQL)
+	lineComments    []string    // the prefixes of the comments running to the end of the line
+	blockComments   [][2]string // the delimiters of the comments spanning several lines
+	quotes          string      // the delimiters of the strings
+	multiline       string      // the delimiters of the strings spanning several lines (raw strings, template literals)
+	tripleQuotes    bool        // """ and ''' delimit strings spanning several lines (Python)
+}
+
+var cLike = [][2]string{{"/*", "*/"}}
+
+// lexicons are indexed by the language name, as found in the info string of a fenced code block.
+var lexicons = map[string]*lexicon{
+	"go": {
+		keywords: []string{"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for", "func", "go", "goto", "if",
+			"import", "interface", "map", "package", "range", "return", "select", "struct", "switch", "type", "var", "nil", "true", "false", "iota"},
+		lineComments: []string{"//"}, blockComments: cLike, quotes: `"'`, multiline: "`",
+	},
+	"python": {
+		keywords: []string{"and", "as", "assert", "async", "await", "break", "class", "continue", "def", "del", "elif", "else", "except", "finally",
+			"for", "from", "global", "if", "import", "in", "is", "lambda", "nonlocal", "not", "or", "pass", "raise", "return", "try", "while",
+			"with", "yield", "None", "True", "False"},
+		lineComments: []string{"#"}, quotes: `"'`, tripleQuotes: true,
+	},
+	"javascript": {
+		keywords: []string{"async", "await", "break", "case", "catch", "class", "const", "continue", "default", "delete", "do", "else", "export",
+			"extends", "finally", "for", "function", "if", "import", "in", "instanceof", "let", "new", "of", "return", "static", "super", "switch",
+			"this", "throw", "try", "typeof", "var", "void", "while", "yield", "null", "undefined", "true", "false",
+			"interface", "type", "enum", "implements", "private", "public", "protected", "readonly"},
+		lineComments: []string{"//"}, blockComments: cLike, quotes: `"'`, multiline: "`",
+	},
+	"java": {
+		keywords: []string{"abstract", "boolean", "break", "byte", "case", "catch", "char", "class", "continue", "default", "do", "double", "else",
+			"enum", "extends", "final", "finally", "float", "for", "if", "implements", "import", "instanceof", "int", "interface", "long", "new",
+			"package", "private", "protected", "public", "return", "short", "static", "super", "switch", "this", "throw", "throws", "try", "var",
+			"void", "while", "null", "true", "false"},
+		lineComments: []string{"//"}, blockComments: cLike, quotes: `"'`,
+	},
+	"c": {
+		keywords: []string{"auto", "bool", "break", "case", "char", "class", "const", "continue", "default", "delete", "do", "double", "else", "enum",
+			"extern", "float", "for", "if", "include", "define", "inline", "int", "long", "namespace", "new", "nullptr", "private", "public",
+			"return", "short", "signed", "sizeof", "static", "struct", "switch", "template", "this", "typedef", "union", "unsigned", "using",
+			"virtual", "void", "while", "NULL", "true", "false"},
+		lineComments: []string{"//"}, blockComments: cLike, quotes: `"'`,
+	},
+	"rust": {
+		keywords: []string{"as", "async", "await", "break", "const", "continue", "crate", "else", "enum", "fn", "for", "if", "impl", "in", "let",
+			"loop", "match", "mod", "move", "mut", "pub", "ref", "return", "self", "Self", "static", "struct", "trait", "type", "unsafe", "use",
+			"where", "while", "true", "false"},
+		lineComments: []string{"//"}, blockComments: cLike, quotes: `"`,
+	},
+	"shell": {
+		keywords: []string{"if", "then", "else", "elif", "fi", "for", "while", "until", "do", "done", "case", "esac", "in", "function", "return",
+			"export", "local", "echo", "exit"},
+		lineComments: []string{"#"}, quotes: `"'`,
+	},
+	"sql": {
+		keywords: []string{"select", "from", "where", "and", "or", "not", "insert", "into", "values", "update", "set", "delete", "create", "table",
+			"drop", "alter", "join", "left", "right", "inner", "outer", "on", "group", "by", "order", "having", "limit", "as", "distinct", "null",
+			"is", "in", "union", "all", "primary", "key", "with"},
+		caseInsensitive: true, lineComments: []string{"--"}, blockComments: cLike, quotes: `'"`,
+	},
+	"yaml": {
+		keywords:     []string{"true", "false", "null", "yes", "no"},
+		lineComments: []string{"#"}, quotes: `"'`,
+	},
+}
+
+// languageAliases maps the usual names of the info strings to the lexicons.
+var languageAliases = map[string]string{
+	"golang": "go", "py": "python", "python3": "python", "js": "javascript", "jsx": "javascript", "ts": "javascript",
+	"typescript": "javascript", "tsx": "javascript", "json": "javascript", "cpp": "c", "c++": "c", "h": "c", "cs": "c",
+	"csharp": "c", "kotlin": "java", "scala": "java", "rs": "rust", "sh": "shell", "bash": "shell", "zsh": "shell",
+	"console": "shell", "yml": "yaml", "dockerfile": "shell",
+}
+
+// ParseCodeBlock splits a fenced code block into its language and its code.
+// The language is the first word of the info string of the opening fence (```go or ~~~ python), lower cased;
+// the closing fence is optional. A block without fence is returned unchanged with an empty language.
+// The code is otherwise preserved, including its indentation.
+func ParseCodeBlock(block string) (language, code string) {
+	block = strings.ReplaceAll(block, "\r\n", "\n")
+	trimmed := strings.TrimLeft(block, "\n")
+	if !strings.HasPrefix(trimmed, "```") && !strings.HasPrefix(trimmed, "~~~") {
+		return "", strings.Trim(block, "\n")
+	}
+	fence := trimmed[:3]
+	info, code, _ := strings.Cut(trimmed, "\n")
+	if fields := strings.Fields(strings.TrimLeft(info, fence[:1])); len(fields) > 0 {
+		language = strings.ToLower(fields[0])
+	}
+	code = strings.TrimRight(code, " \t\n")
+	if i := strings.LastIndex(code, "\n"); strings.HasPrefix(strings.TrimSpace(code[i+1:]), fence) {
+		code = code[:max(i, 0)]
+	}
+	return language, code
+}
+
+// Highlight splits the code into tokens according to the language (see ParseCodeBlock).
+// The concatenation of the tokens is the code. An unknown language gives a single plain token.
+func Highlight(code, language string) []Token {
+	if alias, ok := languageAliases[language]; ok {
+		language = alias
+	}
+	lex, ok := lexicons[language]
+	if !ok {
+		if code == "" {
+			return nil
+		}
+		return []Token{{Text: code}}
+	}
+	var tokens []Token
+	add := func(text string, kind TokenKind) {
+		if last := len(tokens) - 1; last >= 0 && tokens[last].Kind == kind {
+			tokens[last].Text += text
+			return
+		}
+		tokens = append(tokens, Token{Text: text, Kind: kind})
+	}
+	for i := 0; i < len(code); {
+		rest := code[i:]
+		if n := lex.comment(rest); n > 0 {
+			add(rest[:n], TokenComment)
+			i += n
+			continue
+		}
+		if n := lex.str(rest); n > 0 {
+			add(rest[:n], TokenString)
+			i += n
+			continue
+		}
+		c := code[i]
+		switch {
+		case isDigit(c) && (i == 0 || !isIdentifierByte(code[i-1])):
+			n := 1
+			for n < len(rest) && (isIdentifierByte(rest[n]) || rest[n] == '.' && n+1 < len(rest) && isDigit(rest[n+1])) {
+				n++
+			}
+			add(rest[:n], TokenNumber)
+			i += n
+		case isIdentifierByte(c):
+			n := 1
+			for n < len(rest) && isIdentifierByte(rest[n]) {
+				n++
+			}
+			if lex.isKeyword(rest[:n]) {
+				add(rest[:n], TokenKeyword)
+			} else {
+				add(rest[:n], TokenPlain)
+			}
+			i += n
+		default:
+			add(rest[:1], TokenPlain)
+			i++
+		}
+	}
+	return tokens
+}
+
+// comment returns the length of the comment at the beginning of text, 0 if text does not start with a comment.
+func (lex *lexicon) comment(text string) int {
+	for _, prefix := range lex.lineComments {
+		if strings.HasPrefix(text, prefix) {
+			if end := strings.IndexByte(text, '\n'); end >= 0 {
+				return end
+			}
+			return len(text)
+		}
+	}
+	for _, delimiters := range lex.blockComments {
+		if strings.HasPrefix(text, delimiters[0]) {
+			if end := strings.Index(text[len(delimiters[0]):], delimiters[1]); end >= 0 {
+				return len(delimiters[0]) + end + len(delimiters[1])
+			}
+			return len(text)
+		}
+	}
+	return 0
+}
+
+// str returns the length of the string literal at the beginning of text, 0 if text does not start with a string.
+// An unterminated string runs to the end of the line.
+func (lex *lexicon) str(text string) int {
+	if lex.tripleQuotes && (strings.HasPrefix(text, `"""`) || strings.HasPrefix(text, `'''`)) {
+		if end := strings.Index(text[3:], text[:3]); end >= 0 {
+			return 3 + end + 3
+		}
+		return len(text)
+	}
+	q := text[0]
+	switch {
+	case strings.IndexByte(lex.multiline, q) >= 0:
+		if end := strings.IndexByte(text[1:], q); end >= 0 {
+			return end + 2
+		}
+		return len(text)
+	case strings.IndexByte(lex.quotes, q) >= 0:
+		for n := 1; n < len(text); n++ {
+			switch text[n] {
+			case '\\':
+				n++
+			case q:
+				return n + 1
+			case '\n':
+				return n
+			}
+		}
+		return len(text)
+	}
+	return 0
+}
+
+func (lex *lexicon) isKeyword(word string) bool {
+	for _, keyword := range lex.keywords {
+		if keyword == word || lex.caseInsensitive && strings.EqualFold(keyword, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || isDigit(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// FormatCode returns the requests inserting the fenced code block (see ParseCodeBlock) in the shape objectID.
+//
+// The code is inserted verbatim, indentation included, in a monospace font; then the keywords, strings, comments
+// and numbers are colored (see CodeColors) by UpdateTextStyle requests. The keywords are bold and the comments italic.
+//
+// Parameters:
+//   - block: The fenced code block; the info string of the fence gives the language.
+//   - objectID: The ID of the shape receiving the code; it is expected to be empty.
+//
+// Returns:
+//   - []*slides.Request: The requests to send in a batch update, nil if the code is empty.
+func FormatCode(block string, objectID string) []*slides.Request {
+	language, code := ParseCodeBlock(block)
+	if code == "" {
+		return nil
+	}
+	length := int64(len(utf16.Encode([]rune(code))))
+	requests := []*slides.Request{
+		{
+			InsertText: &slides.InsertTextRequest{
+				ObjectId:       objectID,
+				InsertionIndex: 0,
+				Text:           code,
+			},
+		},
+		updateTextStyle(objectID, 0, length, &slides.TextStyle{FontFamily: codeFontFamily}, "bold,italic,underline,strikethrough,fontFamily"),
+	}
+	index := int64(0) // In UTF-16 code units as the Slides API
+	for _, token := range Highlight(code, language) {
+		n := int64(len(utf16.Encode([]rune(token.Text))))
+		if color, ok := CodeColors[token.Kind]; ok && strings.TrimSpace(token.Text) != "" {
+			style := &slides.TextStyle{ForegroundColor: rgbColor(color)}
+			fields := "foregroundColor"
+			switch token.Kind {
+			case TokenKeyword:
+				style.Bold = true
+				fields += ",bold"
+			case TokenComment:
+				style.Italic = true
+				fields += ",italic"
+			}
+			requests = append(requests, updateTextStyle(objectID, index, index+n, style, fields))
+		}
+		index += n
+	}
+	return requests
+}
+
+// rgbColor converts a hexadecimal RGB value to a color of the Slides API.
+func rgbColor(hex string) *slides.OptionalColor {
+	v, _ := strconv.ParseUint(hex, 16, 32)
+	return &slides.OptionalColor{
+		OpaqueColor: &slides.OpaqueColor{
+			RgbColor: &slides.RgbColor{
+				Red:   float64(v>>16&0xFF) / 255,
+				Green: float64(v>>8&0xFF) / 255,
+				Blue:  float64(v&0xFF) / 255,
+			},
+		},
+	}
+}
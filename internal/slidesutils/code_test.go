@@ -0,0 +1,100 @@
+package slidesutils
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/slides/v1"
+)
+
+func TestParseCodeBlock(t *testing.T) {
+	tests := []struct {
+		name         string
+		block        string
+		wantLanguage string
+		wantCode     string
+	}{
+		{"fenced", "```Go title=main.go\nfunc main() {\n\tprintln()\n}\n```\n", "go", "func main() {\n\tprintln()\n}"},
+		{"tilde without closing fence", "~~~ python\n    pass\n", "python", "    pass"},
+		{"no info string", "```\nls -l\n```", "", "ls -l"},
+		{"not fenced", "\n  x := 1\n", "", "  x := 1"},
+		{"empty", "```go\n```", "go", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			language, code := ParseCodeBlock(tt.block)
+			if language != tt.wantLanguage || code != tt.wantCode {
+				t.Errorf("ParseCodeBlock() = %q, %q, want %q, %q", language, code, tt.wantLanguage, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		language string
+		want     []Token
+	}{
+		{
+			name:     "go",
+			code:     "x := `a\nb` // done\nreturn \"\\\"\" + 'c' + 42",
+			language: "go",
+			want: []Token{
+				{Text: "x := "}, {Text: "`a\nb`", Kind: TokenString}, {Text: " "}, {Text: "// done", Kind: TokenComment}, {Text: "\n"},
+				{Text: "return", Kind: TokenKeyword}, {Text: " "}, {Text: `"\""`, Kind: TokenString}, {Text: " + "},
+				{Text: "'c'", Kind: TokenString}, {Text: " + "}, {Text: "42", Kind: TokenNumber},
+			},
+		},
+		{
+			name:     "alias and triple quotes",
+			code:     "def f(x2):\n    \"\"\"doc\n    string\"\"\"  # 1.5",
+			language: "py",
+			want: []Token{
+				{Text: "def", Kind: TokenKeyword}, {Text: " f(x2):\n    "}, {Text: "\"\"\"doc\n    string\"\"\"", Kind: TokenString},
+				{Text: "  "}, {Text: "# 1.5", Kind: TokenComment},
+			},
+		},
+		{
+			name:     "case insensitive keywords",
+			code:     "SELECT a /* b */ FROM t -- c",
+			language: "sql",
+			want: []Token{
+				{Text: "SELECT", Kind: TokenKeyword}, {Text: " a "}, {Text: "/* b */", Kind: TokenComment}, {Text: " "},
+				{Text: "FROM", Kind: TokenKeyword}, {Text: " t "}, {Text: "-- c", Kind: TokenComment},
+			},
+		},
+		{
+			name:     "unknown language",
+			code:     "if x then 'y'",
+			language: "cobol",
+			want:     []Token{{Text: "if x then 'y'"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Highlight(tt.code, tt.language); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Highlight() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatCode(t *testing.T) {
+	r := func(start, end int64) *slides.Range {
+		return &slides.Range{Type: "FIXED_RANGE", StartIndex: &start, EndIndex: &end}
+	}
+	want := []*slides.Request{
+		{InsertText: &slides.InsertTextRequest{ObjectId: "id", Text: "\tgo \"😀\""}},
+		{UpdateTextStyle: &slides.UpdateTextStyleRequest{ObjectId: "id", TextRange: r(0, 8), Style: &slides.TextStyle{FontFamily: codeFontFamily}, Fields: "bold,italic,underline,strikethrough,fontFamily"}},
+		{UpdateTextStyle: &slides.UpdateTextStyleRequest{ObjectId: "id", TextRange: r(1, 3), Style: &slides.TextStyle{Bold: true, ForegroundColor: rgbColor(CodeColors[TokenKeyword])}, Fields: "foregroundColor,bold"}},
+		{UpdateTextStyle: &slides.UpdateTextStyleRequest{ObjectId: "id", TextRange: r(4, 8), Style: &slides.TextStyle{ForegroundColor: rgbColor(CodeColors[TokenString])}, Fields: "foregroundColor"}},
+	}
+	if got := FormatCode("```golang\n\tgo \"😀\"\n```", "id"); !reflect.DeepEqual(got, want) {
+		t.Errorf("FormatCode() = %v, want %v", dumpRequests(got), dumpRequests(want))
+	}
+	if got := FormatCode("", "id"); got != nil {
+		t.Errorf("FormatCode() = %v, want nil", dumpRequests(got))
+	}
+}
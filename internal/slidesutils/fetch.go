@@ -0,0 +1,32 @@
+package slidesutils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// FetchImage returns the content of the image at location.
+// The location is either an http(s) URL or the path of a local file (optionally prefixed by file://).
+// It is used by the builders producing a self-contained output.
+func FetchImage(ctx context.Context, location string) ([]byte, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return os.ReadFile(strings.TrimPrefix(location, "file://"))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot fetch %v: %v", location, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
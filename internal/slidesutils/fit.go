@@ -0,0 +1,346 @@
+package slidesutils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+	"google.golang.org/api/slides/v1"
+)
+
+// TextBox is the frame of a text placeholder and the font size of its text, in points.
+type TextBox struct {
+	Frame    Frame
+	FontSize float64
+}
+
+// Part is a part of a body fitted in a text box (see Fit), and the font size it is shown at, in points.
+type Part struct {
+	Body     string
+	FontSize float64
+}
+
+const (
+	// DefaultFontSize is the font size of a body whose placeholder does not give it, in points.
+	DefaultFontSize = 18
+	// MinFontScale is the smallest scale of the font size of the placeholder a body shrinks to before being split.
+	MinFontScale = 0.75
+)
+
+// The metrics of the estimation of the text layout, in ems (the font size) unless noted otherwise.
+const (
+	emuPerPoint = 12700
+	textInset   = 7.2 // the inset of the text in its box, on each side, in points
+	lineSpacing = 1.2 // the height of a line
+	levelIndent = 1.5 // the indentation of a list item per nesting level, bullet included
+	monoWidth   = 0.6 // the width of a character of the code font
+	boldScale   = 1.05
+)
+
+// charWidth returns the estimated width of a character of a proportional font.
+func charWidth(r rune) float64 {
+	switch {
+	case r == ' ':
+		return 0.28
+	case strings.ContainsRune("iljtfrI.,;:'!|()[]", r):
+		return 0.3
+	case strings.ContainsRune("mwMW@%", r):
+		return 0.85
+	case r >= 'A' && r <= 'Z':
+		return 0.65
+	case r >= '0' && r <= '9':
+		return 0.55
+	case r > 0x2E80:
+		// The CJK characters are as wide as the font size
+		return 1
+	}
+	return 0.5
+}
+
+// TextHeight estimates the height in points of the paragraphs (see Parse) laid out in a box width points wide, with
+// a font of fontSize points: the words of each paragraph are wrapped on lines of the width of the box, less the
+// indentation of the list items; an empty paragraph is an empty line.
+func TextHeight(paragraphs []Paragraph, width, fontSize float64) float64 {
+	lines := 0
+	for _, p := range paragraphs {
+		available := width - float64(p.Level)*levelIndent*fontSize
+		lines += wrappedLines(p, available, fontSize)
+	}
+	return float64(lines) * lineSpacing * fontSize
+}
+
+// wrappedLines returns the number of lines of the paragraph wrapped at width points.
+func wrappedLines(p Paragraph, width, fontSize float64) int {
+	lines, x := 1, 0.0
+	for _, r := range p.Runs {
+		for _, word := range strings.SplitAfter(r.Text, " ") {
+			w := 0.0
+			for _, c := range word {
+				switch {
+				case r.Code:
+					w += monoWidth
+				case r.Bold || p.Heading > 0:
+					w += charWidth(c) * boldScale
+				default:
+					w += charWidth(c)
+				}
+			}
+			w *= fontSize
+			if x > 0 && x+w > width {
+				lines++
+				x = 0
+			}
+			x += w
+			// A word longer than the line is broken
+			for width > 0 && x > width {
+				lines++
+				x -= width
+			}
+		}
+	}
+	return lines
+}
+
+// inner returns the width and the height in points of the text area of the box.
+func (box TextBox) inner() (float64, float64) {
+	return box.Frame.Width/emuPerPoint - 2*textInset, box.Frame.Height/emuPerPoint - 2*textInset
+}
+
+// height returns the estimated height in points of the Markdown body in the box at the font size.
+func (box TextBox) height(body string, fontSize float64) float64 {
+	width, _ := box.inner()
+	return TextHeight(Parse(body), width, fontSize)
+}
+
+// Fit fits the Markdown body in the text box. A body overflowing the box at its font size is shown at a smaller size,
+// down to MinFontScale times the font size. Beyond that, it is split at the boundaries of its paragraphs and its
+// first level list items into parts fitting the box, of similar heights; a heading stays with what follows it,
+// and neither a nested list item nor a line of a code block starts a part. A part that still overflows the box,
+// such as a long paragraph, is shrunk as the whole body would be.
+// The estimation of the layout is a heuristic (see TextHeight): it does not know the actual metrics of the font.
+//
+// Parameters:
+//   - body: The Markdown body.
+//   - box: The text box of the body; a font size of 0 is DefaultFontSize.
+//
+// Returns:
+//   - []Part: The parts of the body with their font size, a single part if the body fits once shrunk.
+func Fit(body string, box TextBox) []Part {
+	if box.FontSize <= 0 {
+		box.FontSize = DefaultFontSize
+	}
+	if size, ok := box.shrink(body); ok {
+		return []Part{{Body: body, FontSize: size}}
+	}
+
+	// Split the body greedily to know the number of parts, then balance the parts: the smallest capacity giving as
+	// many parts is looked for from their average height
+	blocks := splitBlocks(body)
+	_, capacity := box.inner()
+	parts := box.pack(blocks, capacity)
+	if len(parts) > 1 {
+		average := box.height(strings.Join(blocks, "\n"), box.FontSize) / float64(len(parts))
+		for c := average; c < capacity; c += capacity / 20 {
+			if balanced := box.pack(blocks, c); len(balanced) == len(parts) {
+				parts = balanced
+				break
+			}
+		}
+	}
+	result := make([]Part, len(parts))
+	for i, part := range parts {
+		size, _ := box.shrink(part)
+		result[i] = Part{Body: part, FontSize: size}
+	}
+	return result
+}
+
+// FitFontSize returns the font size at which the Markdown body fits the text box without being split: the font size
+// of the box, or a smaller one down to MinFontScale times it (see Fit); a font size of 0 is DefaultFontSize.
+func FitFontSize(body string, box TextBox) float64 {
+	if box.FontSize <= 0 {
+		box.FontSize = DefaultFontSize
+	}
+	size, _ := box.shrink(body)
+	return size
+}
+
+// shrink returns the largest font size, from the font size of the box down to MinFontScale times it by steps of
+// a point, at which the body fits the box; it reports false, with the smallest size, if it fits at none.
+func (box TextBox) shrink(body string) (float64, bool) {
+	_, capacity := box.inner()
+	smallest := box.FontSize * MinFontScale
+	for size := box.FontSize; size > smallest; size-- {
+		if box.height(body, size) <= capacity {
+			return size, true
+		}
+	}
+	return smallest, box.height(body, smallest) <= capacity
+}
+
+// pack gathers the consecutive blocks into parts up to capacity points high at the font size of the box; a block
+// higher than the capacity is a part of its own.
+func (box TextBox) pack(blocks []string, capacity float64) []string {
+	var parts []string
+	var current []string
+	for _, b := range blocks {
+		if len(current) > 0 && box.height(strings.Join(append(current, b), "\n"), box.FontSize) > capacity {
+			parts = append(parts, strings.Join(current, "\n"))
+			current = nil
+		}
+		current = append(current, b)
+	}
+	if len(current) > 0 {
+		parts = append(parts, strings.Join(current, "\n"))
+	}
+	return parts
+}
+
+// splitBlocks splits the Markdown body into the blocks that are not split across slides: a heading with the block
+// following it, a paragraph, a first level list item with its nested items, or a fenced code block.
+// The empty lines at the edges of the blocks are dropped.
+func splitBlocks(body string) []string {
+	var blocks [][]string
+	var current []string
+	var fence string // the marker of the current code block
+	heldHeading := false
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			current = append(current, line)
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		case trimmed == "":
+			current = append(current, line)
+			continue
+		case len(trimmed) < len(strings.TrimRight(line, " \t")) && len(current) > 0:
+			// A nested list item or the continuation of a block
+			current = append(current, line)
+			continue
+		}
+		if !heldHeading && len(current) > 0 {
+			blocks = append(blocks, current)
+			current = nil
+		}
+		current = append(current, line)
+		heldHeading = heading.MatchString(trimmed)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+		}
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, current)
+	}
+	var result []string
+	for _, b := range blocks {
+		if text := strings.Trim(strings.Join(b, "\n"), "\n"); strings.TrimSpace(text) != "" {
+			result = append(result, text)
+		}
+	}
+	return result
+}
+
+// ContinuationTitle returns the title of the part i (from 0) of n parts of a slide, such as "Title (1/2)".
+func ContinuationTitle(title string, i, n int) string {
+	if n < 2 {
+		return title
+	}
+	return fmt.Sprintf("%v (%d/%d)", title, i+1, n)
+}
+
+// SplitSlide returns the slides showing a content slide whose body fits the text box (see Fit): the slide itself, or
+// its continuation slides titled such as "Title (1/2)" if the body is split, the speaker notes and the audio span going
+// to the first one. The chapters and the code, table and chart slides are not split.
+//
+// Parameters:
+//   - slide: The slide.
+//   - box: The text box of the body.
+//
+// Returns:
+//   - []structure.Slide: The slide, or its continuation slides.
+func SplitSlide(slide structure.Slide, box TextBox) []structure.Slide {
+	if slide.Chapter || slide.Code != "" || !slide.Table.Empty() || !slide.Chart.Empty() {
+		return []structure.Slide{slide}
+	}
+	parts := Fit(slide.Body, box)
+	if len(parts) < 2 {
+		return []structure.Slide{slide}
+	}
+	slides := make([]structure.Slide, len(parts))
+	for i, part := range parts {
+		slides[i] = slide
+		slides[i].Title = ContinuationTitle(slide.Title, i, len(parts))
+		slides[i].Body = part.Body
+		if i > 0 {
+			slides[i].Notes = ""
+			slides[i].Audio = structure.Span{}
+		}
+	}
+	return slides
+}
+
+// PlaceholderFontSize returns the font size in points of the text of a placeholder of a layout or a master: the first
+// size given by the style of its text, or else by the placeholder it inherits from. It returns 0 if no size is given.
+//
+// Parameters:
+//   - presentation: The presentation holding the layouts and the masters.
+//   - objectID: The ID of the placeholder in its layout or master.
+//
+// Returns:
+//   - float64: The font size in points, 0 if it is unknown.
+func PlaceholderFontSize(presentation *slides.Presentation, objectID string) float64 {
+	pages := append(append([]*slides.Page(nil), presentation.Layouts...), presentation.Masters...)
+	for depth := 0; objectID != "" && depth < 3; depth++ {
+		element := findElement(pages, objectID)
+		if element == nil || element.Shape == nil {
+			return 0
+		}
+		if element.Shape.Text != nil {
+			for _, t := range element.Shape.Text.TextElements {
+				if t.TextRun != nil && t.TextRun.Style != nil && fontSize(t.TextRun.Style) > 0 {
+					return fontSize(t.TextRun.Style)
+				}
+			}
+		}
+		objectID = ""
+		if element.Shape.Placeholder != nil {
+			objectID = element.Shape.Placeholder.ParentObjectId
+		}
+	}
+	return 0
+}
+
+// fontSize returns the font size in points of the style, 0 if it does not give it.
+func fontSize(style *slides.TextStyle) float64 {
+	if style.FontSize == nil || style.FontSize.Unit != "PT" {
+		return 0
+	}
+	return style.FontSize.Magnitude
+}
+
+// findElement returns the page element of the pages with the object ID, or nil.
+func findElement(pages []*slides.Page, objectID string) *slides.PageElement {
+	for _, page := range pages {
+		for _, element := range page.PageElements {
+			if element.ObjectId == objectID {
+				return element
+			}
+		}
+	}
+	return nil
+}
+
+// FontSizeRequest returns the request setting the font size in points of the whole text of the shape objectID.
+func FontSizeRequest(objectID string, size float64) *slides.Request {
+	return &slides.Request{
+		UpdateTextStyle: &slides.UpdateTextStyleRequest{
+			ObjectId:  objectID,
+			TextRange: &slides.Range{Type: "ALL"},
+			Style:     &slides.TextStyle{FontSize: &slides.Dimension{Magnitude: size, Unit: "PT"}},
+			Fields:    "fontSize",
+		},
+	}
+}
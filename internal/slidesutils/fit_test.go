@@ -0,0 +1,183 @@
+package slidesutils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+	"google.golang.org/api/slides/v1"
+)
+
+func TestTextHeight(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		width   float64
+		want    float64
+	}{
+		{"single line", "Hello world", 500, 1},
+		{"empty lines", "one\n\ntwo", 500, 3},
+		{"wrapped", strings.Repeat("word ", 40), 120, 10},
+		{"long word", strings.Repeat("a", 100), 100, 5},
+		{"nested list item", "- " + strings.Repeat("word ", 16) + "\n  - " + strings.Repeat("word ", 16), 254, 4},
+		{"code", "```\n" + strings.Repeat("x", 30) + "\n```", 100, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// At 10 points, a line is 12 points high
+			if got := TextHeight(Parse(tt.content), tt.width, 10) / 12; got != tt.want {
+				t.Errorf("TextHeight() = %v lines, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitBlocks(t *testing.T) {
+	body := "Intro\n\n## Heading\n\n- item 1\n  - nested\n- item 2\n```go\nfunc f() {\n\n}\n```\n~~~\nx\n\n~~~\nEnd\n"
+	want := []string{"Intro", "## Heading\n\n- item 1\n  - nested", "- item 2", "```go\nfunc f() {\n\n}\n```", "~~~\nx\n\n~~~", "End"}
+	if got := splitBlocks(body); fmt.Sprintf("%q", got) != fmt.Sprintf("%q", want) {
+		t.Errorf("splitBlocks() = %q, want %q", got, want)
+	}
+}
+
+func TestFit(t *testing.T) {
+	// A box of 600x240 points once the insets are removed: 10 lines at 20 points
+	box := TextBox{Frame: Frame{Width: (600 + 2*textInset) * emuPerPoint, Height: (240 + 2*textInset) * emuPerPoint}, FontSize: 20}
+	line := strings.Repeat("abcd ", 20) // 45.6 ems: an item is 2 lines long from 15 to 20 points
+	items := func(n int) string {
+		var lines []string
+		for i := 0; i < n; i++ {
+			lines = append(lines, fmt.Sprintf("- %d %v", i, line))
+		}
+		return strings.Join(lines, "\n")
+	}
+	tests := []struct {
+		name  string
+		body  string
+		parts []int // the number of items of each part
+		sizes []float64
+	}{
+		{"fits", items(4), []int{4}, []float64{20}},
+		{"shrunk", items(6), []int{6}, []float64{16}},
+		{"split", items(10), []int{5, 5}, []float64{20, 20}},
+		{"balanced", items(11), []int{4, 4, 3}, []float64{20, 20, 20}},
+		{"too long to split", strings.Repeat(line, 30), []int{0}, []float64{15}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := Fit(tt.body, box)
+			var got []int
+			var sizes []float64
+			for _, p := range parts {
+				got = append(got, strings.Count(p.Body, "- "))
+				sizes = append(sizes, p.FontSize)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.parts) || fmt.Sprint(sizes) != fmt.Sprint(tt.sizes) {
+				t.Errorf("Fit() gives parts of %v items at %v points, want %v at %v", got, sizes, tt.parts, tt.sizes)
+			}
+			if strings.Join(strings.Fields(joinParts(parts)), " ") != strings.Join(strings.Fields(tt.body), " ") {
+				t.Errorf("the parts do not make the body")
+			}
+		})
+	}
+}
+
+func TestFitFontSize(t *testing.T) {
+	box := TextBox{Frame: Frame{Width: (600 + 2*textInset) * emuPerPoint, Height: (240 + 2*textInset) * emuPerPoint}, FontSize: 20}
+	line := strings.Repeat("abcd ", 20)
+	tests := []struct {
+		name  string
+		lines int
+		want  float64
+	}{
+		{"fits", 4, 20},
+		{"shrunk", 6, 16},
+		{"too long", 10, 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := strings.TrimSpace(strings.Repeat("- "+line+"\n", tt.lines))
+			if got := FitFontSize(body, box); got != tt.want {
+				t.Errorf("FitFontSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitSlide(t *testing.T) {
+	box := TextBox{Frame: Frame{Width: (600 + 2*textInset) * emuPerPoint, Height: (240 + 2*textInset) * emuPerPoint}, FontSize: 20}
+	long := strings.TrimSpace(strings.Repeat("- "+strings.Repeat("abcd ", 20)+"\n", 10))
+	span := structure.Span{Start: "00:10", End: "00:20"}
+	tests := []struct {
+		name   string
+		slide  structure.Slide
+		titles []string
+	}{
+		{"fits", structure.Slide{Title: "Short", Body: "text", Notes: "notes", Audio: span}, []string{"Short"}},
+		{"split", structure.Slide{Title: "Long", Body: long, Notes: "notes", Audio: span}, []string{"Long (1/2)", "Long (2/2)"}},
+		{"chapter", structure.Slide{Title: "Chapter", Body: long, Chapter: true}, []string{"Chapter"}},
+		{"code", structure.Slide{Title: "Code", Body: long, Code: "```\ncode\n```"}, []string{"Code"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slides := SplitSlide(tt.slide, box)
+			var titles []string
+			for _, s := range slides {
+				titles = append(titles, s.Title)
+			}
+			if fmt.Sprint(titles) != fmt.Sprint(tt.titles) {
+				t.Fatalf("SplitSlide() gives the slides %q, want %q", titles, tt.titles)
+			}
+			if slides[0].Notes != tt.slide.Notes || slides[0].Audio != tt.slide.Audio {
+				t.Errorf("the first slide lost its notes or its audio span")
+			}
+			for _, s := range slides[1:] {
+				if s.Notes != "" || !s.Audio.Empty() {
+					t.Errorf("the continuation slide %q has notes or an audio span", s.Title)
+				}
+			}
+		})
+	}
+}
+
+func joinParts(parts []Part) string {
+	var bodies []string
+	for _, p := range parts {
+		bodies = append(bodies, p.Body)
+	}
+	return strings.Join(bodies, "\n")
+}
+
+func TestContinuationTitle(t *testing.T) {
+	if got := ContinuationTitle("Results", 0, 1); got != "Results" {
+		t.Errorf("ContinuationTitle() = %q, want the title", got)
+	}
+	if got := ContinuationTitle("Results", 1, 2); got != "Results (2/2)" {
+		t.Errorf("ContinuationTitle() = %q, want Results (2/2)", got)
+	}
+}
+
+func TestPlaceholderFontSize(t *testing.T) {
+	text := func(size float64) *slides.TextContent {
+		return &slides.TextContent{TextElements: []*slides.TextElement{
+			{ParagraphMarker: &slides.ParagraphMarker{}},
+			{TextRun: &slides.TextRun{Content: "Body\n", Style: &slides.TextStyle{FontSize: &slides.Dimension{Magnitude: size, Unit: "PT"}}}},
+		}}
+	}
+	presentation := &slides.Presentation{
+		Masters: []*slides.Page{{PageElements: []*slides.PageElement{
+			{ObjectId: "master_body", Shape: &slides.Shape{Text: text(14)}},
+		}}},
+		Layouts: []*slides.Page{{PageElements: []*slides.PageElement{
+			{ObjectId: "sized", Shape: &slides.Shape{Text: text(22), Placeholder: &slides.Placeholder{ParentObjectId: "master_body"}}},
+			{ObjectId: "inherited", Shape: &slides.Shape{Placeholder: &slides.Placeholder{ParentObjectId: "master_body"}}},
+			{ObjectId: "unsized", Shape: &slides.Shape{Placeholder: &slides.Placeholder{}}},
+		}}},
+	}
+	for id, want := range map[string]float64{"sized": 22, "inherited": 14, "unsized": 0, "missing": 0} {
+		if got := PlaceholderFontSize(presentation, id); got != want {
+			t.Errorf("PlaceholderFontSize(%v) = %v, want %v", id, got, want)
+		}
+	}
+}
@@ -1,199 +1,158 @@
 package slidesutils
 
 import (
-	"regexp"
 	"strings"
-	"unicode"
-	"unicode/utf8"
+	"unicode/utf16"
 
 	"google.golang.org/api/slides/v1"
 )
 
-type chunk struct {
-	content          string
-	isBold           bool
-	indentationLevel int // 0 means no indentation, 1 first bullet, 2 second bulled
-}
+// The bullet presets of the lists.
+const (
+	bulletPreset   = "BULLET_DISC_CIRCLE_SQUARE"
+	numberedPreset = "NUMBERED_DIGIT_ALPHA_ROMAN"
+)
 
-func Format(content string, objectID string) []*slides.Request {
-	chunks := parseContent(content)
+// codeFontFamily is the font of the code spans and code blocks.
+const codeFontFamily = "Courier New"
 
-	// Build the text content with styles
-	var requests []*slides.Request
-	currentIndex := int64(0) // Tracks the cumulative character index in the text box
+// listRange is a sequence of consecutive list items, bulleted by a single request.
+type listRange struct {
+	start, end int64
+	ordered    bool
+}
 
-	for _, c := range chunks {
+// Format returns the requests inserting the Markdown content (see Parse) in the shape objectID and styling it.
+//
+// The text is inserted at once, then every styled run is updated: bold, italic, underline, strikethrough,
+// link (TextStyle.Link) and monospace font for the code; the headings are bold.
+// The list items are prefixed by one tab per level of nesting: creating the bullets removes the tabs and sets the
+// nesting level of each paragraph. As it shifts the following text, the bullets are created last, from the end.
+// A list is numbered (NUMBERED_DIGIT_ALPHA_ROMAN) or bulleted (BULLET_DISC_CIRCLE_SQUARE) as its first level items.
+//
+// Parameters:
+//   - content: The Markdown content.
+//   - objectID: The ID of the shape receiving the content; it is expected to be empty.
+//
+// Returns:
+//   - []*slides.Request: The requests to send in a batch update, nil if the content is empty.
+func Format(content string, objectID string) []*slides.Request {
+	paragraphs := Parse(content)
+
+	var text strings.Builder
+	var styles []*slides.Request
+	var lists []listRange
+	index := int64(0) // Tracks the cumulative index in the text box, in UTF-16 code units as the Slides API
+	for i, p := range paragraphs {
+		if i > 0 {
+			text.WriteString("\n")
+			index++
+		}
+		start := index
+		if p.Level > 1 {
+			text.WriteString(strings.Repeat("\t", p.Level-1))
+			index += int64(p.Level - 1)
+		}
+		for _, r := range p.Runs {
+			n := int64(len(utf16.Encode([]rune(r.Text))))
+			if n == 0 {
+				continue
+			}
+			if style, fields := textStyle(r, p.Heading > 0); fields != "" {
+				styles = append(styles, updateTextStyle(objectID, index, index+n, style, fields))
+			}
+			text.WriteString(r.Text)
+			index += n
+		}
+		if p.Level == 0 {
+			continue
+		}
+		// A list continues on the next item, except when a first level item changes the kind of list
+		if last := len(lists) - 1; last >= 0 && lists[last].end == start-1 && (p.Level > 1 || lists[last].ordered == p.Ordered) {
+			lists[last].end = index
+		} else {
+			lists = append(lists, listRange{start: start, end: index, ordered: p.Ordered})
+		}
+	}
+	if index == 0 {
+		return nil
+	}
 
-		// Append the text to the text box
-		requests = append(requests, &slides.Request{
+	requests := []*slides.Request{
+		{
 			InsertText: &slides.InsertTextRequest{
 				ObjectId:       objectID,
-				InsertionIndex: currentIndex,
-				Text:           c.content, // Add a newline after each chunk
+				InsertionIndex: 0,
+				Text:           text.String(),
 			},
-		})
-
-		// Calculate the start and end indices for the current chunk
-		startIndex := currentIndex
-		endIndex := startIndex + int64(utf8.RuneCountInString(c.content))
-		// Replace only if the string ends with "\n\n"
-		//		if strings.HasSuffix(c.content, "\n\n") {
-		//			endIndex--
-		//		}
-
-		// Apply bold styling if needed
-		if c.isBold {
-			requests = append(requests, &slides.Request{
-				UpdateTextStyle: &slides.UpdateTextStyleRequest{
-					ObjectId: objectID,
-					TextRange: &slides.Range{
-						Type:       "FIXED_RANGE",
-						StartIndex: &startIndex,
-						EndIndex:   &endIndex,
-					},
-					Style: &slides.TextStyle{
-						Bold: true,
-					},
-					Fields: "bold",
-				},
-			})
-		} else {
-			requests = append(requests, &slides.Request{
-				UpdateTextStyle: &slides.UpdateTextStyleRequest{
-					ObjectId: objectID,
-					TextRange: &slides.Range{
-						Type:       "FIXED_RANGE",
-						StartIndex: &startIndex,
-						EndIndex:   &endIndex,
-					},
-					Style: &slides.TextStyle{
-						Bold: false,
-					},
-					Fields: "bold",
-				},
-			})
+		},
+		// The inserted text inherits the style of the placeholder: reset it before styling the runs
+		updateTextStyle(objectID, 0, index, &slides.TextStyle{}, "bold,italic,underline,strikethrough"),
+	}
+	requests = append(requests, styles...)
+	for i := len(lists) - 1; i >= 0; i-- {
+		preset := bulletPreset
+		if lists[i].ordered {
+			preset = numberedPreset
 		}
-
-		// Apply bullet points based on indentation level
-		if c.indentationLevel > 0 {
-			// Create bullet points
-			requests = append(requests, &slides.Request{
-				CreateParagraphBullets: &slides.CreateParagraphBulletsRequest{
-					ObjectId: objectID,
-					TextRange: &slides.Range{
-						Type:       "FIXED_RANGE",
-						StartIndex: &startIndex,
-						EndIndex:   &endIndex, // Include the newline for bullet points
-					},
-					BulletPreset: "BULLET_DISC_CIRCLE_SQUARE",
-				},
-			})
-
-			// Adjust indentation level
-			var indentStart, indentFirstStart float64
-			switch c.indentationLevel {
-			case 1:
-				indentFirstStart = 18.0 // First level indentation, in points
-				indentStart = 36.0      // First level indentation, in points
-			case 2:
-				indentFirstStart = 54.0 // First level indentation, in points
-				indentStart = 72.0      // Second level indentation, in points
-			default:
-				indentStart = float64(c.indentationLevel) * 36.0 // Adjust as needed for deeper levels
-			}
-
-			requests = append(requests, &slides.Request{
-				UpdateParagraphStyle: &slides.UpdateParagraphStyleRequest{
-					ObjectId: objectID,
-					TextRange: &slides.Range{
-						Type:       "FIXED_RANGE",
-						StartIndex: &startIndex,
-						EndIndex:   &endIndex,
-					},
-					Style: &slides.ParagraphStyle{
-						IndentFirstLine: &slides.Dimension{
-							Magnitude: indentFirstStart,
-							Unit:      "PT",
-						},
-						IndentStart: &slides.Dimension{
-							Magnitude: indentStart,
-							Unit:      "PT",
-						},
-					},
-					Fields: "indentStart, indentFirstLine", // Ensure only the indentStart field is updated
+		start, end := lists[i].start, lists[i].end
+		requests = append(requests, &slides.Request{
+			CreateParagraphBullets: &slides.CreateParagraphBulletsRequest{
+				ObjectId: objectID,
+				TextRange: &slides.Range{
+					Type:       "FIXED_RANGE",
+					StartIndex: &start,
+					EndIndex:   &end,
 				},
-			})
-		}
-
-		// Update the current index to account for the inserted text and newline
-		currentIndex = endIndex
+				BulletPreset: preset,
+			},
+		})
 	}
-
 	return requests
 }
 
-func parseContent(input string) []chunk {
-	var chunks []chunk
-
-	lines := strings.Split(input, "\n")              // Split the input into lines
-	boldRegex := regexp.MustCompile(`\*\*(.*?)\*\*`) // Regex to detect bold text
-
-	for _, line := range lines {
-		trimmedLine := filterPrintable(line)
-
-		// Determine the indentation level
-		var indentationLevel int
-		if strings.HasPrefix(trimmedLine, "- ") {
-			indentationLevel = 1
-			trimmedLine = strings.TrimPrefix(trimmedLine, "- ")
-		} else if strings.HasPrefix(trimmedLine, "  - ") {
-			indentationLevel = 2
-			trimmedLine = strings.TrimPrefix(trimmedLine, "  - ")
-		}
-
-		// Split the line into chunks by bold markers
-		parts := boldRegex.Split(trimmedLine, -1)
-		matches := boldRegex.FindAllStringSubmatch(trimmedLine, -1)
-
-		// Iterate over the parts and matches to build the chunks
-		for i, part := range parts {
-			if part != "" {
-				chunks = append(chunks, chunk{
-					content:          part,
-					isBold:           false,
-					indentationLevel: indentationLevel,
-				})
-			}
-
-			// Add the bold content if there's a match
-			if i < len(matches) {
-				chunks = append(chunks, chunk{
-					content:          matches[i][1], // The content inside the bold markers
-					isBold:           true,
-					indentationLevel: indentationLevel,
-				})
-			}
-		}
-		lastChunk := chunks[len(chunks)-1]
-		lastChunk.content = lastChunk.content + "\n"
-		chunks[len(chunks)-1] = lastChunk
+// textStyle returns the style of the run and the fields to update; the fields are empty for a plain run.
+func textStyle(r Run, heading bool) (*slides.TextStyle, string) {
+	style := &slides.TextStyle{}
+	var fields []string
+	if r.Bold || heading {
+		style.Bold = true
+		fields = append(fields, "bold")
 	}
-	// Remove the last carriage rerturn
-	lastChunk := chunks[len(chunks)-1]
-	lastChunk.content = lastChunk.content[:len(lastChunk.content)-1]
-	chunks[len(chunks)-1] = lastChunk
-
-	return chunks
+	if r.Italic {
+		style.Italic = true
+		fields = append(fields, "italic")
+	}
+	if r.Underline {
+		style.Underline = true
+		fields = append(fields, "underline")
+	}
+	if r.Strikethrough {
+		style.Strikethrough = true
+		fields = append(fields, "strikethrough")
+	}
+	if r.Code {
+		style.FontFamily = codeFontFamily
+		fields = append(fields, "fontFamily")
+	}
+	if r.Link != "" {
+		style.Link = &slides.Link{Url: r.Link}
+		fields = append(fields, "link")
+	}
+	return style, strings.Join(fields, ",")
 }
 
-func filterPrintable(s string) string {
-	result := []rune{}
-	for _, r := range s {
-		if unicode.IsSpace(r) {
-			result = append(result, ' ')
-		} else {
-			result = append(result, r)
-		}
+func updateTextStyle(objectID string, start, end int64, style *slides.TextStyle, fields string) *slides.Request {
+	return &slides.Request{
+		UpdateTextStyle: &slides.UpdateTextStyleRequest{
+			ObjectId: objectID,
+			TextRange: &slides.Range{
+				Type:       "FIXED_RANGE",
+				StartIndex: &start,
+				EndIndex:   &end,
+			},
+			Style:  style,
+			Fields: fields,
+		},
 	}
-	return string(result)
 }
@@ -3,26 +3,168 @@ package slidesutils
 import (
 	"reflect"
 	"testing"
+
+	"google.golang.org/api/slides/v1"
 )
 
-func TestParseContent(t *testing.T) {
-	input := `this is a **bold** word and this is a list:
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Paragraph
+	}{
+		{
+			name: "bold and bullets",
+			input: `this is a **bold** word and this is a list:
 - the level of indentation should be 1
   - this content should have a level indentation of 2
-and this is back to a level of indentation of zero`
-
-	expected := []chunk{
-		{content: "this is a ", isBold: false, indentationLevel: 0},
-		{content: "bold", isBold: true, indentationLevel: 0},
-		{content: " word and this is a list:\n", isBold: false, indentationLevel: 0},
-		{content: "the level of indentation should be 1\n", isBold: false, indentationLevel: 1},
-		{content: "this content should have a level indentation of 2\n", isBold: false, indentationLevel: 2},
-		{content: "and this is back to a level of indentation of zero", isBold: false, indentationLevel: 0},
+and this is back to a level of indentation of zero`,
+			want: []Paragraph{
+				{Runs: []Run{{Text: "this is a "}, {Text: "bold", Bold: true}, {Text: " word and this is a list:"}}},
+				{Level: 1, Runs: []Run{{Text: "the level of indentation should be 1"}}},
+				{Level: 2, Runs: []Run{{Text: "this content should have a level indentation of 2"}}},
+				{Runs: []Run{{Text: "and this is back to a level of indentation of zero"}}},
+			},
+		},
+		{
+			name:  "empty",
+			input: "",
+			want:  nil,
+		},
+		{
+			name:  "empty lines",
+			input: "first\n\nsecond",
+			want: []Paragraph{
+				{Runs: []Run{{Text: "first"}}},
+				{},
+				{Runs: []Run{{Text: "second"}}},
+			},
+		},
+		{
+			name:  "inline styles",
+			input: "*italic*, _italic_, __bold__, ***both***, ~~struck~~, <u>underlined</u> and `a **code** span`",
+			want: []Paragraph{
+				{Runs: []Run{
+					{Text: "italic", Italic: true}, {Text: ", "},
+					{Text: "italic", Italic: true}, {Text: ", "},
+					{Text: "bold", Bold: true}, {Text: ", "},
+					{Text: "both", Bold: true, Italic: true}, {Text: ", "},
+					{Text: "struck", Strikethrough: true}, {Text: ", "},
+					{Text: "underlined", Underline: true}, {Text: " and "},
+					{Text: "a **code** span", Code: true},
+				}},
+			},
+		},
+		{
+			name:  "nested styles",
+			input: "**bold with *italic* inside**",
+			want: []Paragraph{
+				{Runs: []Run{{Text: "bold with ", Bold: true}, {Text: "italic", Bold: true, Italic: true}, {Text: " inside", Bold: true}}},
+			},
+		},
+		{
+			name:  "literal delimiters",
+			input: `2 * 3 * 4, snake_case_name, \*escaped\* and **unclosed`,
+			want: []Paragraph{
+				{Runs: []Run{{Text: "2 * 3 * 4, snake_case_name, *escaped* and **unclosed"}}},
+			},
+		},
+		{
+			name:  "links",
+			input: "see [the **docs**](https://example.com/docs \"title\") or <https://example.com>",
+			want: []Paragraph{
+				{Runs: []Run{
+					{Text: "see "},
+					{Text: "the ", Link: "https://example.com/docs"},
+					{Text: "docs", Bold: true, Link: "https://example.com/docs"},
+					{Text: " or "},
+					{Text: "https://example.com", Link: "https://example.com"},
+				}},
+			},
+		},
+		{
+			name:  "numbered and nested lists",
+			input: "1. first\n2) second\n   * nested\n     1. deeper\n        - deepest\n\n3. third",
+			want: []Paragraph{
+				{Level: 1, Ordered: true, Runs: []Run{{Text: "first"}}},
+				{Level: 1, Ordered: true, Runs: []Run{{Text: "second"}}},
+				{Level: 2, Runs: []Run{{Text: "nested"}}},
+				{Level: 3, Ordered: true, Runs: []Run{{Text: "deeper"}}},
+				{Level: 4, Runs: []Run{{Text: "deepest"}}},
+				{Level: 1, Ordered: true, Runs: []Run{{Text: "third"}}},
+			},
+		},
+		{
+			name:  "headings and code blocks",
+			input: "## A *heading* ##\n```go\nfunc main() {\n\n\tfmt.Println(\"**\")\t// 2\n\t\treturn\n}\n```\nafter",
+			want: []Paragraph{
+				{Heading: 2, Runs: []Run{{Text: "A "}, {Text: "heading", Italic: true}}},
+				{Runs: []Run{{Text: "func main() {", Code: true}}},
+				{},
+				{Runs: []Run{{Text: "    fmt.Println(\"**\")   // 2", Code: true}}},
+				{Runs: []Run{{Text: "        return", Code: true}}},
+				{Runs: []Run{{Text: "}", Code: true}}},
+				{Runs: []Run{{Text: "after"}}},
+			},
+		},
 	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
 
-	result := parseContent(input)
-
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("parseContent() = %+v, want %+v", result, expected)
+func TestFormat(t *testing.T) {
+	r := func(start, end int64) *slides.Range {
+		return &slides.Range{Type: "FIXED_RANGE", StartIndex: &start, EndIndex: &end}
 	}
+	tests := []struct {
+		name  string
+		input string
+		want  []*slides.Request
+	}{
+		{
+			name:  "empty",
+			input: "",
+			want:  nil,
+		},
+		{
+			name:  "styles in UTF-16 code units",
+			input: "😀 **bold** [link](https://example.com)",
+			want: []*slides.Request{
+				{InsertText: &slides.InsertTextRequest{ObjectId: "id", Text: "😀 bold link"}},
+				{UpdateTextStyle: &slides.UpdateTextStyleRequest{ObjectId: "id", TextRange: r(0, 12), Style: &slides.TextStyle{}, Fields: "bold,italic,underline,strikethrough"}},
+				{UpdateTextStyle: &slides.UpdateTextStyleRequest{ObjectId: "id", TextRange: r(3, 7), Style: &slides.TextStyle{Bold: true}, Fields: "bold"}},
+				{UpdateTextStyle: &slides.UpdateTextStyleRequest{ObjectId: "id", TextRange: r(8, 12), Style: &slides.TextStyle{Link: &slides.Link{Url: "https://example.com"}}, Fields: "link"}},
+			},
+		},
+		{
+			name:  "nested lists",
+			input: "intro\n- a\n  - `b`\n1. c",
+			want: []*slides.Request{
+				{InsertText: &slides.InsertTextRequest{ObjectId: "id", Text: "intro\na\n\tb\nc"}},
+				{UpdateTextStyle: &slides.UpdateTextStyleRequest{ObjectId: "id", TextRange: r(0, 12), Style: &slides.TextStyle{}, Fields: "bold,italic,underline,strikethrough"}},
+				{UpdateTextStyle: &slides.UpdateTextStyleRequest{ObjectId: "id", TextRange: r(9, 10), Style: &slides.TextStyle{FontFamily: codeFontFamily}, Fields: "fontFamily"}},
+				// The lists are bulleted from the end as the tabs are removed
+				{CreateParagraphBullets: &slides.CreateParagraphBulletsRequest{ObjectId: "id", TextRange: r(11, 12), BulletPreset: numberedPreset}},
+				{CreateParagraphBullets: &slides.CreateParagraphBulletsRequest{ObjectId: "id", TextRange: r(6, 10), BulletPreset: bulletPreset}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Format(tt.input, "id"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Format() = %v, want %v", dumpRequests(got), dumpRequests(tt.want))
+			}
+		})
+	}
+}
+
+// dumpRequests returns the requests as JSON for the error messages.
+func dumpRequests(requests []*slides.Request) string {
+	b, _ := (&slides.BatchUpdatePresentationRequest{Requests: requests}).MarshalJSON()
+	return string(b)
 }
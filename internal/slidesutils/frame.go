@@ -0,0 +1,54 @@
+package slidesutils
+
+import "google.golang.org/api/slides/v1"
+
+// Frame describes the size and position of an element on a slide, in EMUs.
+type Frame struct {
+	Width      float64 `json:"width" yaml:"width"`
+	Height     float64 `json:"height" yaml:"height"`
+	TranslateX float64 `json:"x" yaml:"x"`
+	TranslateY float64 `json:"y" yaml:"y"`
+}
+
+// DefaultBodyFrame is the frame of a table or a chart when the layout has no body placeholder giving it: below the title
+// and the subtitle of a 10x7.5 inches slide.
+var DefaultBodyFrame = Frame{
+	Width:      8229600,
+	Height:     4648200,
+	TranslateX: 457200,
+	TranslateY: 1752600,
+}
+
+// DefaultChapterImageFrame is the frame of the illustration of a chapter in the original template.
+// The image is 3x3 inches on a 10x7.5 inches slide.
+var DefaultChapterImageFrame = Frame{
+	Width:      2743200,
+	Height:     2743200,
+	TranslateX: 1213950,
+	TranslateY: 1659800,
+}
+
+// ElementFrame returns the frame of the page element, or the default frame if its size or position is unknown.
+// The size is scaled as the element is; the frame is expressed in EMUs.
+func ElementFrame(element *slides.PageElement, defaultFrame Frame) Frame {
+	size, transform := element.Size, element.Transform
+	if size == nil || size.Width == nil || size.Height == nil || transform == nil {
+		return defaultFrame
+	}
+	if size.Width.Unit != "EMU" || size.Height.Unit != "EMU" || (transform.Unit != "" && transform.Unit != "EMU") {
+		return defaultFrame
+	}
+	scaleX, scaleY := transform.ScaleX, transform.ScaleY
+	if scaleX == 0 {
+		scaleX = 1
+	}
+	if scaleY == 0 {
+		scaleY = 1
+	}
+	return Frame{
+		Width:      size.Width.Magnitude * scaleX,
+		Height:     size.Height.Magnitude * scaleY,
+		TranslateX: transform.TranslateX,
+		TranslateY: transform.TranslateY,
+	}
+}
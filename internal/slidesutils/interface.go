@@ -14,6 +14,15 @@ type BuilderInterface interface {
 	// CreateSlideTitleSubtitleBody creates a slide with a title, subtitle, and body.
 	CreateSlideTitleSubtitleBody(ctx context.Context, slide structure.Slide) error
 
+	// CreateCodeSlide creates a slide with a title, subtitle, and the code block of the slide, highlighted.
+	CreateCodeSlide(ctx context.Context, slide structure.Slide) error
+
+	// CreateTableSlide creates a slide with a title, subtitle, and the table of the slide.
+	CreateTableSlide(ctx context.Context, slide structure.Slide) error
+
+	// CreateChartSlide creates a slide with a title, subtitle, and the image of the chart of the slide, rendered beforehand.
+	CreateChartSlide(ctx context.Context, slide structure.Slide, imageUrl string) error
+
 	// CreateCover creates a cover with the given title and subtitle.
 	CreateCover(ctx context.Context, title, subtitle string) error
 
@@ -23,3 +32,10 @@ type BuilderInterface interface {
 	// CreateNewSlide creates a new slide with the specified layout.
 	CreateNewSlide(ctx context.Context, layout string) error
 }
+
+// Flusher is implemented by the builders that defer their requests.
+// Flush must be called once all the slides are created.
+type Flusher interface {
+	// Flush sends the pending requests.
+	Flush(ctx context.Context) error
+}
@@ -0,0 +1,59 @@
+package slidesutils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/slides/v1"
+)
+
+// ListLayouts returns the layouts of the presentation indexed by their object ID.
+// The value is the layout name as defined in the master (LayoutProperties.Name).
+func ListLayouts(presentation *slides.Presentation) map[string]string {
+	layoutMap := make(map[string]string)
+	for _, layout := range presentation.Layouts {
+		if layout.LayoutProperties == nil {
+			continue
+		}
+		layoutMap[layout.ObjectId] = layout.LayoutProperties.Name
+	}
+	return layoutMap
+}
+
+// FindLayout returns the object ID of the layout matching name.
+// The name is compared, case insensitively, against the layout name first and its display name then.
+// For convenience, a layout object ID is also accepted.
+//
+// If no layout matches, the returned error lists the layouts available in the presentation.
+func FindLayout(presentation *slides.Presentation, name string) (string, error) {
+	for _, layout := range presentation.Layouts {
+		if layout.ObjectId == name {
+			return layout.ObjectId, nil
+		}
+	}
+	for _, layout := range presentation.Layouts {
+		if layout.LayoutProperties != nil && strings.EqualFold(layout.LayoutProperties.Name, name) {
+			return layout.ObjectId, nil
+		}
+	}
+	for _, layout := range presentation.Layouts {
+		if layout.LayoutProperties != nil && strings.EqualFold(layout.LayoutProperties.DisplayName, name) {
+			return layout.ObjectId, nil
+		}
+	}
+	return "", fmt.Errorf("no layout named %q, available layouts are: %v", name, strings.Join(describeLayouts(presentation), ", "))
+}
+
+// describeLayouts returns a sorted human readable description of the layouts of the presentation.
+func describeLayouts(presentation *slides.Presentation) []string {
+	descriptions := make([]string, 0, len(presentation.Layouts))
+	for _, layout := range presentation.Layouts {
+		if layout.LayoutProperties == nil {
+			continue
+		}
+		descriptions = append(descriptions, fmt.Sprintf("%q (%v, id %v)", layout.LayoutProperties.Name, layout.LayoutProperties.DisplayName, layout.ObjectId))
+	}
+	sort.Strings(descriptions)
+	return descriptions
+}
@@ -0,0 +1,45 @@
+package slidesutils
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/api/slides/v1"
+)
+
+func TestFindLayout(t *testing.T) {
+	layout := func(id, name, displayName string) *slides.Page {
+		return &slides.Page{ObjectId: id, LayoutProperties: &slides.LayoutProperties{Name: name, DisplayName: displayName}}
+	}
+	presentation := &slides.Presentation{Layouts: []*slides.Page{
+		layout("p1", "TITLE", "Title slide"),
+		layout("p2", "SECTION_HEADER", "Section header"),
+		layout("p3", "TITLE_AND_BODY", "Title and body"),
+	}}
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"p2", "p2", false},
+		{"TITLE_AND_BODY", "p3", false},
+		{"section_header", "p2", false},
+		{"Title slide", "p1", false},
+		{"title and BODY", "p3", false},
+		{"BLANK", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindLayout(presentation, tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindLayout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FindLayout() = %q, want %q", got, tt.want)
+			}
+			if err != nil && !strings.Contains(err.Error(), `"SECTION_HEADER" (Section header, id p2)`) {
+				t.Errorf("the error %q does not list the available layouts", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,395 @@
+package slidesutils
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Paragraph is a line of content as parsed by Parse.
+type Paragraph struct {
+	Level   int   // The nesting level of a list item: 0 means no bullet, 1 first level bullet, 2 second level bullet, and so on
+	Ordered bool  // Whether the list item is numbered
+	Heading int   // The level of a heading (1 to 6), 0 means not a heading
+	Runs    []Run // The portions of text of the paragraph
+}
+
+// Run is a portion of a paragraph sharing the same style.
+type Run struct {
+	Text          string
+	Bold          bool
+	Italic        bool
+	Underline     bool
+	Strikethrough bool
+	Code          bool   // A code span or a line of a code block, rendered in a monospace font
+	Link          string // The URL of a link, empty if the run is not a link
+}
+
+var (
+	listItem = regexp.MustCompile(`^([ \t]*)([-*+]|\d{1,9}[.)])[ \t]+(.*)$`)
+	heading  = regexp.MustCompile(`^(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+	autolink = regexp.MustCompile(`^<((?:https?|mailto):[^\s<>]+)>`)
+)
+
+// Parse parses the Markdown content and returns its paragraphs.
+// Every line is a paragraph (an empty line is an empty paragraph). The supported blocks are the headings,
+// the bulleted and numbered lists nested at any depth (by indentation), and the fenced code blocks;
+// the supported inlines are the bold, italic, strikethrough (~~), underline (<u>), code spans, links and autolinks.
+// The tabs of the code blocks are expanded to the next multiple of tabWidth columns, so that the indentation is kept.
+// It allows rendering the content with another backend than Google Slides.
+func Parse(content string) []Paragraph {
+	if content == "" {
+		return nil
+	}
+	var paragraphs []Paragraph
+	var indents []int // The indentation of the markers of the enclosing list items
+	var fence string  // The marker of the current code block
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+				continue
+			}
+			var p Paragraph
+			if code := filterPrintable(expandTabs(strings.TrimRight(line, " \t"))); code != "" {
+				p.Runs = []Run{{Text: code, Code: true}}
+			}
+			paragraphs = append(paragraphs, p)
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			indents = nil
+			continue
+		}
+		if trimmed == "" {
+			// The empty lines between the items of a list are dropped so that the list is not split
+			if len(indents) == 0 || !nextIsListItem(lines[i+1:]) {
+				paragraphs = append(paragraphs, Paragraph{})
+			}
+			continue
+		}
+		if m := listItem.FindStringSubmatch(line); m != nil {
+			indent := indentation(m[1])
+			for len(indents) > 0 && indents[len(indents)-1] >= indent {
+				indents = indents[:len(indents)-1]
+			}
+			indents = append(indents, indent)
+			paragraphs = append(paragraphs, Paragraph{
+				Level:   len(indents),
+				Ordered: m[2] != "-" && m[2] != "*" && m[2] != "+",
+				Runs:    parseInline(filterPrintable(m[3]), Run{}),
+			})
+			continue
+		}
+		indents = nil
+		if m := heading.FindStringSubmatch(trimmed); m != nil {
+			paragraphs = append(paragraphs, Paragraph{
+				Heading: len(m[1]),
+				Runs:    parseInline(filterPrintable(m[2]), Run{}),
+			})
+			continue
+		}
+		paragraphs = append(paragraphs, Paragraph{Runs: parseInline(filterPrintable(line), Run{})})
+	}
+	return paragraphs
+}
+
+// nextIsListItem reports whether the first non empty line is a list item.
+func nextIsListItem(lines []string) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			return listItem.MatchString(line)
+		}
+	}
+	return false
+}
+
+// indentation returns the width of the leading white space; a tab counts for four spaces.
+func indentation(s string) int {
+	width := 0
+	for _, r := range s {
+		if r == '\t' {
+			width += 4
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// emphasis is an inline delimiter and the style it applies.
+type emphasis struct {
+	delimiter string
+	apply     func(*Run)
+}
+
+// emphases are tried in order: the longest delimiters first.
+var emphases = []emphasis{
+	{"***", func(r *Run) { r.Bold, r.Italic = true, true }},
+	{"**", func(r *Run) { r.Bold = true }},
+	{"__", func(r *Run) { r.Bold = true }},
+	{"~~", func(r *Run) { r.Strikethrough = true }},
+	{"*", func(r *Run) { r.Italic = true }},
+	{"_", func(r *Run) { r.Italic = true }},
+}
+
+// parseInline splits the text into runs; each run inherits the style of the enclosing run.
+func parseInline(text string, style Run) []Run {
+	var runs []Run
+	var plain strings.Builder
+	flush := func() {
+		if plain.Len() > 0 {
+			r := style
+			r.Text = plain.String()
+			runs = append(runs, r)
+			plain.Reset()
+		}
+	}
+	nested := func(inner string, s Run) {
+		flush()
+		runs = append(runs, parseInline(inner, s)...)
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		switch c := text[i]; {
+		case c == '\\' && len(rest) > 1 && isPunctuation(rest[1]):
+			plain.WriteByte(rest[1])
+			i += 2
+			continue
+		case c == '`':
+			n := countPrefix(rest, '`')
+			if end := closingCodeSpan(rest[n:], n); end >= 0 {
+				code := rest[n : n+end]
+				if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' {
+					code = code[1 : len(code)-1]
+				}
+				flush()
+				r := style
+				r.Text, r.Code = code, true
+				runs = append(runs, r)
+				i += 2*n + end
+				continue
+			}
+			plain.WriteString(rest[:n])
+			i += n
+			continue
+		case c == '[':
+			if label, url, n, ok := parseLink(rest); ok {
+				s := style
+				s.Link = url
+				nested(label, s)
+				i += n
+				continue
+			}
+		case c == '<':
+			if m := autolink.FindStringSubmatch(rest); m != nil {
+				flush()
+				r := style
+				r.Text, r.Link = m[1], m[1]
+				runs = append(runs, r)
+				i += len(m[0])
+				continue
+			}
+			if strings.HasPrefix(rest, "<u>") {
+				if end := strings.Index(rest[3:], "</u>"); end > 0 {
+					s := style
+					s.Underline = true
+					nested(rest[3:3+end], s)
+					i += 3 + end + 4
+					continue
+				}
+			}
+		case c == '*' || c == '_' || c == '~':
+			matched := false
+			for _, e := range emphases {
+				if !strings.HasPrefix(rest, e.delimiter) {
+					continue
+				}
+				if end, ok := closingEmphasis(text, i, e.delimiter); ok {
+					s := style
+					e.apply(&s)
+					nested(text[i+len(e.delimiter):end], s)
+					i = end + len(e.delimiter)
+					matched = true
+					break
+				}
+			}
+			if matched {
+				continue
+			}
+			// An unmatched delimiter is literal, with the whole run of characters
+			n := countPrefix(rest, c)
+			plain.WriteString(rest[:n])
+			i += n
+			continue
+		}
+		plain.WriteByte(text[i])
+		i++
+	}
+	flush()
+	return runs
+}
+
+// closingEmphasis returns the index of the delimiter closing the one at start, following the Markdown flanking rules:
+// the opening delimiter is followed by a non-space, the closing one is preceded by a non-space,
+// and an underscore does not open nor close an emphasis within a word (as in snake_case).
+func closingEmphasis(text string, start int, delimiter string) (int, bool) {
+	d := delimiter[0]
+	open := start + len(delimiter)
+	if open >= len(text) || isSpace(text[open]) {
+		return 0, false
+	}
+	if d == '_' && start > 0 && isWordByte(text[start-1]) {
+		return 0, false
+	}
+	for j := open + 1; j < len(text); j++ {
+		switch text[j] {
+		case '\\':
+			j++
+			continue
+		case '`':
+			n := countPrefix(text[j:], '`')
+			if end := closingCodeSpan(text[j+n:], n); end >= 0 {
+				j += 2*n + end - 1
+			} else {
+				j += n - 1
+			}
+			continue
+		}
+		if text[j] != d {
+			continue
+		}
+		// The whole run of delimiter characters is considered: the closing delimiter is its end
+		n := countPrefix(text[j:], d)
+		if n < len(delimiter) || (len(delimiter) == 1 && n == 2) {
+			j += n - 1
+			continue
+		}
+		end := j + n - len(delimiter)
+		if isSpace(text[j-1]) {
+			j += n - 1
+			continue
+		}
+		if d == '_' && end+len(delimiter) < len(text) && isWordByte(text[end+len(delimiter)]) {
+			j += n - 1
+			continue
+		}
+		return end, true
+	}
+	return 0, false
+}
+
+// closingCodeSpan returns the index of the run of exactly n backticks closing a code span, or -1.
+func closingCodeSpan(text string, n int) int {
+	for j := 0; j < len(text); {
+		if text[j] != '`' {
+			j++
+			continue
+		}
+		m := countPrefix(text[j:], '`')
+		if m == n {
+			return j
+		}
+		j += m
+	}
+	return -1
+}
+
+// parseLink parses an inline link [label](url "title") at the beginning of text.
+// It returns the label, the URL and the length of the link.
+func parseLink(text string) (string, string, int, bool) {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if i+1 >= len(text) || text[i+1] != '(' {
+				return "", "", 0, false
+			}
+			end := strings.IndexByte(text[i+2:], ')')
+			if end < 0 {
+				return "", "", 0, false
+			}
+			target := strings.Fields(text[i+2 : i+2+end])
+			if len(target) == 0 {
+				return "", "", 0, false
+			}
+			url := strings.TrimSuffix(strings.TrimPrefix(target[0], "<"), ">")
+			return text[1:i], url, i + 3 + end, true
+		}
+	}
+	return "", "", 0, false
+}
+
+// countPrefix returns the number of consecutive c at the beginning of s.
+func countPrefix(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+func isPunctuation(c byte) bool {
+	return strings.IndexByte("\\`*_{}[]()#+-.!~<>|", c) >= 0
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// isWordByte reports whether the byte is part of a word; the bytes of multi-byte characters are considered as letters.
+func isWordByte(c byte) bool {
+	if c >= utf8.RuneSelf {
+		return true
+	}
+	return unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+// tabWidth is the number of columns between two tab stops of a code block.
+const tabWidth = 4
+
+// expandTabs replaces the tabs by spaces up to the next tab stop.
+func expandTabs(s string) string {
+	if !strings.Contains(s, "\t") {
+		return s
+	}
+	var b strings.Builder
+	column := 0
+	for _, r := range s {
+		if r == '\t' {
+			n := tabWidth - column%tabWidth
+			b.WriteString(strings.Repeat(" ", n))
+			column += n
+			continue
+		}
+		b.WriteRune(r)
+		column++
+	}
+	return b.String()
+}
+
+// filterPrintable replaces the white space characters (such as tabs) by spaces.
+func filterPrintable(s string) string {
+	result := []rune{}
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			result = append(result, ' ')
+		} else {
+			result = append(result, r)
+		}
+	}
+	return string(result)
+}
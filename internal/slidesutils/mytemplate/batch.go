@@ -0,0 +1,149 @@
+package mytemplate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	slides "google.golang.org/api/slides/v1"
+)
+
+// DefaultBatchSize is the default maximum number of requests sent in a single BatchUpdate.
+const DefaultBatchSize = 500
+
+// Queue adds the requests to the pending requests of the Builder.
+// The pending requests are sent when their number reaches BatchSize, and by Flush.
+//
+// Parameters:
+//   - ctx: A context to manage request lifetime.
+//   - requests: The requests to send, in order.
+//
+// Returns:
+//   - error: An error if a batch had to be sent and the API call failed.
+func (b *Builder) Queue(ctx context.Context, requests ...*slides.Request) error {
+	b.requests = append(b.requests, requests...)
+	if b.BatchSize > 0 && len(b.requests) >= b.BatchSize {
+		return b.send(ctx)
+	}
+	return nil
+}
+
+// AddSpeakerNotes sets the speaker notes of the current slide.
+// The object ID of the speaker notes shape is only known once the slide exists, so the notes are inserted by Flush.
+//
+// Parameters:
+//   - notes: The speaker notes; empty notes are ignored.
+func (b *Builder) AddSpeakerNotes(notes string) {
+	if notes == "" || b.CurrentSlide == nil {
+		return
+	}
+	if b.notes == nil {
+		b.notes = make(map[string]string)
+	}
+	b.notes[b.CurrentSlide.ObjectId] = notes
+}
+
+// Flush sends the pending requests in batches of BatchSize requests, then inserts the speaker notes.
+// It implements slidesutils.Flusher and must be called once all the slides are created.
+//
+// Parameters:
+//   - ctx: A context to manage request lifetime.
+//
+// Returns:
+//   - error: An error if an API call fails.
+func (b *Builder) Flush(ctx context.Context) error {
+	if err := b.send(ctx); err != nil {
+		return err
+	}
+	if len(b.notes) == 0 {
+		return nil
+	}
+
+	// Refresh the presentation to get the speaker notes shape of the new slides.
+	presentation, err := b.Srv.Presentations.Get(b.Presentation.PresentationId).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve updated presentation: %w", err)
+	}
+	b.Presentation = presentation
+	for _, slide := range presentation.Slides {
+		if notes, ok := b.notes[slide.ObjectId]; ok {
+			b.requests = append(b.requests, slidesutils.SpeakerNotes(slide, notes)...)
+		}
+	}
+	b.notes = nil
+	if err := b.send(ctx); err != nil {
+		return fmt.Errorf("failed to insert speaker notes: %w", err)
+	}
+	return nil
+}
+
+// send sends all the pending requests, BatchSize requests at a time.
+func (b *Builder) send(ctx context.Context) error {
+	for len(b.requests) > 0 {
+		n := len(b.requests)
+		if b.BatchSize > 0 && n > b.BatchSize {
+			n = b.BatchSize
+		}
+		if _, err := b.Srv.Presentations.BatchUpdate(b.Presentation.PresentationId, &slides.BatchUpdatePresentationRequest{
+			Requests: b.requests[:n],
+		}).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("failed to send %d requests: %w", n, err)
+		}
+		log.Printf("Sent %d requests", n)
+		b.requests = b.requests[n:]
+	}
+	b.requests = nil
+	return nil
+}
+
+// ObjectIDs returns the state of the object IDs assigned by the Builder, to be saved with a checkpoint.
+//
+// Returns:
+//   - string: The prefix of the object IDs.
+//   - int: The counter of the last object ID assigned.
+func (b *Builder) ObjectIDs() (string, int) {
+	return b.idPrefix, b.lastID
+}
+
+// Resume continues the object IDs of the Builder of a previous run, as returned by ObjectIDs once its requests were flushed.
+// The slides this previous Builder created afterwards were sent by a batch following the checkpoint and may be incomplete:
+// their deletion is queued so that the build can resume from the checkpoint.
+//
+// Parameters:
+//   - prefix: The prefix of the object IDs of the previous Builder.
+//   - last: The counter of the last object ID of the previous Builder at the checkpoint.
+func (b *Builder) Resume(ctx context.Context, prefix string, last int) error {
+	b.idPrefix, b.lastID = prefix, last
+	var requests []*slides.Request
+	for _, slide := range b.Presentation.Slides {
+		counter, ok := strings.CutPrefix(slide.ObjectId, prefix+"_")
+		if n, err := strconv.Atoi(counter); ok && err == nil && n > last {
+			requests = append(requests, &slides.Request{
+				DeleteObject: &slides.DeleteObjectRequest{ObjectId: slide.ObjectId},
+			})
+		}
+	}
+	if len(requests) > 0 {
+		log.Printf("Deleting %d slides created after the checkpoint", len(requests))
+	}
+	return b.Queue(ctx, requests...)
+}
+
+// NewObjectID returns a new object ID, unique within the presentation, for the elements created by the requests.
+// The IDs are prefixed by the creation time of the Builder so that successive runs on the same presentation do not collide.
+func (b *Builder) NewObjectID() string {
+	if b.idPrefix == "" {
+		b.idPrefix = newIDPrefix()
+	}
+	b.lastID++
+	return b.idPrefix + "_" + strconv.Itoa(b.lastID)
+}
+
+// newIDPrefix returns a prefix of object IDs based on the current time.
+func newIDPrefix() string {
+	return "gss" + strconv.FormatInt(time.Now().UnixNano(), 36)
+}
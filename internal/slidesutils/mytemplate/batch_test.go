@@ -0,0 +1,219 @@
+package mytemplate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/option"
+	slides "google.golang.org/api/slides/v1"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// fakeSlides is a Google Slides API server counting the calls.
+type fakeSlides struct {
+	gets     int
+	batches  []int // number of requests of each BatchUpdate
+	slideIDs []string
+	deleted  []string
+	texts    []string       // the inserted texts
+	cleared  []string       // the shapes whose text is deleted
+	layouts  []*slides.Page // the layouts of the template, the ones of the original template if nil
+}
+
+// fakeLayout returns a layout with a placeholder of each type.
+func fakeLayout(id string, types ...string) *slides.Page {
+	page := &slides.Page{ObjectId: id}
+	for i, t := range types {
+		page.PageElements = append(page.PageElements, &slides.PageElement{
+			ObjectId: id + "_" + t,
+			Shape:    &slides.Shape{Placeholder: &slides.Placeholder{Type: t, Index: int64(i)}},
+		})
+	}
+	return page
+}
+
+func (f *fakeSlides) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, ":batchUpdate") {
+		var req slides.BatchUpdatePresentationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.batches = append(f.batches, len(req.Requests))
+		for _, r := range req.Requests {
+			if r.CreateSlide != nil {
+				f.slideIDs = append(f.slideIDs, r.CreateSlide.ObjectId)
+			}
+			if r.DeleteObject != nil {
+				f.deleted = append(f.deleted, r.DeleteObject.ObjectId)
+			}
+			if r.InsertText != nil {
+				f.texts = append(f.texts, r.InsertText.Text)
+			}
+			if r.DeleteText != nil {
+				f.cleared = append(f.cleared, r.DeleteText.ObjectId)
+			}
+		}
+		json.NewEncoder(w).Encode(slides.BatchUpdatePresentationResponse{})
+		return
+	}
+	f.gets++
+	presentation := slides.Presentation{
+		PresentationId: "deck",
+		Layouts:        f.layouts,
+	}
+	if presentation.Layouts == nil {
+		presentation.Layouts = []*slides.Page{
+			fakeLayout(DefaultLayoutNames[RoleCover], "TITLE", "TITLE", "TITLE", "SUBTITLE"),
+			fakeLayout(DefaultLayoutNames[RoleChapter], "TITLE", "BODY", "SLIDE_NUMBER"),
+			fakeLayout(DefaultLayoutNames[RoleContent], "TITLE", "SUBTITLE", "BODY"),
+		}
+	}
+	for _, id := range f.slideIDs {
+		presentation.Slides = append(presentation.Slides, &slides.Page{
+			ObjectId: id,
+			SlideProperties: &slides.SlideProperties{
+				NotesPage: &slides.Page{NotesProperties: &slides.NotesProperties{SpeakerNotesObjectId: id + "_notes"}},
+			},
+		})
+	}
+	json.NewEncoder(w).Encode(presentation)
+}
+
+func TestBatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchSize int
+		notes     string
+		gets      int
+		batches   []int
+	}{
+		// 1 cover (5 requests), 10 chapters (3 requests), 20 slides (5 requests with the formatted body)
+		{"single batch", DefaultBatchSize, "", 1, []int{135}},
+		{"small batches", 100, "", 1, []int{100, 35}},
+		{"speaker notes", DefaultBatchSize, "notes", 2, []int{135, 30}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := &fakeSlides{}
+			server := httptest.NewServer(f)
+			defer server.Close()
+			srv, err := slides.NewService(ctx, option.WithEndpoint(server.URL), option.WithoutAuthentication())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := NewBuilder(ctx, srv, "deck", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b.BatchSize = tt.batchSize
+			if err := b.CreateCover(ctx, "title", "subtitle"); err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 10; i++ {
+				if err := b.CreateChapter(ctx, structure.Slide{Title: "chapter", Notes: tt.notes}); err != nil {
+					t.Fatal(err)
+				}
+				for j := 0; j < 2; j++ {
+					if err := b.CreateSlideTitleSubtitleBody(ctx, structure.Slide{Title: "title", Subtitle: "subtitle", Body: "body", Notes: tt.notes}); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if err := b.Flush(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if f.gets != tt.gets || len(f.batches) != len(tt.batches) {
+				t.Fatalf("got %v Get and BatchUpdate of %v requests, want %v and %v", f.gets, f.batches, tt.gets, tt.batches)
+			}
+			for i := range f.batches {
+				if f.batches[i] != tt.batches[i] {
+					t.Errorf("got BatchUpdate of %v requests, want %v", f.batches, tt.batches)
+				}
+			}
+		})
+	}
+}
+
+func TestResume(t *testing.T) {
+	ctx := context.Background()
+	// The slides of a previous run are gss1_1 and gss1_5; gss1_9 was sent after the checkpoint
+	f := &fakeSlides{slideIDs: []string{"template", "gss1_1", "gss1_5", "gss1_9", "gss2_9"}}
+	server := httptest.NewServer(f)
+	defer server.Close()
+	srv, err := slides.NewService(ctx, option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewBuilder(ctx, srv, "deck", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Resume(ctx, "gss1", 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.CreateSlideTitleSubtitleBody(ctx, structure.Slide{Title: "title", Body: "body"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.deleted) != 1 || f.deleted[0] != "gss1_9" {
+		t.Errorf("got deleted slides %v, want [gss1_9]", f.deleted)
+	}
+	if created := f.slideIDs[len(f.slideIDs)-1]; created != "gss1_6" {
+		t.Errorf("got new slide %v, want gss1_6", created)
+	}
+}
+
+func TestContinuationSlides(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeSlides{}
+	server := httptest.NewServer(f)
+	defer server.Close()
+	srv, err := slides.NewService(ctx, option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewBuilder(ctx, srv, "deck", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 40 items of two lines do not fit the default body frame, even at a smaller font size
+	var items []string
+	for i := 0; i < 40; i++ {
+		items = append(items, "- "+strings.Repeat("a long item ", 10))
+	}
+	parts := slidesutils.SplitSlide(structure.Slide{Title: "Results", Subtitle: "subtitle", Body: strings.Join(items, "\n")}, b.BodyBox())
+	if len(parts) < 2 {
+		t.Fatalf("got %v slides, want continuation slides", len(parts))
+	}
+	for _, part := range parts {
+		if err := b.CreateSlideTitleSubtitleBody(ctx, part); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// The builder creates a slide per part
+	var titles []string
+	for _, text := range f.texts {
+		if strings.HasPrefix(text, "Results") {
+			titles = append(titles, text)
+		}
+	}
+	if len(f.slideIDs) != len(parts) || len(titles) != len(parts) || titles[0] != fmt.Sprintf("Results (1/%d)", len(parts)) {
+		t.Errorf("got %v slides titled %q, want %v continuation slides", len(f.slideIDs), titles, len(parts))
+	}
+}
@@ -0,0 +1,57 @@
+package mytemplate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+	slides "google.golang.org/api/slides/v1"
+)
+
+// CreateChartSlide creates a new slide with a title, subtitle, and the image of the chart of the slide.
+// It uses the content layout: the image takes the place of the body placeholder, which is removed, or the
+// default body frame if the layout has no BODY placeholder.
+//
+// Parameters:
+//   - ctx: A context to manage request lifetime.
+//   - slide: A structure containing slide information such as title, subtitle, and speaker notes.
+//   - imageUrl: The URL of the rendered chart; it must remain reachable until the requests are flushed.
+//
+// Returns:
+//   - error: An error if the slide creation or the insertion of the chart fails.
+func (b *Builder) CreateChartSlide(ctx context.Context, slide structure.Slide, imageUrl string) error {
+	if err := b.CreateNewSlide(ctx, b.Layouts[RoleContent]); err != nil {
+		return fmt.Errorf("failed to create chart slide: %w", err)
+	}
+
+	// Ensure the current slide is set after creation.
+	if b.CurrentSlide == nil {
+		return fmt.Errorf("current slide is not set after creation")
+	}
+
+	// Find placeholders for title, subtitle, and body in the newly created slide.
+	title, subtitle, body := b.slidePlaceholders()
+
+	requests := append(insertText(title, slide.Title), insertText(subtitle, slide.Subtitle)...)
+	frame := slidesutils.DefaultBodyFrame
+	if body != nil {
+		frame = slidesutils.ElementFrame(body, slidesutils.DefaultBodyFrame)
+		requests = append(requests, &slides.Request{
+			DeleteObject: &slides.DeleteObjectRequest{
+				ObjectId: body.ObjectId,
+			},
+		})
+	}
+
+	// Queue the requests inserting the text and removing the body.
+	if err := b.Queue(ctx, requests...); err != nil {
+		return fmt.Errorf("failed to insert text: %w", err)
+	}
+	if err := b.InsertImage(ctx, imageUrl, frame.Width, frame.Height, frame.TranslateX, frame.TranslateY); err != nil {
+		return fmt.Errorf("failed to insert chart: %w", err)
+	}
+	b.AddSpeakerNotes(slide.Notes)
+
+	return nil
+}
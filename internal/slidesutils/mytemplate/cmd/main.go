@@ -44,7 +44,7 @@ func main() {
 	}
 
 	// Initialize the Builder
-	builder, err := mytemplate.NewBuilder(ctx, slidesSrv, presentationId)
+	builder, err := mytemplate.NewBuilder(ctx, slidesSrv, presentationId, nil)
 	if err != nil {
 		log.Fatalf("Unable to create Builder: %v", err)
 	}
@@ -98,6 +98,12 @@ and this is back to a level of indentation of zero` + "Inspired by Simon Wardley
 		log.Fatalf("Error inserting image: %v", err)
 	}
 
+	// Send the queued requests
+	err = builder.Flush(ctx)
+	if err != nil {
+		log.Fatalf("Error sending the requests: %v", err)
+	}
+
 	log.Println("Image inserted successfully.")
 
 	fmt.Println("New presentation created and modified successfully.")
@@ -0,0 +1,46 @@
+package mytemplate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// CreateCodeSlide creates a new slide with a title, subtitle, and the code block of the slide.
+// It uses the content layout: the code replaces the body, in a monospace font with its tokens colored (see slidesutils.FormatCode).
+//
+// Parameters:
+//   - ctx: A context to manage request lifetime.
+//   - slide: A structure containing slide information such as title, subtitle, code, and speaker notes.
+//
+// Returns:
+//   - error: An error if the slide creation or text insertion fails.
+func (b *Builder) CreateCodeSlide(ctx context.Context, slide structure.Slide) error {
+	if err := b.CreateNewSlide(ctx, b.Layouts[RoleContent]); err != nil {
+		return fmt.Errorf("failed to create code slide: %w", err)
+	}
+
+	// Ensure the current slide is set after creation.
+	if b.CurrentSlide == nil {
+		return fmt.Errorf("current slide is not set after creation")
+	}
+
+	// Find placeholders for title, subtitle, and body in the newly created slide.
+	title, subtitle, body := b.slidePlaceholders()
+	if body == nil {
+		return fmt.Errorf("the content layout has no BODY placeholder for the code of the slide %q", slide.Title)
+	}
+
+	textRequests := append(insertText(title, slide.Title), insertText(subtitle, slide.Subtitle)...)
+	textRequests = append(textRequests, slidesutils.FormatCode(slide.Code, body.ObjectId)...)
+
+	// Queue the requests inserting text into the placeholders.
+	if err := b.Queue(ctx, textRequests...); err != nil {
+		return fmt.Errorf("failed to insert text: %w", err)
+	}
+	b.AddSpeakerNotes(slide.Notes)
+
+	return nil
+}
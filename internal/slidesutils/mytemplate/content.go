@@ -6,21 +6,25 @@ import (
 
 	"github.com/owulveryck/gptslideshow/internal/slidesutils"
 	"github.com/owulveryck/gptslideshow/internal/structure"
-	slides "google.golang.org/api/slides/v1"
 )
 
 // CreateSlideTitleSubtitleBody creates a new slide with a title, subtitle, and body content.
 // It uses the predefined layout for title, subtitle, and body.
 //
+// The body is fitted in the BODY placeholder, from its frame and the font size of the template (see
+// slidesutils.FitFontSize): a body overflowing it is shown at a smaller font size. A body too long for a single slide
+// is split beforehand into continuation slides (see BodyBox and slidesutils.SplitSlide), so that the slides of the
+// deck follow the slides of the presentation one to one.
+//
 // Parameters:
 //   - ctx: A context to manage request lifetime.
-//   - slide: A structure containing slide information such as title, subtitle, and body.
+//   - slide: A structure containing slide information such as title, subtitle, body, and speaker notes.
 //
 // Returns:
 //   - error: An error if the slide creation or text insertion fails.
 func (b *Builder) CreateSlideTitleSubtitleBody(ctx context.Context, slide structure.Slide) error {
 	// Use the CreateNewSlide method to create a new slide with the title, subtitle, and body layout.
-	if err := b.CreateNewSlide(ctx, TitleSubtitleBody); err != nil {
+	if err := b.CreateNewSlide(ctx, b.Layouts[RoleContent]); err != nil {
 		return fmt.Errorf("failed to create slide with title, subtitle, and body: %w", err)
 	}
 
@@ -30,61 +34,60 @@ func (b *Builder) CreateSlideTitleSubtitleBody(ctx context.Context, slide struct
 	}
 
 	// Find placeholders for title, subtitle, and body in the newly created slide.
-	var titlePlaceholderID, subtitlePlaceholderID, bodyPlaceholderID string
-	for _, element := range b.CurrentSlide.PageElements {
-		if element.Shape != nil && element.Shape.Placeholder != nil {
-			switch element.Shape.Placeholder.Type {
-			case "TITLE":
-				titlePlaceholderID = element.ObjectId
-			case "SUBTITLE":
-				subtitlePlaceholderID = element.ObjectId
-			case "BODY":
-				bodyPlaceholderID = element.ObjectId
-			}
-		}
-	}
-
-	// Check if all placeholders were found.
-	if titlePlaceholderID == "" || bodyPlaceholderID == "" || subtitlePlaceholderID == "" {
-		return fmt.Errorf("failed to find placeholders on the new slide")
+	title, subtitle, body := b.slidePlaceholders()
+	if body == nil {
+		return fmt.Errorf("the content layout has no BODY placeholder for the body of the slide %q", slide.Title)
 	}
 
-	formattedBody := slidesutils.Format(slide.Body, bodyPlaceholderID)
 	// Prepare text requests to insert the title, subtitle, and body content.
-	textRequests := []*slides.Request{
-		{
-			InsertText: &slides.InsertTextRequest{
-				ObjectId:       titlePlaceholderID,
-				InsertionIndex: 0,
-				Text:           slide.Title,
-			},
-		},
-		{
-			InsertText: &slides.InsertTextRequest{
-				ObjectId:       subtitlePlaceholderID,
-				InsertionIndex: 0,
-				Text:           slide.Subtitle,
-			},
-		},
-		/*
-			{
-				InsertText: &slides.InsertTextRequest{
-					ObjectId:       bodyPlaceholderID,
-					InsertionIndex: 0,
-					Text:           slide.Body,
-				},
-			},
-		*/
-	}
-
+	textRequests := append(insertText(title, slide.Title), insertText(subtitle, slide.Subtitle)...)
+	formattedBody := slidesutils.Format(slide.Body, body.ObjectId)
 	textRequests = append(textRequests, formattedBody...)
+	if len(formattedBody) > 0 {
+		// The body is shrunk once the size of its placeholder is known
+		nominal := slidesutils.PlaceholderFontSize(b.Presentation, body.Shape.Placeholder.ParentObjectId)
+		if nominal == 0 {
+			nominal = slidesutils.DefaultFontSize
+		}
+		size := slidesutils.FitFontSize(slide.Body, slidesutils.TextBox{
+			Frame:    slidesutils.ElementFrame(body, slidesutils.DefaultBodyFrame),
+			FontSize: nominal,
+		})
+		if size < nominal {
+			textRequests = append(textRequests, slidesutils.FontSizeRequest(body.ObjectId, size))
+		}
+	}
 
-	// Execute the batch update request to insert text into the placeholders.
-	if _, err := b.Srv.Presentations.BatchUpdate(b.Presentation.PresentationId, &slides.BatchUpdatePresentationRequest{
-		Requests: textRequests,
-	}).Context(ctx).Do(); err != nil {
+	// Queue the requests inserting text into the placeholders.
+	if err := b.Queue(ctx, textRequests...); err != nil {
 		return fmt.Errorf("failed to insert text: %w", err)
 	}
+	b.AddSpeakerNotes(slide.Notes)
 
 	return nil
 }
+
+// BodyBox returns the text box of the BODY placeholder of the content layout: its frame and the font size of the
+// template, or the default ones (see slidesutils.DefaultBodyFrame and slidesutils.DefaultFontSize).
+//
+// Returns:
+//   - slidesutils.TextBox: The text box the bodies of the content slides are fitted in.
+func (b *Builder) BodyBox() slidesutils.TextBox {
+	box := slidesutils.TextBox{Frame: slidesutils.DefaultBodyFrame, FontSize: slidesutils.DefaultFontSize}
+	for _, layout := range b.Presentation.Layouts {
+		if layout.ObjectId != b.Layouts[RoleContent] {
+			continue
+		}
+		for _, element := range layout.PageElements {
+			if element.Shape == nil || element.Shape.Placeholder == nil || element.Shape.Placeholder.Type != "BODY" {
+				continue
+			}
+			box.Frame = slidesutils.ElementFrame(element, slidesutils.DefaultBodyFrame)
+			if size := slidesutils.PlaceholderFontSize(b.Presentation, element.ObjectId); size > 0 {
+				box.FontSize = size
+			}
+			return box
+		}
+	}
+	return box
+}
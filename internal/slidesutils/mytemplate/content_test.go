@@ -0,0 +1,131 @@
+package mytemplate
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/option"
+	slides "google.golang.org/api/slides/v1"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+func TestEmptySubtitle(t *testing.T) {
+	slide := structure.Slide{Title: "Budget"}
+	tests := []struct {
+		name   string
+		create func(ctx context.Context, b *Builder) error
+		texts  []string
+	}{
+		{"content", func(ctx context.Context, b *Builder) error {
+			slide := slide
+			slide.Body = "body"
+			return b.CreateSlideTitleSubtitleBody(ctx, slide)
+		}, []string{"Budget", "body\n"}},
+		{"code", func(ctx context.Context, b *Builder) error {
+			slide := slide
+			slide.Code = "```go\nx := 1\n```"
+			return b.CreateCodeSlide(ctx, slide)
+		}, []string{"Budget", "x := 1"}},
+		{"table", func(ctx context.Context, b *Builder) error {
+			slide := slide
+			slide.Table = structure.Table{Header: []string{"Q1"}}
+			return b.CreateTableSlide(ctx, slide)
+		}, []string{"Budget", "Q1"}},
+		{"chart", func(ctx context.Context, b *Builder) error {
+			return b.CreateChartSlide(ctx, slide, "https://example.com/chart.png")
+		}, []string{"Budget"}},
+		{"cover", func(ctx context.Context, b *Builder) error {
+			return b.CreateCover(ctx, "Budget", "")
+		}, []string{"Budget", "date", "gptSlideShow"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := &fakeSlides{}
+			server := httptest.NewServer(f)
+			defer server.Close()
+			srv, err := slides.NewService(ctx, option.WithEndpoint(server.URL), option.WithoutAuthentication())
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := NewBuilder(ctx, srv, "deck", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.create(ctx, b); err != nil {
+				t.Fatal(err)
+			}
+			if err := b.Flush(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if len(f.texts) != len(tt.texts) || f.texts[0] != "Budget" {
+				t.Errorf("got the texts %q, want %q", strings.Join(f.texts, "|"), strings.Join(tt.texts, "|"))
+			}
+			for _, text := range f.texts {
+				if text == "" {
+					t.Errorf("got an empty text in %q", strings.Join(f.texts, "|"))
+				}
+			}
+		})
+	}
+}
+
+func TestStandardLayouts(t *testing.T) {
+	ctx := context.Background()
+	named := func(page *slides.Page, name string) *slides.Page {
+		page.LayoutProperties = &slides.LayoutProperties{Name: name}
+		return page
+	}
+	// The placeholders of the predefined layouts of Google Slides
+	f := &fakeSlides{layouts: []*slides.Page{
+		named(fakeLayout("p1", "CENTERED_TITLE", "SUBTITLE"), "TITLE"),
+		named(fakeLayout("p2", "TITLE"), "SECTION_HEADER"),
+		named(fakeLayout("p3", "TITLE", "BODY"), "TITLE_AND_BODY"),
+	}}
+	server := httptest.NewServer(f)
+	defer server.Close()
+	srv, err := slides.NewService(ctx, option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewBuilder(ctx, srv, "deck", map[string]string{RoleCover: "TITLE", RoleChapter: "SECTION_HEADER", RoleContent: "TITLE_AND_BODY"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slide := structure.Slide{Title: "Budget", Subtitle: "2024", Body: "body"}
+	steps := []func() error{
+		func() error { return b.CreateCover(ctx, "Deck", "subtitle") },
+		func() error { return b.CreateChapter(ctx, structure.Slide{Title: "Results"}) },
+		func() error { return b.CreateSlideTitleSubtitleBody(ctx, slide) },
+		func() error {
+			slide := slide
+			slide.Code = "```go\nx := 1\n```"
+			return b.CreateCodeSlide(ctx, slide)
+		},
+		func() error {
+			slide := slide
+			slide.Table = structure.Table{Header: []string{"Q1"}}
+			return b.CreateTableSlide(ctx, slide)
+		},
+		func() error { return b.CreateChartSlide(ctx, slide, "https://example.com/chart.png") },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %v: %v", i, err)
+		}
+	}
+	if err := b.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// The subtitles of the content slides and the chapter number have no placeholder
+	want := "Deck|subtitle|Results|Budget|body|Budget|x := 1|Budget|Q1|Budget"
+	if got := strings.Join(f.texts, "|"); got != want {
+		t.Errorf("got the texts %q, want %q", got, want)
+	}
+	if len(f.deleted) != 2 {
+		t.Errorf("got the deleted objects %v, want the bodies of the table and chart slides", f.deleted)
+	}
+}
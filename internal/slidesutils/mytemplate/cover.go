@@ -3,7 +3,6 @@ package mytemplate
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	slides "google.golang.org/api/slides/v1"
@@ -20,7 +19,7 @@ import (
 //   - error: An error if the slide creation or text insertion fails.
 func (b *Builder) CreateCover(ctx context.Context, title, subtitle string) error {
 	// Use the CreateNewSlide method to create a new slide with the chapter layout.
-	if err := b.CreateNewSlide(ctx, CoverLayoutID); err != nil {
+	if err := b.CreateNewSlide(ctx, b.Layouts[RoleCover]); err != nil {
 		return fmt.Errorf("failed to create cover slide: %w", err)
 	}
 
@@ -30,57 +29,32 @@ func (b *Builder) CreateCover(ctx context.Context, title, subtitle string) error
 	}
 
 	// Find placeholders for title and body in the newly created slide.
-	titlesID := make([]string, 0, 3)
-	var bodyPlaceholderID string
+	titles := make([]*slides.PageElement, 0, 3)
+	var subtitlePlaceholder *slides.PageElement
 	for _, element := range b.CurrentSlide.PageElements {
-		log.Println(element.Shape.Placeholder.Type)
 		if element.Shape != nil && element.Shape.Placeholder != nil {
 			switch element.Shape.Placeholder.Type {
-			case "TITLE":
-				titlesID = append(titlesID, element.ObjectId)
+			case "TITLE", "CENTERED_TITLE":
+				titles = append(titles, element)
 			case "SUBTITLE":
-				bodyPlaceholderID = element.ObjectId
+				subtitlePlaceholder = element
 			}
 		}
 	}
-	// Get the current date
-	currentDate := time.Now()
-	// Prepare text requests to insert the chapter title and number.
-	textRequests := []*slides.Request{
-		{
-			InsertText: &slides.InsertTextRequest{
-				ObjectId:       titlesID[0],
-				InsertionIndex: 0,
-				Text:           title,
-			},
-		},
-		{
-			InsertText: &slides.InsertTextRequest{
-				ObjectId:       titlesID[1],
-				InsertionIndex: 0,
-				Text:           currentDate.Format("01/02/2006"),
-			},
-		},
-		{
-			InsertText: &slides.InsertTextRequest{
-				ObjectId:       titlesID[2],
-				InsertionIndex: 0,
-				Text:           "gptSlideShow",
-			},
-		},
-		{
-			InsertText: &slides.InsertTextRequest{
-				ObjectId:       bodyPlaceholderID,
-				InsertionIndex: 0,
-				Text:           subtitle,
-			},
-		},
+	if len(titles) == 0 {
+		return fmt.Errorf("failed to find a title placeholder on the cover slide")
 	}
+	// The title placeholders of the cover hold the title, the current date and the name of the tool, as long as the
+	// layout has them.
+	texts := []string{title, time.Now().Format("01/02/2006"), "gptSlideShow"}
+	var textRequests []*slides.Request
+	for i, placeholder := range titles[:min(len(titles), len(texts))] {
+		textRequests = append(textRequests, insertText(placeholder, texts[i])...)
+	}
+	textRequests = append(textRequests, insertText(subtitlePlaceholder, subtitle)...)
 
-	// Execute the batch update request to insert text into the placeholders.
-	if _, err := b.Srv.Presentations.BatchUpdate(b.Presentation.PresentationId, &slides.BatchUpdatePresentationRequest{
-		Requests: textRequests,
-	}).Context(ctx).Do(); err != nil {
+	// Queue the requests inserting text into the placeholders.
+	if err := b.Queue(ctx, textRequests...); err != nil {
 		return fmt.Errorf("failed to insert text: %w", err)
 	}
 
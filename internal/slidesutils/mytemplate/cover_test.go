@@ -0,0 +1,52 @@
+package mytemplate
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/option"
+	slides "google.golang.org/api/slides/v1"
+)
+
+func TestCreateCover(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout string
+		texts  []string
+	}{
+		{"three titles", RoleCover, []string{"Deck", "date", "gptSlideShow", "subtitle"}},
+		{"single title", RoleContent, []string{"Deck", "subtitle"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := &fakeSlides{}
+			server := httptest.NewServer(f)
+			defer server.Close()
+			srv, err := slides.NewService(ctx, option.WithEndpoint(server.URL), option.WithoutAuthentication())
+			if err != nil {
+				t.Fatal(err)
+			}
+			layouts := map[string]string{}
+			for role, name := range DefaultLayoutNames {
+				layouts[role] = name
+			}
+			layouts[RoleCover] = DefaultLayoutNames[tt.layout]
+			b, err := NewBuilder(ctx, srv, "deck", layouts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := b.CreateCover(ctx, "Deck", "subtitle"); err != nil {
+				t.Fatal(err)
+			}
+			if err := b.Flush(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if len(f.texts) != len(tt.texts) || f.texts[0] != "Deck" || f.texts[len(f.texts)-1] != "subtitle" {
+				t.Errorf("got the texts %q, want %q", strings.Join(f.texts, "|"), strings.Join(tt.texts, "|"))
+			}
+		})
+	}
+}
@@ -12,7 +12,7 @@ import (
 //
 // Parameters:
 //   - ctx: A context to manage request lifetime.
-//   - imageUrl: The URL of the image to be inserted.
+//   - imageUrl: The URL of the image to be inserted; it must remain reachable until the requests are flushed.
 //   - width: The width of the image in EMUs.
 //   - height: The height of the image in EMUs.
 //   - translateX: The X translation of the image in EMUs.
@@ -52,14 +52,8 @@ func (b *Builder) InsertImage(ctx context.Context, imageUrl string, width, heigh
 		},
 	}
 
-	// Create a batch update request
-	batchUpdateRequest := &slides.BatchUpdatePresentationRequest{
-		Requests: []*slides.Request{imageRequest},
-	}
-
-	// Execute the batch update request
-	_, err := b.Srv.Presentations.BatchUpdate(b.Presentation.PresentationId, batchUpdateRequest).Context(ctx).Do()
-	if err != nil {
+	// Queue the request; the image is fetched by the API when the batch is sent
+	if err := b.Queue(ctx, imageRequest); err != nil {
 		return fmt.Errorf("failed to insert image: %w", err)
 	}
 
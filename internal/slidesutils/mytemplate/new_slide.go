@@ -7,73 +7,114 @@ import (
 	slides "google.golang.org/api/slides/v1"
 )
 
-// CreateNewSlide creates a new slide in the presentation using the specified layout ID.
-// It updates the Builder's CurrentSlide to reference the newly created slide.
+// CreateNewSlide queues the creation of a new slide in the presentation using the specified layout ID.
+// It updates the Builder's CurrentSlide to reference the new slide.
+//
+// The object IDs of the slide and of its placeholders are assigned by the Builder (through placeholderIdMappings),
+// so that the text can be inserted in the same batch without reading the presentation back.
+// CurrentSlide is therefore a local description of the slide: its ID and its placeholders, each one with the type,
+// the index, the parent object ID, the size and the transform of the placeholder of the layout it is created from.
+// The slide number placeholders are not instantiated by the API and are not mapped.
 //
 // Parameters:
 //   - ctx: A context to manage request lifetime.
 //   - layoutId: The ID of the layout to be used for the new slide.
 //
 // Returns:
-//   - error: An error if the slide creation fails or if the new slide cannot be matched
-//     to the presentation's slide list.
+//   - error: An error if the layout is not part of the presentation or if a batch had to be sent and failed.
 func (b *Builder) CreateNewSlide(ctx context.Context, layoutId string) error {
-	// Construct the request for creating a new slide using the specified layout ID.
-	createSlideRequests := []*slides.Request{
-		{
-			CreateSlide: &slides.CreateSlideRequest{
-				SlideLayoutReference: &slides.LayoutReference{
-					LayoutId: layoutId,
-				},
-			},
-		},
+	var layout *slides.Page
+	for _, l := range b.Presentation.Layouts {
+		if l.ObjectId == layoutId {
+			layout = l
+			break
+		}
 	}
-
-	// Execute the batch update request to create a new slide.
-	createSlideResponse, err := b.Srv.Presentations.BatchUpdate(b.Presentation.PresentationId, &slides.BatchUpdatePresentationRequest{
-		Requests: createSlideRequests,
-	}).Context(ctx).Do()
-	if err != nil {
-		return fmt.Errorf("failed to create slide: %w", err)
+	if layout == nil {
+		return fmt.Errorf("failed to create slide: layout %q not found in the presentation", layoutId)
 	}
 
-	// Extract the new slide ID from the response.
-	var newSlideID string
-	for _, reply := range createSlideResponse.Replies {
-		if reply.CreateSlide != nil {
-			newSlideID = reply.CreateSlide.ObjectId
-			break
+	page := &slides.Page{ObjectId: b.NewObjectID()}
+	var mappings []*slides.LayoutPlaceholderIdMapping
+	for _, element := range layout.PageElements {
+		if element.Shape == nil || element.Shape.Placeholder == nil || element.Shape.Placeholder.Type == "SLIDE_NUMBER" {
+			continue
 		}
+		placeholder := element.Shape.Placeholder
+		objectID := b.NewObjectID()
+		mappings = append(mappings, &slides.LayoutPlaceholderIdMapping{
+			LayoutPlaceholder: &slides.Placeholder{
+				Type:  placeholder.Type,
+				Index: placeholder.Index,
+			},
+			ObjectId: objectID,
+		})
+		page.PageElements = append(page.PageElements, &slides.PageElement{
+			ObjectId:  objectID,
+			Size:      element.Size,
+			Transform: element.Transform,
+			Shape: &slides.Shape{
+				Placeholder: &slides.Placeholder{
+					Type:           placeholder.Type,
+					Index:          placeholder.Index,
+					ParentObjectId: element.ObjectId,
+				},
+			},
+		})
 	}
 
-	// Check if a new slide ID was retrieved; return an error if not.
-	if newSlideID == "" {
-		return fmt.Errorf("failed to retrieve new slide ID")
+	// Construct the request for creating a new slide using the specified layout ID.
+	createSlideRequest := &slides.Request{
+		CreateSlide: &slides.CreateSlideRequest{
+			ObjectId: page.ObjectId,
+			SlideLayoutReference: &slides.LayoutReference{
+				LayoutId: layoutId,
+			},
+			PlaceholderIdMappings: mappings,
+		},
 	}
-
-	// Refresh the presentation to include the newly created slide.
-	presentation, err := b.Srv.Presentations.Get(b.Presentation.PresentationId).Context(ctx).Do()
-	if err != nil {
-		return fmt.Errorf("failed to retrieve updated presentation: %w", err)
+	if err := b.Queue(ctx, createSlideRequest); err != nil {
+		return fmt.Errorf("failed to create slide: %w", err)
 	}
 
-	// Update the current slide reference in the Builder to the newly created slide.
-	var found bool
-	for _, slide := range presentation.Slides {
-		if slide.ObjectId == newSlideID {
-			b.CurrentSlide = slide
-			found = true
-			break
+	// Update the current slide reference in the Builder to the new slide.
+	b.CurrentSlide = page
+	return nil
+}
+
+// slidePlaceholders returns the TITLE, SUBTITLE and BODY placeholders of the current slide; the ones its layout does not
+// have are nil, such as the SUBTITLE of the standard TITLE_AND_BODY layout or the BODY of the SECTION_HEADER layout.
+func (b *Builder) slidePlaceholders() (title, subtitle, body *slides.PageElement) {
+	for _, element := range b.CurrentSlide.PageElements {
+		if element.Shape != nil && element.Shape.Placeholder != nil {
+			switch element.Shape.Placeholder.Type {
+			case "TITLE", "CENTERED_TITLE":
+				if title == nil {
+					title = element
+				}
+			case "SUBTITLE":
+				subtitle = element
+			case "BODY":
+				body = element
+			}
 		}
 	}
+	return title, subtitle, body
+}
 
-	// Ensure the new slide was matched to an existing slide in the presentation.
-	if !found {
-		return fmt.Errorf("new slide ID %q not found in the updated presentation", newSlideID)
+// insertText returns the request inserting the text in the placeholder, none if the text is empty or if the layout
+// has no such placeholder (nil), so that the placeholder keeps showing nothing rather than receiving an empty insertion.
+func insertText(placeholder *slides.PageElement, text string) []*slides.Request {
+	if placeholder == nil || text == "" {
+		return nil
+	}
+	return []*slides.Request{
+		{
+			InsertText: &slides.InsertTextRequest{
+				ObjectId:       placeholder.ObjectId,
+				InsertionIndex: 0,
+				Text:           text,
+			},
+		},
 	}
-
-	// Update the Builder's Presentation to reflect the latest state.
-	b.Presentation = presentation
-
-	return nil
 }
@@ -0,0 +1,83 @@
+package mytemplate
+
+import (
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+	slides "google.golang.org/api/slides/v1"
+)
+
+// Read returns the structure of a presentation built by the Builder, such as to lint it.
+//
+// The first slide is the cover, giving the title and the subtitle of the presentation; the slides made from the chapter
+// layout are chapters, titled by their TITLE placeholder; the other slides give their title, subtitle and body from
+// their TITLE, SUBTITLE and BODY placeholders (see slidesutils.ShapeText), and their speaker notes.
+// The tables, the images and the formatting of the text are not read.
+//
+// Parameters:
+//   - presentation: The presentation, as returned by the Google Slides API.
+//   - layouts: The layout object ID of each role, as resolved by NewBuilder.
+//
+// Returns:
+//   - *structure.Presentation: The structure of the presentation.
+func Read(presentation *slides.Presentation, layouts map[string]string) *structure.Presentation {
+	p := &structure.Presentation{}
+	for i, page := range presentation.Slides {
+		slide := ReadSlide(page, layouts)
+		if i == 0 {
+			p.Title, p.Subtitle = slide.Title, slide.Subtitle
+			continue
+		}
+		p.Slides = append(p.Slides, slide)
+	}
+	return p
+}
+
+// ReadSlide returns the title, the subtitle, the body and the speaker notes of a slide of a presentation built by the
+// Builder (see Read); a slide made from the chapter layout is a chapter, without body.
+//
+// Parameters:
+//   - page: The slide, as returned by the Google Slides API.
+//   - layouts: The layout object ID of each role, as resolved by NewBuilder.
+//
+// Returns:
+//   - structure.Slide: The text of the slide.
+func ReadSlide(page *slides.Page, layouts map[string]string) structure.Slide {
+	var slide structure.Slide
+	for _, element := range page.PageElements {
+		if element.Shape == nil || element.Shape.Placeholder == nil {
+			continue
+		}
+		switch element.Shape.Placeholder.Type {
+		case "TITLE", "CENTERED_TITLE":
+			if slide.Title == "" {
+				slide.Title = slidesutils.ShapeText(element.Shape)
+			}
+		case "SUBTITLE":
+			slide.Subtitle = slidesutils.ShapeText(element.Shape)
+		case "BODY":
+			slide.Body = slidesutils.ShapeText(element.Shape)
+		}
+	}
+	if page.SlideProperties != nil {
+		slide.Chapter = page.SlideProperties.LayoutObjectId == layouts[RoleChapter]
+		slide.Notes = speakerNotes(page.SlideProperties.NotesPage)
+	}
+	if slide.Chapter {
+		// The body of a chapter is its number
+		slide.Body = ""
+	}
+	return slide
+}
+
+// speakerNotes returns the text of the speaker notes shape of the notes page.
+func speakerNotes(notesPage *slides.Page) string {
+	if notesPage == nil || notesPage.NotesProperties == nil {
+		return ""
+	}
+	for _, element := range notesPage.PageElements {
+		if element.ObjectId == notesPage.NotesProperties.SpeakerNotesObjectId {
+			return slidesutils.ShapeText(element.Shape)
+		}
+	}
+	return ""
+}
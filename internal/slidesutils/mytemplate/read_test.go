@@ -0,0 +1,43 @@
+package mytemplate
+
+import (
+	"testing"
+
+	slides "google.golang.org/api/slides/v1"
+)
+
+func TestRead(t *testing.T) {
+	text := func(s string) *slides.TextContent {
+		return &slides.TextContent{TextElements: []*slides.TextElement{{ParagraphMarker: &slides.ParagraphMarker{}}, {TextRun: &slides.TextRun{Content: s + "\n"}}}}
+	}
+	placeholder := func(kind, s string) *slides.PageElement {
+		return &slides.PageElement{ObjectId: kind + s, Shape: &slides.Shape{Placeholder: &slides.Placeholder{Type: kind}, Text: text(s)}}
+	}
+	page := func(layout string, elements ...*slides.PageElement) *slides.Page {
+		return &slides.Page{
+			PageElements: elements,
+			SlideProperties: &slides.SlideProperties{
+				LayoutObjectId: layout,
+				NotesPage: &slides.Page{
+					NotesProperties: &slides.NotesProperties{SpeakerNotesObjectId: "notes"},
+					PageElements:    []*slides.PageElement{{ObjectId: "notes", Shape: &slides.Shape{Text: text("notes of " + layout)}}},
+				},
+			},
+		}
+	}
+	presentation := &slides.Presentation{Slides: []*slides.Page{
+		page("cover", placeholder("CENTERED_TITLE", "Deck"), placeholder("SUBTITLE", "2024")),
+		page("chapter", placeholder("TITLE", "Part one"), placeholder("BODY", "0")),
+		page("content", placeholder("TITLE", "Details"), placeholder("SUBTITLE", "More"), placeholder("BODY", "Body")),
+	}}
+	p := Read(presentation, map[string]string{RoleCover: "cover", RoleChapter: "chapter", RoleContent: "content"})
+	if p.Title != "Deck" || p.Subtitle != "2024" || len(p.Slides) != 2 {
+		t.Fatalf("Read() = %+v, want the cover and 2 slides", p)
+	}
+	if s := p.Slides[0]; !s.Chapter || s.Title != "Part one" || s.Body != "" {
+		t.Errorf("chapter = %+v", s)
+	}
+	if s := p.Slides[1]; s.Chapter || s.Title != "Details" || s.Subtitle != "More" || s.Body != "Body" || s.Notes != "notes of content" {
+		t.Errorf("content slide = %+v", s)
+	}
+}
@@ -0,0 +1,146 @@
+package mytemplate
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+	slides "google.golang.org/api/slides/v1"
+)
+
+// FindSlide returns a slide of the presentation, by its number if target is a number (the first slide, the cover,
+// being 1), or else by its object ID.
+//
+// Parameters:
+//   - target: The number or the object ID of the slide.
+//
+// Returns:
+//   - *slides.Page: The slide.
+//   - int: The number of the slide.
+//   - error: An error if the presentation has no such slide.
+func (b *Builder) FindSlide(target string) (*slides.Page, int, error) {
+	if n, err := strconv.Atoi(target); err == nil {
+		if n < 1 || n > len(b.Presentation.Slides) {
+			return nil, 0, fmt.Errorf("no slide %d: the presentation has %d slides", n, len(b.Presentation.Slides))
+		}
+		return b.Presentation.Slides[n-1], n, nil
+	}
+	for i, page := range b.Presentation.Slides {
+		if page.ObjectId == target {
+			return page, i + 1, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("no slide with the object ID %q in the presentation", target)
+}
+
+// ReplaceSlideText replaces in place the text of the placeholders of a slide of the presentation: the title, the
+// subtitle and, unless the slide is a chapter (see ReadSlide), the body, formatted as CreateSlideTitleSubtitleBody does
+// and shrunk to fit its placeholder (see slidesutils.FitFontSize). The speaker notes are replaced as well.
+// The other elements of the slide, such as the tables and the images, and the other slides are left untouched.
+// The requests are sent at once.
+//
+// Parameters:
+//   - ctx: A context to manage request lifetime.
+//   - page: The slide, as returned by the API.
+//   - slide: The new text of the slide.
+//
+// Returns:
+//   - error: An error if the slide has no title placeholder or if the update fails.
+func (b *Builder) ReplaceSlideText(ctx context.Context, page *slides.Page, slide structure.Slide) error {
+	chapter := page.SlideProperties != nil && page.SlideProperties.LayoutObjectId == b.Layouts[RoleChapter]
+	titled := false
+	for _, element := range page.PageElements {
+		if element.Shape == nil || element.Shape.Placeholder == nil {
+			continue
+		}
+		var requests []*slides.Request
+		switch element.Shape.Placeholder.Type {
+		case "TITLE", "CENTERED_TITLE":
+			if titled {
+				continue
+			}
+			titled = true
+			requests = replaceText(element, slide.Title)
+		case "SUBTITLE":
+			requests = replaceText(element, slide.Subtitle)
+		case "BODY":
+			if chapter {
+				continue
+			}
+			formattedBody := slidesutils.Format(slide.Body, element.ObjectId)
+			requests = append(replaceText(element, ""), formattedBody...)
+			if len(formattedBody) == 0 {
+				break
+			}
+			nominal := slidesutils.PlaceholderFontSize(b.Presentation, element.Shape.Placeholder.ParentObjectId)
+			if nominal == 0 {
+				nominal = slidesutils.DefaultFontSize
+			}
+			size := slidesutils.FitFontSize(slide.Body, slidesutils.TextBox{
+				Frame:    slidesutils.ElementFrame(element, slidesutils.DefaultBodyFrame),
+				FontSize: nominal,
+			})
+			if size < nominal {
+				requests = append(requests, slidesutils.FontSizeRequest(element.ObjectId, size))
+			} else {
+				// The new text may inherit the font size of a previous shrink: back to the size of the placeholder
+				requests = append(requests, &slides.Request{
+					UpdateTextStyle: &slides.UpdateTextStyleRequest{
+						ObjectId:  element.ObjectId,
+						TextRange: &slides.Range{Type: "ALL"},
+						Style:     &slides.TextStyle{},
+						Fields:    "fontSize",
+					},
+				})
+			}
+		default:
+			continue
+		}
+		if err := b.Queue(ctx, requests...); err != nil {
+			return fmt.Errorf("failed to replace the text of the slide: %w", err)
+		}
+	}
+	if !titled {
+		return fmt.Errorf("the slide %v has no title placeholder", page.ObjectId)
+	}
+	if page.SlideProperties != nil && page.SlideProperties.NotesPage != nil {
+		notesPage := page.SlideProperties.NotesPage
+		for _, element := range notesPage.PageElements {
+			if notesPage.NotesProperties != nil && element.ObjectId == notesPage.NotesProperties.SpeakerNotesObjectId {
+				if err := b.Queue(ctx, replaceText(element, "")...); err != nil {
+					return fmt.Errorf("failed to replace the speaker notes: %w", err)
+				}
+			}
+		}
+		if err := b.Queue(ctx, slidesutils.SpeakerNotes(page, slide.Notes)...); err != nil {
+			return fmt.Errorf("failed to replace the speaker notes: %w", err)
+		}
+	}
+	return b.Flush(ctx)
+}
+
+// replaceText returns the requests replacing the text of the shape: deleting its text, if any, and inserting the text,
+// if not empty.
+func replaceText(element *slides.PageElement, text string) []*slides.Request {
+	var requests []*slides.Request
+	if slidesutils.ShapeText(element.Shape) != "" {
+		requests = append(requests, &slides.Request{
+			DeleteText: &slides.DeleteTextRequest{
+				ObjectId:  element.ObjectId,
+				TextRange: &slides.Range{Type: "ALL"},
+			},
+		})
+	}
+	if text != "" {
+		requests = append(requests, &slides.Request{
+			InsertText: &slides.InsertTextRequest{
+				ObjectId:       element.ObjectId,
+				InsertionIndex: 0,
+				Text:           text,
+			},
+		})
+	}
+	return requests
+}
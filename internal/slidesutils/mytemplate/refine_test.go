@@ -0,0 +1,91 @@
+package mytemplate
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/option"
+	slides "google.golang.org/api/slides/v1"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+func TestFindSlide(t *testing.T) {
+	b := &Builder{Presentation: &slides.Presentation{Slides: []*slides.Page{{ObjectId: "cover"}, {ObjectId: "gss1_1"}, {ObjectId: "gss1_2"}}}}
+	tests := []struct {
+		target  string
+		want    string
+		number  int
+		wantErr bool
+	}{
+		{"1", "cover", 1, false},
+		{"3", "gss1_2", 3, false},
+		{"gss1_1", "gss1_1", 2, false},
+		{"0", "", 0, true},
+		{"4", "", 0, true},
+		{"unknown", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			page, number, err := b.FindSlide(tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindSlide() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (page.ObjectId != tt.want || number != tt.number) {
+				t.Errorf("FindSlide() = %v, %v, want %v, %v", page.ObjectId, number, tt.want, tt.number)
+			}
+		})
+	}
+}
+
+func TestReplaceSlideText(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeSlides{}
+	server := httptest.NewServer(f)
+	defer server.Close()
+	srv, err := slides.NewService(ctx, option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := &slides.TextContent{TextElements: []*slides.TextElement{{TextRun: &slides.TextRun{Content: "old\n"}}}}
+	page := &slides.Page{
+		ObjectId: "slide",
+		PageElements: []*slides.PageElement{
+			{ObjectId: "title", Shape: &slides.Shape{Placeholder: &slides.Placeholder{Type: "TITLE"}, Text: text}},
+			{ObjectId: "subtitle", Shape: &slides.Shape{Placeholder: &slides.Placeholder{Type: "SUBTITLE"}}},
+			{ObjectId: "body", Shape: &slides.Shape{Placeholder: &slides.Placeholder{Type: "BODY"}, Text: text}},
+			{ObjectId: "picture", Shape: &slides.Shape{Text: text}},
+		},
+		SlideProperties: &slides.SlideProperties{
+			LayoutObjectId: "content",
+			NotesPage: &slides.Page{
+				NotesProperties: &slides.NotesProperties{SpeakerNotesObjectId: "notes"},
+				PageElements:    []*slides.PageElement{{ObjectId: "notes", Shape: &slides.Shape{Text: text}}},
+			},
+		},
+	}
+	b := &Builder{
+		Srv:          srv,
+		Presentation: &slides.Presentation{PresentationId: "deck", Slides: []*slides.Page{page}},
+		Layouts:      map[string]string{RoleChapter: "chapter", RoleContent: "content"},
+	}
+	if err := b.ReplaceSlideText(ctx, page, structure.Slide{Title: "New title", Subtitle: "New subtitle", Body: "- **new** body", Notes: "new notes"}); err != nil {
+		t.Fatal(err)
+	}
+	// The subtitle is empty: its text is not deleted
+	if got := strings.Join(f.cleared, ","); got != "title,body,notes" {
+		t.Errorf("got the text of %v deleted, want title,body,notes", got)
+	}
+	if got := strings.Join(f.texts, "|"); got != "New title|New subtitle|new body|new notes" {
+		t.Errorf("got the texts %q inserted", got)
+	}
+	if len(f.batches) != 1 {
+		t.Errorf("got %v BatchUpdate, want a single one", f.batches)
+	}
+
+	if err := b.ReplaceSlideText(ctx, &slides.Page{ObjectId: "image"}, structure.Slide{Title: "title"}); err == nil {
+		t.Error("ReplaceSlideText() of a slide without title succeeded")
+	}
+}
@@ -9,53 +9,99 @@ package mytemplate
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
 	slides "google.golang.org/api/slides/v1"
 )
 
 // Builder encapsulates state and methods for working with a Google Slides presentation.
 // It maintains the current chapter, the current slide, and the overall presentation object.
+//
+// The requests are not sent as the slides are created: they are queued and sent in batches (see Queue and Flush).
 type Builder struct {
 	Srv            *slides.Service      // The Google Slides API service client.
 	CurrentChapter int                  // Tracks the current chapter number in the presentation.
 	CurrentSlide   *slides.Page         // Points to the current slide being manipulated.
 	Presentation   *slides.Presentation // The full presentation being managed.
+	Layouts        map[string]string    // The layout object ID to use for each role.
+	BatchSize      int                  // The maximum number of requests per BatchUpdate; 0 means no limit.
 	slideNumber    int
+	requests       []*slides.Request // The pending requests.
+	notes          map[string]string // The pending speaker notes, indexed by slide object ID.
+	idPrefix       string
+	lastID         int
 }
 
 const (
-	// ChapterLayoutId represents the layout ID for a chapter slide in the presentation.
-	ChapterLayoutId = "g2ac55f3490c_0_1010"
-
-	// TitleSubtitleBody represents the layout ID for a slide with a title, subtitle, and body content.
-	TitleSubtitleBody = "g2ac55f3490c_0_1006"
-	// CoverLayout ...
-	CoverLayoutID = "g2ac55f3490c_0_1073"
+	// RoleCover is the role of the layout used for the cover slide.
+	RoleCover = "cover"
+	// RoleChapter is the role of the layout used for a chapter slide.
+	RoleChapter = "chapter"
+	// RoleContent is the role of the layout used for a slide with a title, subtitle, and body content.
+	RoleContent = "content"
 )
 
+// DefaultLayoutNames maps each role to the layout of the original template.
+// The template is referenced by object IDs; any other template should map the roles to its layout names.
+var DefaultLayoutNames = map[string]string{
+	RoleCover:   "g2ac55f3490c_0_1073",
+	RoleChapter: "g2ac55f3490c_0_1010",
+	RoleContent: "g2ac55f3490c_0_1006",
+}
+
 // NewBuilder initializes a Builder instance for managing a Google Slides presentation.
 //
 // This function retrieves the presentation with the specified ID using the provided Google Slides API service client.
+// The layouts are discovered from the presentation by their name (see slidesutils.FindLayout).
 // The resulting Builder is used for creating and managing slides programmatically.
 //
 // Parameters:
 //   - ctx: A context to manage request lifetime.
 //   - srv: A Google Slides API service client.
 //   - presentationId: The ID of the Google Slides presentation to manage.
+//   - layoutNames: The layout name to use for each role (RoleCover, RoleChapter and RoleContent); the roles it does not
+//     give, or a nil map, use DefaultLayoutNames.
 //
 // Returns:
-//   - *Builder: A new Builder instance for the specified presentation filled with the Srv and Presentation field.
-//   - error: An error if the presentation could not be retrieved or if the API call fails.
-func NewBuilder(ctx context.Context, srv *slides.Service, presentationId string) (*Builder, error) {
+//   - *Builder: A new Builder instance for the specified presentation filled with the Srv, Presentation and Layouts fields,
+//     sending its requests in batches of DefaultBatchSize.
+//   - error: An error if the presentation could not be retrieved, if the API call fails or if a role cannot be matched to a layout.
+func NewBuilder(ctx context.Context, srv *slides.Service, presentationId string, layoutNames map[string]string) (*Builder, error) {
 	presentation, err := srv.Presentations.Get(presentationId).Context(ctx).Do()
 	if err != nil {
 		return nil, err
 	}
+	layouts, err := resolveLayouts(presentation, layoutNames)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Builder{
 		Srv:            srv,
 		CurrentChapter: 0,
 		CurrentSlide:   nil,
 		Presentation:   presentation,
+		Layouts:        layouts,
+		BatchSize:      DefaultBatchSize,
+		idPrefix:       newIDPrefix(),
 	}, nil
 }
+
+// resolveLayouts finds the layout object ID of every role required by the Builder; the roles that layoutNames does not
+// give use DefaultLayoutNames.
+func resolveLayouts(presentation *slides.Presentation, layoutNames map[string]string) (map[string]string, error) {
+	layouts := make(map[string]string, len(DefaultLayoutNames))
+	for _, role := range []string{RoleCover, RoleChapter, RoleContent} {
+		name, ok := layoutNames[role]
+		if !ok || name == "" {
+			name = DefaultLayoutNames[role]
+		}
+		id, err := slidesutils.FindLayout(presentation, name)
+		if err != nil {
+			return nil, fmt.Errorf("cannot find the layout for role %q: %w", role, err)
+		}
+		layouts[role] = id
+	}
+	return layouts, nil
+}
@@ -0,0 +1,53 @@
+package mytemplate
+
+import (
+	"testing"
+
+	slides "google.golang.org/api/slides/v1"
+)
+
+func TestResolveLayouts(t *testing.T) {
+	layout := func(id, name string) *slides.Page {
+		return &slides.Page{ObjectId: id, LayoutProperties: &slides.LayoutProperties{Name: name, DisplayName: name}}
+	}
+	presentation := &slides.Presentation{Layouts: []*slides.Page{
+		layout(DefaultLayoutNames[RoleCover], "COVER"),
+		layout(DefaultLayoutNames[RoleChapter], "CHAPTER"),
+		layout(DefaultLayoutNames[RoleContent], "CONTENT"),
+		layout("title", "TITLE"),
+	}}
+	tests := []struct {
+		name    string
+		names   map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"default", nil, DefaultLayoutNames, false},
+		{
+			"partial",
+			map[string]string{RoleCover: "title"},
+			map[string]string{RoleCover: "title", RoleChapter: DefaultLayoutNames[RoleChapter], RoleContent: DefaultLayoutNames[RoleContent]},
+			false,
+		},
+		{
+			"by name",
+			map[string]string{RoleCover: "Title", RoleChapter: "chapter", RoleContent: "CONTENT"},
+			map[string]string{RoleCover: "title", RoleChapter: DefaultLayoutNames[RoleChapter], RoleContent: DefaultLayoutNames[RoleContent]},
+			false,
+		},
+		{"unknown", map[string]string{RoleContent: "BLANK"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveLayouts(presentation, tt.names)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveLayouts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for role, id := range tt.want {
+				if got[role] != id {
+					t.Errorf("resolveLayouts()[%v] = %q, want %q", role, got[role], id)
+				}
+			}
+		})
+	}
+}
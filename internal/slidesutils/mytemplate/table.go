@@ -0,0 +1,54 @@
+package mytemplate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+	slides "google.golang.org/api/slides/v1"
+)
+
+// CreateTableSlide creates a new slide with a title, subtitle, and the table of the slide.
+// It uses the content layout: the table takes the place of the body placeholder, which is removed, or the
+// default body frame if the layout has no BODY placeholder.
+//
+// Parameters:
+//   - ctx: A context to manage request lifetime.
+//   - slide: A structure containing slide information such as title, subtitle, table, and speaker notes.
+//
+// Returns:
+//   - error: An error if the slide creation or the creation of the table fails.
+func (b *Builder) CreateTableSlide(ctx context.Context, slide structure.Slide) error {
+	if err := b.CreateNewSlide(ctx, b.Layouts[RoleContent]); err != nil {
+		return fmt.Errorf("failed to create table slide: %w", err)
+	}
+
+	// Ensure the current slide is set after creation.
+	if b.CurrentSlide == nil {
+		return fmt.Errorf("current slide is not set after creation")
+	}
+
+	// Find placeholders for title, subtitle, and body in the newly created slide.
+	title, subtitle, body := b.slidePlaceholders()
+
+	requests := append(insertText(title, slide.Title), insertText(subtitle, slide.Subtitle)...)
+	frame := slidesutils.DefaultBodyFrame
+	if body != nil {
+		frame = slidesutils.ElementFrame(body, slidesutils.DefaultBodyFrame)
+		requests = append(requests, &slides.Request{
+			DeleteObject: &slides.DeleteObjectRequest{
+				ObjectId: body.ObjectId,
+			},
+		})
+	}
+	requests = append(requests, slidesutils.FormatTable(slide.Table, b.CurrentSlide.ObjectId, b.NewObjectID(), frame)...)
+
+	// Queue the requests inserting the text and the table.
+	if err := b.Queue(ctx, requests...); err != nil {
+		return fmt.Errorf("failed to insert table: %w", err)
+	}
+	b.AddSpeakerNotes(slide.Notes)
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package slidesutils
+
+import (
+	slides "google.golang.org/api/slides/v1"
+)
+
+// SpeakerNotes returns the requests inserting the notes in the speaker notes shape of the page.
+// The shape is created by the API on the first insertion if it does not exist yet.
+// It returns nil if the notes are empty or if the page has no notes page.
+func SpeakerNotes(page *slides.Page, notes string) []*slides.Request {
+	if notes == "" || page == nil || page.SlideProperties == nil || page.SlideProperties.NotesPage == nil {
+		return nil
+	}
+	notesPage := page.SlideProperties.NotesPage
+	if notesPage.NotesProperties == nil || notesPage.NotesProperties.SpeakerNotesObjectId == "" {
+		return nil
+	}
+	return []*slides.Request{
+		{
+			InsertText: &slides.InsertTextRequest{
+				ObjectId:       notesPage.NotesProperties.SpeakerNotesObjectId,
+				InsertionIndex: 0,
+				Text:           notes,
+			},
+		},
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Package pptx implements slidesutils.BuilderInterface by writing a native PowerPoint file (Office Open XML).
+
+It does not need any Google credential nor network access (except to fetch remote images),
+which makes it usable in air-gapped environments and in tests.
+*/
+package pptx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// Builder accumulates the slides of a presentation in memory.
+// The presentation is serialized by Write once all the slides are created.
+type Builder struct {
+	CurrentChapter int       // Tracks the current chapter number in the presentation.
+	Date           time.Time // The date displayed on the cover.
+	slides         []*slide
+	media          []media
+}
+
+// slide is the content of a slide: the XML of its shapes and the relationships to its images.
+type slide struct {
+	shapes strings.Builder
+	images []int // index of the images in the media of the Builder
+	nextID int
+	notes  string   // the speaker notes, written in a notes slide
+	links  []string // the URL of the hyperlinks of the slide
+}
+
+// media is an image embedded in the presentation.
+type media struct {
+	data      []byte
+	extension string
+}
+
+// NewBuilder returns an empty Builder.
+func NewBuilder() *Builder {
+	return &Builder{
+		Date: time.Now(),
+	}
+}
+
+// CreateNewSlide appends a new blank slide to the presentation.
+// The layout is ignored: every slide uses the blank layout and the text is laid out in text boxes.
+func (b *Builder) CreateNewSlide(ctx context.Context, layout string) error {
+	b.slides = append(b.slides, &slide{nextID: 2})
+	return nil
+}
+
+// CreateCover creates the cover slide with the title, the subtitle and the date.
+func (b *Builder) CreateCover(ctx context.Context, title, subtitle string) error {
+	if err := b.CreateNewSlide(ctx, ""); err != nil {
+		return err
+	}
+	s := b.currentSlide()
+	s.addTextBox(685800, 2130425, 7772400, 1470025, "Title", paragraphs(title, 4400, true, "ctr"))
+	s.addTextBox(1371600, 3886200, 6400800, 1752600, "Subtitle", paragraphs(subtitle, 2400, false, "ctr"))
+	s.addTextBox(1371600, 5943600, 6400800, 457200, "Date", paragraphs(b.Date.Format("01/02/2006")+" - gptSlideShow", 1400, false, "ctr"))
+	return nil
+}
+
+// CreateChapter creates a chapter slide with the chapter number and title.
+func (b *Builder) CreateChapter(ctx context.Context, slide structure.Slide) error {
+	if err := b.CreateNewSlide(ctx, ""); err != nil {
+		return err
+	}
+	s := b.currentSlide()
+	s.addTextBox(457200, 457200, 8229600, 914400, "Chapter number", paragraphs(strconv.Itoa(b.CurrentChapter), 2800, true, "l"))
+	s.addTextBox(457200, 4800600, 8229600, 1143000, "Title", paragraphs(slide.Title, 4000, true, "l"))
+	s.notes = slide.Notes
+	b.CurrentChapter++
+	return nil
+}
+
+// CreateSlideTitleSubtitleBody creates a slide with a title, a subtitle and a formatted body.
+func (b *Builder) CreateSlideTitleSubtitleBody(ctx context.Context, slide structure.Slide) error {
+	if err := b.CreateNewSlide(ctx, ""); err != nil {
+		return err
+	}
+	s := b.currentSlide()
+	s.addTextBox(457200, 274638, 8229600, 868362, "Title", paragraphs(slide.Title, 3200, true, "l"))
+	s.addTextBox(457200, 1143000, 8229600, 457200, "Subtitle", paragraphs(slide.Subtitle, 2000, false, "l"))
+	s.addTextBox(457200, 1752600, 8229600, 4648200, "Body", s.bodyParagraphs(slidesutils.Parse(slide.Body), 1600))
+	s.notes = slide.Notes
+	return nil
+}
+
+// CreateCodeSlide creates a slide with a title, a subtitle and the highlighted code block of the slide.
+func (b *Builder) CreateCodeSlide(ctx context.Context, slide structure.Slide) error {
+	if err := b.CreateNewSlide(ctx, ""); err != nil {
+		return err
+	}
+	s := b.currentSlide()
+	s.addTextBox(457200, 274638, 8229600, 868362, "Title", paragraphs(slide.Title, 3200, true, "l"))
+	s.addTextBox(457200, 1143000, 8229600, 457200, "Subtitle", paragraphs(slide.Subtitle, 2000, false, "l"))
+	s.addTextBox(457200, 1752600, 8229600, 4648200, "Code", codeParagraphs(slide.Code, 1400))
+	s.notes = slide.Notes
+	return nil
+}
+
+// CreateTableSlide creates a slide with a title, a subtitle and the table of the slide.
+func (b *Builder) CreateTableSlide(ctx context.Context, slide structure.Slide) error {
+	if err := b.CreateNewSlide(ctx, ""); err != nil {
+		return err
+	}
+	s := b.currentSlide()
+	s.addTextBox(457200, 274638, 8229600, 868362, "Title", paragraphs(slide.Title, 3200, true, "l"))
+	s.addTextBox(457200, 1143000, 8229600, 457200, "Subtitle", paragraphs(slide.Subtitle, 2000, false, "l"))
+	s.addTable(slide.Table, slidesutils.DefaultBodyFrame, 1400)
+	s.notes = slide.Notes
+	return nil
+}
+
+// CreateChartSlide creates a slide with a title, a subtitle and the image of the chart of the slide.
+func (b *Builder) CreateChartSlide(ctx context.Context, slide structure.Slide, imageUrl string) error {
+	if err := b.CreateNewSlide(ctx, ""); err != nil {
+		return err
+	}
+	s := b.currentSlide()
+	s.addTextBox(457200, 274638, 8229600, 868362, "Title", paragraphs(slide.Title, 3200, true, "l"))
+	s.addTextBox(457200, 1143000, 8229600, 457200, "Subtitle", paragraphs(slide.Subtitle, 2000, false, "l"))
+	s.notes = slide.Notes
+	frame := slidesutils.DefaultBodyFrame
+	return b.InsertImage(ctx, imageUrl, frame.Width, frame.Height, frame.TranslateX, frame.TranslateY)
+}
+
+// InsertImage embeds the image in the current slide.
+// The imageUrl is either an http(s) URL or the path of a local file (optionally prefixed by file://).
+func (b *Builder) InsertImage(ctx context.Context, imageUrl string, width, height, translateX, translateY float64) error {
+	s := b.currentSlide()
+	if s == nil {
+		return fmt.Errorf("current slide is not set")
+	}
+	data, err := slidesutils.FetchImage(ctx, imageUrl)
+	if err != nil {
+		return fmt.Errorf("failed to insert image: %w", err)
+	}
+	var extension string
+	switch http.DetectContentType(data) {
+	case "image/png":
+		extension = "png"
+	case "image/jpeg":
+		extension = "jpeg"
+	case "image/gif":
+		extension = "gif"
+	default:
+		return fmt.Errorf("failed to insert image: unsupported format %v", http.DetectContentType(data))
+	}
+	b.media = append(b.media, media{data: data, extension: extension})
+	s.images = append(s.images, len(b.media)-1)
+	// The relationship rId1 is the layout, the images start at rId2
+	s.addPicture(int64(translateX), int64(translateY), int64(width), int64(height), "rId"+strconv.Itoa(len(s.images)+1))
+	return nil
+}
+
+func (b *Builder) currentSlide() *slide {
+	if len(b.slides) == 0 {
+		return nil
+	}
+	return b.slides[len(b.slides)-1]
+}
@@ -0,0 +1,125 @@
+package pptx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"flag"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestBuilder(t *testing.T) {
+	ctx := context.Background()
+	imagePath := filepath.Join(t.TempDir(), "image.png")
+	f, err := os.Create(imagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, image.NewGray(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	b := NewBuilder()
+	b.Date = time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	if err := b.CreateCover(ctx, "The title", "The <subtitle> & more"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.CreateChapter(ctx, structure.Slide{Title: "First chapter", Chapter: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.InsertImage(ctx, "file://"+imagePath, 2743200, 2743200, 1213950, 1659800); err != nil {
+		t.Fatal(err)
+	}
+	err = b.CreateSlideTitleSubtitleBody(ctx, structure.Slide{
+		Title:    "A slide",
+		Subtitle: "with a subtitle",
+		Body: `this is a **bold** word and this is a list:
+- the level of indentation should be 1
+  - this content should have a level indentation of 2
+and this is back to a level of indentation of zero
+1. a *numbered* item with a [link](https://example.com/?a=1&b=2)
+   1. and ` + "`code`" + ``,
+		Notes: "Talk about the <list>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = b.CreateTableSlide(ctx, structure.Slide{
+		Title: "A table slide",
+		Table: structure.Table{Header: []string{"Name", "Description"}, Rows: [][]string{{"<a>", "the first & only row"}, {"b"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = b.CreateCodeSlide(ctx, structure.Slide{
+		Title: "A code slide",
+		Code:  "```go\n// main prints\nfunc main() {\n\tfmt.Println(\"<hello>\", 42)\n}\n```",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = b.CreateChartSlide(ctx, structure.Slide{Title: "A chart slide", Notes: "Comment the chart"}, "file://"+imagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := b.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got := dump(t, buf.Bytes())
+
+	golden := filepath.Join("testdata", "deck.golden")
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("unexpected package content, run go test -update to see the differences:\n%v", got)
+	}
+}
+
+// dump returns the name and content of each XML part of the package, and only the name of the media.
+func dump(t *testing.T, pkg []byte) string {
+	zr, err := zip.NewReader(bytes.NewReader(pkg), int64(len(pkg)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	for _, f := range zr.File {
+		sb.WriteString("=== " + f.Name + "\n")
+		if strings.HasPrefix(f.Name, "ppt/media/") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sb.Write(content)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,203 @@
+package pptx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// bulletIndent is the indentation of a bullet level in EMUs.
+const bulletIndent = 342900
+
+// addTextBox appends a text box holding the paragraphs (DrawingML a:p elements) to the slide.
+func (s *slide) addTextBox(x, y, cx, cy int64, name, paragraphs string) {
+	fmt.Fprintf(&s.shapes, `<p:sp><p:nvSpPr><p:cNvPr id="%d" name="%s"/><p:cNvSpPr txBox="1"/><p:nvPr/></p:nvSpPr>`, s.nextID, escape(name))
+	fmt.Fprintf(&s.shapes, `<p:spPr><a:xfrm><a:off x="%d" y="%d"/><a:ext cx="%d" cy="%d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></p:spPr>`, x, y, cx, cy)
+	fmt.Fprintf(&s.shapes, `<p:txBody><a:bodyPr wrap="square" rtlCol="0"><a:normAutofit/></a:bodyPr><a:lstStyle/>%s</p:txBody></p:sp>`, paragraphs)
+	s.nextID++
+}
+
+// rowHeight is the minimum height of a row of a table in EMUs; the rows grow with their content.
+const rowHeight = 370840
+
+// addTable appends the table to the slide, at the position of the frame and as wide as the frame.
+// The columns are sized by slidesutils.ColumnWidths and the header row is bold on a slidesutils.TableHeaderColor background.
+func (s *slide) addTable(t structure.Table, frame slidesutils.Frame, size int) {
+	widths := slidesutils.ColumnWidths(t, frame.Width)
+	if len(widths) == 0 {
+		return
+	}
+	rows := t.Rows
+	if len(t.Header) > 0 {
+		rows = append([][]string{t.Header}, rows...)
+	}
+	fmt.Fprintf(&s.shapes, `<p:graphicFrame><p:nvGraphicFramePr><p:cNvPr id="%d" name="Table %d"/><p:cNvGraphicFramePr><a:graphicFrameLocks noGrp="1"/></p:cNvGraphicFramePr><p:nvPr/></p:nvGraphicFramePr>`, s.nextID, s.nextID)
+	fmt.Fprintf(&s.shapes, `<p:xfrm><a:off x="%d" y="%d"/><a:ext cx="%d" cy="%d"/></p:xfrm>`, int64(frame.TranslateX), int64(frame.TranslateY), int64(frame.Width), len(rows)*rowHeight)
+	s.shapes.WriteString(`<a:graphic><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/table"><a:tbl><a:tblPr firstRow="1" bandRow="1"/><a:tblGrid>`)
+	for _, w := range widths {
+		fmt.Fprintf(&s.shapes, `<a:gridCol w="%d"/>`, int64(w))
+	}
+	s.shapes.WriteString(`</a:tblGrid>`)
+	border := `<a:solidFill><a:srgbClr val="BFBFBF"/></a:solidFill>`
+	for r, row := range rows {
+		header := r == 0 && len(t.Header) > 0
+		fmt.Fprintf(&s.shapes, `<a:tr h="%d">`, rowHeight)
+		for c := range widths {
+			var cell string
+			if c < len(row) {
+				cell = row[c]
+			}
+			s.shapes.WriteString(`<a:tc><a:txBody><a:bodyPr/><a:lstStyle/>`)
+			s.shapes.WriteString(paragraphs(cell, size, header, "l"))
+			s.shapes.WriteString(`</a:txBody><a:tcPr>`)
+			for _, line := range []string{"lnL", "lnR", "lnT", "lnB"} {
+				fmt.Fprintf(&s.shapes, `<a:%s w="12700">%s</a:%s>`, line, border, line)
+			}
+			if header {
+				fmt.Fprintf(&s.shapes, `<a:solidFill><a:srgbClr val="%s"/></a:solidFill>`, slidesutils.TableHeaderColor)
+			}
+			s.shapes.WriteString(`</a:tcPr></a:tc>`)
+		}
+		s.shapes.WriteString(`</a:tr>`)
+	}
+	s.shapes.WriteString(`</a:tbl></a:graphicData></a:graphic></p:graphicFrame>`)
+	s.nextID++
+}
+
+// addPicture appends a picture referencing the image relationship rID to the slide.
+func (s *slide) addPicture(x, y, cx, cy int64, rID string) {
+	fmt.Fprintf(&s.shapes, `<p:pic><p:nvPicPr><p:cNvPr id="%d" name="Picture %d"/><p:cNvPicPr><a:picLocks noChangeAspect="1"/></p:cNvPicPr><p:nvPr/></p:nvPicPr>`, s.nextID, s.nextID)
+	fmt.Fprintf(&s.shapes, `<p:blipFill><a:blip r:embed="%s"/><a:stretch><a:fillRect/></a:stretch></p:blipFill>`, rID)
+	fmt.Fprintf(&s.shapes, `<p:spPr><a:xfrm><a:off x="%d" y="%d"/><a:ext cx="%d" cy="%d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></p:spPr></p:pic>`, x, y, cx, cy)
+	s.nextID++
+}
+
+// paragraphs returns the text as DrawingML paragraphs (one per line) with the given size (in hundredths of a point) and alignment.
+func paragraphs(text string, size int, bold bool, align string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(&sb, `<a:p><a:pPr algn="%s"/>`, align)
+		if line != "" {
+			sb.WriteString(run(line, size, bold))
+		}
+		fmt.Fprintf(&sb, `<a:endParaRPr lang="en-US" sz="%d"/></a:p>`, size)
+	}
+	return sb.String()
+}
+
+// numberings are the numbering schemes of the levels of a numbered list, as the NUMBERED_DIGIT_ALPHA_ROMAN preset of Google Slides.
+var numberings = []string{"arabicPeriod", "alphaLcPeriod", "romanLcPeriod"}
+
+// bodyParagraphs renders the parsed body as DrawingML paragraphs, with bullets or numbers for the list items.
+// The links are added to the relationships of the slide.
+func (s *slide) bodyParagraphs(content []slidesutils.Paragraph, size int) string {
+	if len(content) == 0 {
+		return `<a:p><a:endParaRPr lang="en-US"/></a:p>`
+	}
+	var sb strings.Builder
+	for _, p := range content {
+		switch {
+		case p.Level > 0 && p.Ordered:
+			fmt.Fprintf(&sb, `<a:p><a:pPr marL="%d" lvl="%d" indent="%d"><a:buFont typeface="+mj-lt"/><a:buAutoNum type="%s"/></a:pPr>`, p.Level*bulletIndent, p.Level-1, -bulletIndent, numberings[(p.Level-1)%len(numberings)])
+		case p.Level > 0:
+			fmt.Fprintf(&sb, `<a:p><a:pPr marL="%d" lvl="%d" indent="%d"><a:buFont typeface="Arial"/><a:buChar char="&#8226;"/></a:pPr>`, p.Level*bulletIndent, p.Level-1, -bulletIndent)
+		default:
+			sb.WriteString(`<a:p><a:pPr><a:buNone/></a:pPr>`)
+		}
+		for _, r := range p.Runs {
+			if p.Heading > 0 {
+				r.Bold = true
+			}
+			sb.WriteString(s.styledRun(r, size))
+		}
+		fmt.Fprintf(&sb, `<a:endParaRPr lang="en-US" sz="%d"/></a:p>`, size)
+	}
+	return sb.String()
+}
+
+// styledRun returns a DrawingML text run with the style of the parsed run.
+func (s *slide) styledRun(r slidesutils.Run, size int) string {
+	if !r.Italic && !r.Underline && !r.Strikethrough && !r.Code && r.Link == "" {
+		return run(r.Text, size, r.Bold)
+	}
+	var attributes, children strings.Builder
+	fmt.Fprintf(&attributes, ` lang="en-US" sz="%d"`, size)
+	if r.Bold {
+		attributes.WriteString(` b="1"`)
+	}
+	if r.Italic {
+		attributes.WriteString(` i="1"`)
+	}
+	if r.Underline {
+		attributes.WriteString(` u="sng"`)
+	}
+	if r.Strikethrough {
+		attributes.WriteString(` strike="sngStrike"`)
+	}
+	if r.Code {
+		children.WriteString(`<a:latin typeface="Courier New"/>`)
+	}
+	if r.Link != "" {
+		s.links = append(s.links, r.Link)
+		fmt.Fprintf(&children, `<a:hlinkClick r:id="%s"/>`, linkID(len(s.links)-1))
+	}
+	return fmt.Sprintf(`<a:r><a:rPr%s dirty="0">%s</a:rPr><a:t>%s</a:t></a:r>`, attributes.String(), children.String(), escape(r.Text))
+}
+
+// codeParagraphs renders the fenced code block as DrawingML paragraphs (one per line) in a monospace font,
+// with the tokens colored as slidesutils.CodeColors.
+func codeParagraphs(block string, size int) string {
+	language, code := slidesutils.ParseCodeBlock(block)
+	var sb strings.Builder
+	sb.WriteString(`<a:p><a:pPr><a:buNone/></a:pPr>`)
+	for _, token := range slidesutils.Highlight(code, language) {
+		for i, text := range strings.Split(token.Text, "\n") {
+			if i > 0 {
+				fmt.Fprintf(&sb, `<a:endParaRPr lang="en-US" sz="%d"/></a:p><a:p><a:pPr><a:buNone/></a:pPr>`, size)
+			}
+			if text == "" {
+				continue
+			}
+			var style string
+			switch token.Kind {
+			case slidesutils.TokenKeyword:
+				style = ` b="1"`
+			case slidesutils.TokenComment:
+				style = ` i="1"`
+			}
+			var color string
+			if c, ok := slidesutils.CodeColors[token.Kind]; ok {
+				color = `<a:solidFill><a:srgbClr val="` + c + `"/></a:solidFill>`
+			}
+			fmt.Fprintf(&sb, `<a:r><a:rPr lang="en-US" sz="%d"%s dirty="0">%s<a:latin typeface="Courier New"/></a:rPr><a:t>%s</a:t></a:r>`, size, style, color, escape(text))
+		}
+	}
+	fmt.Fprintf(&sb, `<a:endParaRPr lang="en-US" sz="%d"/></a:p>`, size)
+	return sb.String()
+}
+
+// run returns a DrawingML text run.
+func run(text string, size int, bold bool) string {
+	var b string
+	if bold {
+		b = ` b="1"`
+	}
+	return fmt.Sprintf(`<a:r><a:rPr lang="en-US" sz="%d"%s dirty="0"/><a:t>%s</a:t></a:r>`, size, b, escape(text))
+}
+
+// linkID returns the relationship ID of the i-th link of a slide.
+func linkID(i int) string {
+	return "rIdL" + strconv.Itoa(i+1)
+}
+
+// escape escapes the text for an XML document.
+func escape(text string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
@@ -0,0 +1,172 @@
+package pptx
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// part is a file of the package.
+type part struct {
+	name    string
+	content []byte
+}
+
+// Write serializes the presentation as a .pptx file.
+func (b *Builder) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for _, p := range b.parts() {
+		f, err := zw.CreateHeader(&zip.FileHeader{
+			Name:   p.name,
+			Method: zip.Deflate,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot write %v: %w", p.name, err)
+		}
+		if _, err := f.Write(p.content); err != nil {
+			return fmt.Errorf("cannot write %v: %w", p.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+// parts returns all the files of the package, the content types first.
+func (b *Builder) parts() []part {
+	parts := []part{
+		{"[Content_Types].xml", []byte(b.contentTypes())},
+		{"_rels/.rels", []byte(rootRels)},
+		{"ppt/presentation.xml", []byte(b.presentation())},
+		{"ppt/_rels/presentation.xml.rels", []byte(b.presentationRels())},
+		{"ppt/slideMasters/slideMaster1.xml", []byte(slideMaster)},
+		{"ppt/slideMasters/_rels/slideMaster1.xml.rels", []byte(slideMasterRels)},
+		{"ppt/slideLayouts/slideLayout1.xml", []byte(slideLayout)},
+		{"ppt/slideLayouts/_rels/slideLayout1.xml.rels", []byte(slideLayoutRels)},
+		{"ppt/theme/theme1.xml", []byte(theme)},
+		{"ppt/notesMasters/notesMaster1.xml", []byte(notesMaster)},
+		{"ppt/notesMasters/_rels/notesMaster1.xml.rels", []byte(notesMasterRels)},
+		{"ppt/theme/theme2.xml", []byte(theme)},
+	}
+	for i, s := range b.slides {
+		parts = append(parts,
+			part{fmt.Sprintf("ppt/slides/slide%d.xml", i+1), []byte(s.xml())},
+			part{fmt.Sprintf("ppt/slides/_rels/slide%d.xml.rels", i+1), []byte(b.slideRels(i, s))},
+		)
+		if s.notes != "" {
+			parts = append(parts,
+				part{fmt.Sprintf("ppt/notesSlides/notesSlide%d.xml", i+1), []byte(s.notesXML())},
+				part{fmt.Sprintf("ppt/notesSlides/_rels/notesSlide%d.xml.rels", i+1), []byte(notesRels(i))},
+			)
+		}
+	}
+	for i, m := range b.media {
+		parts = append(parts, part{mediaName(i, m), m.data})
+	}
+	return parts
+}
+
+func mediaName(i int, m media) string {
+	return fmt.Sprintf("ppt/media/image%d.%s", i+1, m.extension)
+}
+
+func (b *Builder) contentTypes() string {
+	var sb strings.Builder
+	sb.WriteString(xmlHeader + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	sb.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	sb.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	sb.WriteString(`<Default Extension="png" ContentType="image/png"/>`)
+	sb.WriteString(`<Default Extension="jpeg" ContentType="image/jpeg"/>`)
+	sb.WriteString(`<Default Extension="gif" ContentType="image/gif"/>`)
+	sb.WriteString(`<Override PartName="/ppt/presentation.xml" ContentType="application/vnd.openxmlformats-officedocument.presentationml.presentation.main+xml"/>`)
+	sb.WriteString(`<Override PartName="/ppt/slideMasters/slideMaster1.xml" ContentType="application/vnd.openxmlformats-officedocument.presentationml.slideMaster+xml"/>`)
+	sb.WriteString(`<Override PartName="/ppt/slideLayouts/slideLayout1.xml" ContentType="application/vnd.openxmlformats-officedocument.presentationml.slideLayout+xml"/>`)
+	sb.WriteString(`<Override PartName="/ppt/theme/theme1.xml" ContentType="application/vnd.openxmlformats-officedocument.theme+xml"/>`)
+	sb.WriteString(`<Override PartName="/ppt/notesMasters/notesMaster1.xml" ContentType="application/vnd.openxmlformats-officedocument.presentationml.notesMaster+xml"/>`)
+	sb.WriteString(`<Override PartName="/ppt/theme/theme2.xml" ContentType="application/vnd.openxmlformats-officedocument.theme+xml"/>`)
+	for i, s := range b.slides {
+		fmt.Fprintf(&sb, `<Override PartName="/ppt/slides/slide%d.xml" ContentType="application/vnd.openxmlformats-officedocument.presentationml.slide+xml"/>`, i+1)
+		if s.notes != "" {
+			fmt.Fprintf(&sb, `<Override PartName="/ppt/notesSlides/notesSlide%d.xml" ContentType="application/vnd.openxmlformats-officedocument.presentationml.notesSlide+xml"/>`, i+1)
+		}
+	}
+	sb.WriteString(`</Types>`)
+	return sb.String()
+}
+
+// presentation returns the main part; the relationship rId1 is the master, rId2 the theme, the slides start at rId3
+// and the notes master follows the slides.
+func (b *Builder) presentation() string {
+	var sb strings.Builder
+	sb.WriteString(xmlHeader + `<p:presentation xmlns:a="` + nsA + `" xmlns:r="` + nsR + `" xmlns:p="` + nsP + `" saveSubsetFonts="1">`)
+	sb.WriteString(`<p:sldMasterIdLst><p:sldMasterId id="2147483648" r:id="rId1"/></p:sldMasterIdLst>`)
+	fmt.Fprintf(&sb, `<p:notesMasterIdLst><p:notesMasterId r:id="rId%d"/></p:notesMasterIdLst>`, len(b.slides)+3)
+	if len(b.slides) > 0 {
+		sb.WriteString(`<p:sldIdLst>`)
+		for i := range b.slides {
+			fmt.Fprintf(&sb, `<p:sldId id="%d" r:id="rId%d"/>`, 256+i, i+3)
+		}
+		sb.WriteString(`</p:sldIdLst>`)
+	}
+	fmt.Fprintf(&sb, `<p:sldSz cx="%d" cy="%d"/><p:notesSz cx="%d" cy="%d"/>`, slideWidth, slideHeight, slideHeight, slideWidth)
+	sb.WriteString(`</p:presentation>`)
+	return sb.String()
+}
+
+func (b *Builder) presentationRels() string {
+	var sb strings.Builder
+	sb.WriteString(xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	sb.WriteString(`<Relationship Id="rId1" Type="` + relTypeBase + `slideMaster" Target="slideMasters/slideMaster1.xml"/>`)
+	sb.WriteString(`<Relationship Id="rId2" Type="` + relTypeBase + `theme" Target="theme/theme1.xml"/>`)
+	for i := range b.slides {
+		fmt.Fprintf(&sb, `<Relationship Id="rId%d" Type="`+relTypeBase+`slide" Target="slides/slide%d.xml"/>`, i+3, i+1)
+	}
+	fmt.Fprintf(&sb, `<Relationship Id="rId%d" Type="`+relTypeBase+`notesMaster" Target="notesMasters/notesMaster1.xml"/>`, len(b.slides)+3)
+	sb.WriteString(`</Relationships>`)
+	return sb.String()
+}
+
+func (s *slide) xml() string {
+	return xmlHeader + `<p:sld xmlns:a="` + nsA + `" xmlns:r="` + nsR + `" xmlns:p="` + nsP + `">` +
+		`<p:cSld><p:spTree>` + groupShapeHeader + s.shapes.String() + `</p:spTree></p:cSld>` +
+		`<p:clrMapOvr><a:masterClrMapping/></p:clrMapOvr>` +
+		`</p:sld>`
+}
+
+// slideRels returns the relationships of the i-th slide: rId1 is the layout, the images start at rId2
+// and the notes slide follows the images; the hyperlinks are rIdL1, rIdL2...
+func (b *Builder) slideRels(i int, s *slide) string {
+	var sb strings.Builder
+	sb.WriteString(xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	sb.WriteString(`<Relationship Id="rId1" Type="` + relTypeBase + `slideLayout" Target="../slideLayouts/slideLayout1.xml"/>`)
+	for j, m := range s.images {
+		fmt.Fprintf(&sb, `<Relationship Id="rId%d" Type="`+relTypeBase+`image" Target="../media/%s"/>`, j+2, strings.TrimPrefix(mediaName(m, b.media[m]), "ppt/media/"))
+	}
+	if s.notes != "" {
+		fmt.Fprintf(&sb, `<Relationship Id="rId%d" Type="`+relTypeBase+`notesSlide" Target="../notesSlides/notesSlide%d.xml"/>`, len(s.images)+2, i+1)
+	}
+	for j, link := range s.links {
+		fmt.Fprintf(&sb, `<Relationship Id="%s" Type="`+relTypeBase+`hyperlink" Target="%s" TargetMode="External"/>`, linkID(j), escape(link))
+	}
+	sb.WriteString(`</Relationships>`)
+	return sb.String()
+}
+
+// notesXML returns the notes slide holding the speaker notes in its body placeholder.
+func (s *slide) notesXML() string {
+	return xmlHeader + `<p:notes xmlns:a="` + nsA + `" xmlns:r="` + nsR + `" xmlns:p="` + nsP + `">` +
+		`<p:cSld><p:spTree>` + groupShapeHeader +
+		`<p:sp><p:nvSpPr><p:cNvPr id="2" name="Notes Placeholder 1"/><p:cNvSpPr><a:spLocks noGrp="1"/></p:cNvSpPr><p:nvPr><p:ph type="body" idx="1"/></p:nvPr></p:nvSpPr>` +
+		fmt.Sprintf(`<p:spPr><a:xfrm><a:off x="%d" y="%d"/><a:ext cx="%d" cy="%d"/></a:xfrm></p:spPr>`, 685800, 4400550, 5486400, 3600450) +
+		`<p:txBody><a:bodyPr/><a:lstStyle/>` + paragraphs(s.notes, 1200, false, "l") + `</p:txBody></p:sp>` +
+		`</p:spTree></p:cSld>` +
+		`<p:clrMapOvr><a:masterClrMapping/></p:clrMapOvr>` +
+		`</p:notes>`
+}
+
+// notesRels returns the relationships of the notes slide of the i-th slide.
+func notesRels(i int) string {
+	return xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="` + relTypeBase + `notesMaster" Target="../notesMasters/notesMaster1.xml"/>` +
+		fmt.Sprintf(`<Relationship Id="rId2" Type="`+relTypeBase+`slide" Target="../slides/slide%d.xml"/>`, i+1) +
+		`</Relationships>`
+}
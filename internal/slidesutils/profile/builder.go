@@ -0,0 +1,189 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	slides "google.golang.org/api/slides/v1"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/slidesutils/mytemplate"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// Builder is a slidesutils.BuilderInterface driven by a Profile.
+// It relies on mytemplate.Builder for the creation of the slides and the insertion of the images,
+// and fills the placeholders as declared in the profile.
+type Builder struct {
+	*mytemplate.Builder
+	Profile *Profile
+}
+
+// NewBuilder initializes a Builder for the presentation presentationId using the layouts declared in the profile.
+func NewBuilder(ctx context.Context, srv *slides.Service, presentationId string, p *Profile) (*Builder, error) {
+	b, err := mytemplate.NewBuilder(ctx, srv, presentationId, p.LayoutNames())
+	if err != nil {
+		return nil, err
+	}
+	return &Builder{
+		Builder: b,
+		Profile: p,
+	}, nil
+}
+
+// content holds the values that can be bound to a placeholder.
+type content struct {
+	title, subtitle, body string
+	code                  string          // the code block of a code slide, shown in the body placeholder
+	table                 structure.Table // the table of a table slide, replacing the body placeholder
+	chart                 string          // the URL of the image of a chart slide, replacing the body placeholder
+	notes                 string          // the speaker notes, not bound to a placeholder
+}
+
+// CreateCover creates the cover slide with the title and subtitle of the presentation.
+func (b *Builder) CreateCover(ctx context.Context, title, subtitle string) error {
+	return b.createSlide(ctx, mytemplate.RoleCover, content{title: title, subtitle: subtitle})
+}
+
+// CreateChapter creates a chapter slide and increments the current chapter number.
+func (b *Builder) CreateChapter(ctx context.Context, slide structure.Slide) error {
+	err := b.createSlide(ctx, mytemplate.RoleChapter, content{title: slide.Title, subtitle: slide.Subtitle, notes: slide.Notes})
+	if err != nil {
+		return err
+	}
+	b.CurrentChapter++
+	return nil
+}
+
+// CreateSlideTitleSubtitleBody creates a content slide.
+func (b *Builder) CreateSlideTitleSubtitleBody(ctx context.Context, slide structure.Slide) error {
+	return b.createSlide(ctx, mytemplate.RoleContent, content{title: slide.Title, subtitle: slide.Subtitle, body: slide.Body, notes: slide.Notes})
+}
+
+// CreateCodeSlide creates a content slide showing the code block of the slide instead of its body.
+func (b *Builder) CreateCodeSlide(ctx context.Context, slide structure.Slide) error {
+	return b.createSlide(ctx, mytemplate.RoleContent, content{title: slide.Title, subtitle: slide.Subtitle, code: slide.Code, notes: slide.Notes})
+}
+
+// CreateTableSlide creates a content slide showing the table of the slide in place of the body placeholder.
+func (b *Builder) CreateTableSlide(ctx context.Context, slide structure.Slide) error {
+	return b.createSlide(ctx, mytemplate.RoleContent, content{title: slide.Title, subtitle: slide.Subtitle, table: slide.Table, notes: slide.Notes})
+}
+
+// CreateChartSlide creates a content slide showing the image of the chart of the slide in place of the body placeholder.
+func (b *Builder) CreateChartSlide(ctx context.Context, slide structure.Slide, imageUrl string) error {
+	return b.createSlide(ctx, mytemplate.RoleContent, content{title: slide.Title, subtitle: slide.Subtitle, chart: imageUrl, notes: slide.Notes})
+}
+
+// createSlide creates a slide with the layout of the role and fills its placeholders according to the profile.
+func (b *Builder) createSlide(ctx context.Context, role string, c content) error {
+	if err := b.CreateNewSlide(ctx, b.Layouts[role]); err != nil {
+		return fmt.Errorf("failed to create %v slide: %w", role, err)
+	}
+	if b.CurrentSlide == nil {
+		return fmt.Errorf("current slide is not set after creation")
+	}
+
+	var requests []*slides.Request
+	var chartFrame *slidesutils.Frame
+	for _, binding := range b.Profile.Roles[role].Placeholders {
+		objectID := findPlaceholder(b.CurrentSlide, binding)
+		if objectID == "" {
+			return fmt.Errorf("%v slide: no placeholder matches %+v", role, binding)
+		}
+		if binding.Field == FieldBody {
+			if c.chart != "" {
+				// The image is inserted once the placeholder is removed
+				frame := bodyFrame(b.CurrentSlide, objectID)
+				chartFrame = &frame
+				requests = append(requests, &slides.Request{DeleteObject: &slides.DeleteObjectRequest{ObjectId: objectID}})
+			} else if !c.table.Empty() {
+				requests = append(requests, &slides.Request{DeleteObject: &slides.DeleteObjectRequest{ObjectId: objectID}})
+				requests = append(requests, slidesutils.FormatTable(c.table, b.CurrentSlide.ObjectId, b.NewObjectID(), bodyFrame(b.CurrentSlide, objectID))...)
+			} else if c.code != "" {
+				requests = append(requests, slidesutils.FormatCode(c.code, objectID)...)
+			} else if c.body != "" {
+				requests = append(requests, slidesutils.Format(c.body, objectID)...)
+			}
+			continue
+		}
+		text := b.value(binding, c)
+		if text == "" {
+			continue
+		}
+		requests = append(requests, &slides.Request{
+			InsertText: &slides.InsertTextRequest{
+				ObjectId:       objectID,
+				InsertionIndex: 0,
+				Text:           text,
+			},
+		})
+	}
+	b.AddSpeakerNotes(c.notes)
+	if len(requests) > 0 {
+		// Queue the requests inserting text into the placeholders.
+		if err := b.Queue(ctx, requests...); err != nil {
+			return fmt.Errorf("failed to insert text: %w", err)
+		}
+	}
+	if chartFrame != nil {
+		if err := b.InsertImage(ctx, c.chart, chartFrame.Width, chartFrame.Height, chartFrame.TranslateX, chartFrame.TranslateY); err != nil {
+			return fmt.Errorf("failed to insert chart: %w", err)
+		}
+	}
+	return nil
+}
+
+// value returns the text of a binding (except for FieldBody which is formatted).
+func (b *Builder) value(binding Binding, c content) string {
+	switch binding.Field {
+	case FieldTitle:
+		return c.title
+	case FieldSubtitle:
+		return c.subtitle
+	case FieldChapterNumber:
+		return strconv.Itoa(b.CurrentChapter)
+	case FieldDate:
+		format := binding.Format
+		if format == "" {
+			format = "01/02/2006"
+		}
+		return time.Now().Format(format)
+	case FieldText:
+		return binding.Text
+	}
+	return ""
+}
+
+// findPlaceholder returns the object ID of the element of the page matching the binding.
+// A binding with an object ID matches the element itself or the element inheriting from this layout placeholder.
+func findPlaceholder(page *slides.Page, binding Binding) string {
+	for _, element := range page.PageElements {
+		if element.Shape == nil || element.Shape.Placeholder == nil {
+			continue
+		}
+		placeholder := element.Shape.Placeholder
+		if binding.ObjectID != "" {
+			if element.ObjectId == binding.ObjectID || placeholder.ParentObjectId == binding.ObjectID {
+				return element.ObjectId
+			}
+			continue
+		}
+		if placeholder.Type == binding.Type && placeholder.Index == binding.Index {
+			return element.ObjectId
+		}
+	}
+	return ""
+}
+
+// bodyFrame returns the frame of the placeholder replaced by a table or a chart.
+func bodyFrame(page *slides.Page, objectID string) slidesutils.Frame {
+	for _, element := range page.PageElements {
+		if element.ObjectId == objectID {
+			return slidesutils.ElementFrame(element, slidesutils.DefaultBodyFrame)
+		}
+	}
+	return slidesutils.DefaultBodyFrame
+}
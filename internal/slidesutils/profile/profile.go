@@ -0,0 +1,160 @@
+/*
+Package profile describes a Google Slides template declaratively.
+
+A profile tells, for each slide role (cover, chapter, content), which layout of the template to use,
+which placeholders receive which piece of content and where the illustration goes.
+It allows onboarding a new template by writing a YAML or JSON file instead of a Go package.
+
+Example:
+
+	roles:
+	  cover:
+	    layout: TITLE
+	    placeholders:
+	      - field: title
+	        type: TITLE
+	      - field: date
+	        type: SUBTITLE
+	        index: 1
+	        format: 02/01/2006
+	  chapter:
+	    layout: SECTION_HEADER
+	    placeholders:
+	      - field: title
+	        type: TITLE
+	    image:
+	      width: 2743200
+	      height: 2743200
+	      x: 1213950
+	      y: 1659800
+	  content:
+	    layout: TITLE_AND_BODY
+	    placeholders:
+	      - field: title
+	        type: TITLE
+	      - field: body
+	        type: BODY
+*/
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/slidesutils/mytemplate"
+)
+
+// The fields of the content that can be bound to a placeholder.
+const (
+	// FieldTitle is the title of the slide, or of the presentation for the cover.
+	FieldTitle = "title"
+	// FieldSubtitle is the subtitle of the slide, or of the presentation for the cover.
+	FieldSubtitle = "subtitle"
+	// FieldBody is the body of the slide, formatted with slidesutils.Format, or the code of a code slide;
+	// the table of a table slide or the image of a chart slide replaces the placeholder.
+	FieldBody = "body"
+	// FieldChapterNumber is the number of the current chapter.
+	FieldChapterNumber = "chapter_number"
+	// FieldDate is the current date, formatted according to the Format of the binding.
+	FieldDate = "date"
+	// FieldText is the static text held by the binding.
+	FieldText = "text"
+)
+
+// Profile is the declarative description of a template.
+type Profile struct {
+	// Roles holds the description of the slides indexed by role (mytemplate.RoleCover, mytemplate.RoleChapter and mytemplate.RoleContent).
+	Roles map[string]Role `json:"roles" yaml:"roles"`
+}
+
+// Role describes how to build a slide of a given role.
+type Role struct {
+	// Layout is the name, display name or object ID of the layout.
+	Layout string `json:"layout" yaml:"layout"`
+	// Placeholders tells which content goes in which placeholder.
+	Placeholders []Binding `json:"placeholders" yaml:"placeholders"`
+	// Image is the frame of the illustration; nil means the default frame.
+	Image *slidesutils.Frame `json:"image,omitempty" yaml:"image,omitempty"`
+}
+
+// Binding associates a field of the content with a placeholder.
+// The placeholder is identified either by its type (TITLE, SUBTITLE, BODY...) and index,
+// or by the object ID of the placeholder in the layout.
+type Binding struct {
+	Field    string `json:"field" yaml:"field"`
+	Type     string `json:"type,omitempty" yaml:"type,omitempty"`
+	Index    int64  `json:"index,omitempty" yaml:"index,omitempty"`
+	ObjectID string `json:"object_id,omitempty" yaml:"object_id,omitempty"`
+	// Text is the static text of a FieldText binding.
+	Text string `json:"text,omitempty" yaml:"text,omitempty"`
+	// Format is the Go time layout of a FieldDate binding; defaults to 01/02/2006.
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+}
+
+// Load reads a profile from a YAML (.yaml or .yml extension) or JSON file and validates it.
+func Load(path string) (*Profile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read profile: %w", err)
+	}
+	var p Profile
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &p)
+	default:
+		err = json.Unmarshal(b, &p)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode profile %v: %w", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid profile %v: %w", path, err)
+	}
+	return &p, nil
+}
+
+// Validate checks that every role is described and that the bindings are consistent.
+func (p *Profile) Validate() error {
+	for _, name := range []string{mytemplate.RoleCover, mytemplate.RoleChapter, mytemplate.RoleContent} {
+		role, ok := p.Roles[name]
+		if !ok {
+			return fmt.Errorf("missing role %q", name)
+		}
+		if role.Layout == "" {
+			return fmt.Errorf("role %q: missing layout", name)
+		}
+		for i, binding := range role.Placeholders {
+			switch binding.Field {
+			case FieldTitle, FieldSubtitle, FieldBody, FieldChapterNumber, FieldDate, FieldText:
+			default:
+				return fmt.Errorf("role %q, placeholder %v: unknown field %q", name, i, binding.Field)
+			}
+			if binding.Type == "" && binding.ObjectID == "" {
+				return fmt.Errorf("role %q, placeholder %v: a type or an object_id is required", name, i)
+			}
+		}
+	}
+	return nil
+}
+
+// LayoutNames returns the layout of each role, suitable for mytemplate.NewBuilder.
+func (p *Profile) LayoutNames() map[string]string {
+	names := make(map[string]string, len(p.Roles))
+	for name, role := range p.Roles {
+		names[name] = role.Layout
+	}
+	return names
+}
+
+// ImageFrame returns the frame of the illustration of the role, or slidesutils.DefaultChapterImageFrame if none is declared.
+func (p *Profile) ImageFrame(role string) slidesutils.Frame {
+	if r, ok := p.Roles[role]; ok && r.Image != nil {
+		return *r.Image
+	}
+	return slidesutils.DefaultChapterImageFrame
+}
@@ -0,0 +1,178 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	slides "google.golang.org/api/slides/v1"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/slidesutils/mytemplate"
+)
+
+// roles is a valid description of the roles, as YAML.
+const roles = `roles:
+  cover:
+    layout: TITLE
+    placeholders:
+      - field: title
+        type: TITLE
+  chapter:
+    layout: SECTION_HEADER
+    placeholders:
+      - field: chapter_number
+        object_id: p2_number
+    image:
+      width: 100
+      height: 200
+  content:
+    layout: TITLE_AND_BODY
+    placeholders:
+      - field: body
+        type: BODY
+`
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+	tests := []struct {
+		name     string
+		filename string
+		layouts  map[string]string
+		wantErr  string
+	}{
+		{"yaml", write("profile.yaml", roles), map[string]string{"cover": "TITLE", "chapter": "SECTION_HEADER", "content": "TITLE_AND_BODY"}, ""},
+		{
+			"json",
+			write("profile.json", `{"roles":{"cover":{"layout":"c"},"chapter":{"layout":"s"},"content":{"layout":"b","placeholders":[{"field":"body","type":"BODY"}]}}}`),
+			map[string]string{"cover": "c", "chapter": "s", "content": "b"},
+			"",
+		},
+		{"example", "../../../testdata/profile.yaml", map[string]string{"cover": "g2ac55f3490c_0_1073", "chapter": "g2ac55f3490c_0_1010", "content": "g2ac55f3490c_0_1006"}, ""},
+		{"missing file", filepath.Join(dir, "missing.yaml"), nil, "cannot read profile"},
+		{"not yaml", write("bad.yml", "roles: [\n"), nil, "cannot decode profile"},
+		{"json extension", write("profile.txt", roles), nil, "cannot decode profile"},
+		{"invalid", write("invalid.yaml", "roles:\n  cover:\n    layout: TITLE\n"), nil, `invalid profile`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Load(tt.filename)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := p.LayoutNames()
+			if len(got) != len(tt.layouts) {
+				t.Fatalf("got layouts %v, want %v", got, tt.layouts)
+			}
+			for role, layout := range tt.layouts {
+				if got[role] != layout {
+					t.Errorf("got layouts %v, want %v", got, tt.layouts)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *Profile {
+		return &Profile{Roles: map[string]Role{
+			mytemplate.RoleCover:   {Layout: "TITLE", Placeholders: []Binding{{Field: FieldTitle, Type: "TITLE"}}},
+			mytemplate.RoleChapter: {Layout: "SECTION_HEADER"},
+			mytemplate.RoleContent: {Layout: "TITLE_AND_BODY", Placeholders: []Binding{{Field: FieldBody, ObjectID: "body"}}},
+		}}
+	}
+	tests := []struct {
+		name    string
+		change  func(p *Profile)
+		wantErr string
+	}{
+		{"valid", func(p *Profile) {}, ""},
+		{"missing role", func(p *Profile) { delete(p.Roles, mytemplate.RoleChapter) }, `missing role "chapter"`},
+		{"missing layout", func(p *Profile) { p.Roles[mytemplate.RoleCover] = Role{} }, `role "cover": missing layout`},
+		{"unknown field", func(p *Profile) {
+			p.Roles[mytemplate.RoleContent] = Role{Layout: "b", Placeholders: []Binding{{Field: "footer", Type: "FOOTER"}}}
+		}, `role "content", placeholder 0: unknown field "footer"`},
+		{"no placeholder", func(p *Profile) {
+			p.Roles[mytemplate.RoleContent] = Role{Layout: "b", Placeholders: []Binding{{Field: FieldBody, Type: "BODY"}, {Field: FieldTitle}}}
+		}, `role "content", placeholder 1: a type or an object_id is required`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid()
+			tt.change(p)
+			err := p.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImageFrame(t *testing.T) {
+	p := &Profile{Roles: map[string]Role{
+		mytemplate.RoleChapter: {Layout: "SECTION_HEADER", Image: &slidesutils.Frame{Width: 100, Height: 200}},
+		mytemplate.RoleContent: {Layout: "TITLE_AND_BODY"},
+	}}
+	if got := p.ImageFrame(mytemplate.RoleChapter); got != (slidesutils.Frame{Width: 100, Height: 200}) {
+		t.Errorf("got frame %v for the chapter", got)
+	}
+	if got := p.ImageFrame(mytemplate.RoleContent); got != slidesutils.DefaultChapterImageFrame {
+		t.Errorf("got frame %v for the content, want the default frame", got)
+	}
+}
+
+func TestFindPlaceholder(t *testing.T) {
+	placeholder := func(id, kind string, index int64, parent string) *slides.PageElement {
+		return &slides.PageElement{
+			ObjectId: id,
+			Shape:    &slides.Shape{Placeholder: &slides.Placeholder{Type: kind, Index: index, ParentObjectId: parent}},
+		}
+	}
+	page := &slides.Page{PageElements: []*slides.PageElement{
+		{ObjectId: "picture"},
+		placeholder("s_title", "TITLE", 0, "l_title"),
+		placeholder("s_date", "TITLE", 1, "l_date"),
+		placeholder("s_body", "BODY", 0, "l_body"),
+	}}
+	tests := []struct {
+		name    string
+		binding Binding
+		want    string
+	}{
+		{"type", Binding{Type: "BODY"}, "s_body"},
+		{"type and index", Binding{Type: "TITLE", Index: 1}, "s_date"},
+		{"object id of the slide", Binding{ObjectID: "s_title"}, "s_title"},
+		{"object id of the layout", Binding{ObjectID: "l_date"}, "s_date"},
+		{"object id before type", Binding{Type: "BODY", ObjectID: "l_title"}, "s_title"},
+		{"missing type", Binding{Type: "SUBTITLE"}, ""},
+		{"missing index", Binding{Type: "TITLE", Index: 2}, ""},
+		{"missing object id", Binding{ObjectID: "picture"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findPlaceholder(page, tt.binding); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package slidesutils
+
+import (
+	"strings"
+
+	"google.golang.org/api/slides/v1"
+)
+
+// ShapeText returns the text of the shape as Markdown: its paragraphs, the bulleted ones being list items indented by
+// their nesting level. The other formatting is lost. It returns an empty string if the shape has no text.
+func ShapeText(shape *slides.Shape) string {
+	if shape == nil || shape.Text == nil {
+		return ""
+	}
+	var lines []string
+	var line strings.Builder
+	prefix := ""
+	for _, t := range shape.Text.TextElements {
+		switch {
+		case t.ParagraphMarker != nil:
+			prefix = ""
+			if b := t.ParagraphMarker.Bullet; b != nil {
+				prefix = strings.Repeat("  ", int(b.NestingLevel)) + "- "
+			}
+		case t.TextRun != nil:
+			line.WriteString(t.TextRun.Content)
+		case t.AutoText != nil:
+			line.WriteString(t.AutoText.Content)
+		}
+		// A paragraph ends with a new line
+		if text := line.String(); strings.HasSuffix(text, "\n") {
+			lines = append(lines, prefix+strings.TrimSuffix(text, "\n"))
+			line.Reset()
+		}
+	}
+	if line.Len() > 0 {
+		lines = append(lines, prefix+line.String())
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
@@ -0,0 +1,29 @@
+package slidesutils
+
+import (
+	"testing"
+
+	"google.golang.org/api/slides/v1"
+)
+
+func TestShapeText(t *testing.T) {
+	bullet := func(level int64) *slides.TextElement {
+		return &slides.TextElement{ParagraphMarker: &slides.ParagraphMarker{Bullet: &slides.Bullet{NestingLevel: level}}}
+	}
+	run := func(text string) *slides.TextElement {
+		return &slides.TextElement{TextRun: &slides.TextRun{Content: text}}
+	}
+	shape := &slides.Shape{Text: &slides.TextContent{TextElements: []*slides.TextElement{
+		{ParagraphMarker: &slides.ParagraphMarker{}}, run("Intro in "), run("two runs\n"),
+		bullet(0), run("item\n"),
+		bullet(1), run("nested\n"),
+		{ParagraphMarker: &slides.ParagraphMarker{}}, run("End\n"),
+	}}}
+	want := "Intro in two runs\n- item\n  - nested\nEnd"
+	if got := ShapeText(shape); got != want {
+		t.Errorf("ShapeText() = %q, want %q", got, want)
+	}
+	if got := ShapeText(&slides.Shape{}); got != "" {
+		t.Errorf("ShapeText() of an empty shape = %q", got)
+	}
+}
@@ -0,0 +1,336 @@
+/*
+Package revealjs implements slidesutils.BuilderInterface by rendering the presentation as a single HTML file.
+
+The markup follows the reveal.js conventions: every slide is a section, and each chapter is a vertical stack
+holding the chapter slide and its content slides. The file is self-contained: the style and a minimal
+keyboard navigation are inlined and the images are embedded as data URLs.
+*/
+package revealjs
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// emuPerPixel converts the EMUs used by the builder interface into CSS pixels (96 dpi).
+const emuPerPixel = 9525
+
+// The layouts understood by CreateNewSlide.
+const (
+	layoutCover   = "cover"
+	layoutChapter = "chapter"
+	layoutContent = "content"
+)
+
+// Builder accumulates the slides of a presentation in memory.
+// The HTML document is rendered by Write once all the slides are created.
+type Builder struct {
+	CurrentChapter int       // Tracks the current chapter number in the presentation.
+	Date           time.Time // The date displayed on the cover.
+	Title          string    // The title of the HTML document, set by CreateCover.
+	stacks         []*stack
+}
+
+// stack is a top-level section; it holds several slides when it is a chapter.
+type stack struct {
+	chapter bool
+	slides  []*slide
+}
+
+// slide is the HTML content of a section; the layout is used as its class.
+type slide struct {
+	layout  string
+	content strings.Builder
+}
+
+// NewBuilder returns an empty Builder.
+func NewBuilder() *Builder {
+	return &Builder{
+		Date: time.Now(),
+	}
+}
+
+// CreateNewSlide appends a new slide; a chapter layout starts a new vertical stack.
+// The slides following a chapter are added to its stack.
+func (b *Builder) CreateNewSlide(ctx context.Context, layout string) error {
+	s := &slide{layout: layout}
+	switch {
+	case layout == layoutChapter:
+		b.stacks = append(b.stacks, &stack{chapter: true, slides: []*slide{s}})
+	case layout == layoutContent && len(b.stacks) > 0 && b.stacks[len(b.stacks)-1].chapter:
+		current := b.stacks[len(b.stacks)-1]
+		current.slides = append(current.slides, s)
+	default:
+		b.stacks = append(b.stacks, &stack{slides: []*slide{s}})
+	}
+	return nil
+}
+
+// CreateCover creates the cover slide with the title, the subtitle and the date.
+func (b *Builder) CreateCover(ctx context.Context, title, subtitle string) error {
+	if err := b.CreateNewSlide(ctx, layoutCover); err != nil {
+		return err
+	}
+	b.Title = title
+	s := b.currentSlide()
+	fmt.Fprintf(s, "<h1>%s</h1>\n", html.EscapeString(title))
+	fmt.Fprintf(s, "<h3>%s</h3>\n", html.EscapeString(subtitle))
+	fmt.Fprintf(s, "<p class=\"date\">%s - gptSlideShow</p>\n", b.Date.Format("01/02/2006"))
+	return nil
+}
+
+// CreateChapter creates a chapter slide with the chapter number and title.
+func (b *Builder) CreateChapter(ctx context.Context, slide structure.Slide) error {
+	if err := b.CreateNewSlide(ctx, layoutChapter); err != nil {
+		return err
+	}
+	s := b.currentSlide()
+	fmt.Fprintf(s, "<p class=\"chapter-number\">%s</p>\n", strconv.Itoa(b.CurrentChapter))
+	fmt.Fprintf(s, "<h2>%s</h2>\n", html.EscapeString(slide.Title))
+	writeNotes(s, slide.Notes)
+	b.CurrentChapter++
+	return nil
+}
+
+// CreateSlideTitleSubtitleBody creates a slide with a title, a subtitle and a formatted body.
+func (b *Builder) CreateSlideTitleSubtitleBody(ctx context.Context, slide structure.Slide) error {
+	if err := b.CreateNewSlide(ctx, layoutContent); err != nil {
+		return err
+	}
+	s := b.currentSlide()
+	fmt.Fprintf(s, "<h2>%s</h2>\n", html.EscapeString(slide.Title))
+	fmt.Fprintf(s, "<h3>%s</h3>\n", html.EscapeString(slide.Subtitle))
+	fmt.Fprintf(s, "<div class=\"body\">\n%s</div>\n", renderBody(slidesutils.Parse(slide.Body)))
+	writeNotes(s, slide.Notes)
+	return nil
+}
+
+// CreateCodeSlide creates a slide with a title, a subtitle and the highlighted code block of the slide.
+func (b *Builder) CreateCodeSlide(ctx context.Context, slide structure.Slide) error {
+	if err := b.CreateNewSlide(ctx, layoutContent); err != nil {
+		return err
+	}
+	s := b.currentSlide()
+	fmt.Fprintf(s, "<h2>%s</h2>\n", html.EscapeString(slide.Title))
+	fmt.Fprintf(s, "<h3>%s</h3>\n", html.EscapeString(slide.Subtitle))
+	fmt.Fprintf(s, "%s\n", renderCode(slide.Code))
+	writeNotes(s, slide.Notes)
+	return nil
+}
+
+// CreateTableSlide creates a slide with a title, a subtitle and the table of the slide.
+func (b *Builder) CreateTableSlide(ctx context.Context, slide structure.Slide) error {
+	if err := b.CreateNewSlide(ctx, layoutContent); err != nil {
+		return err
+	}
+	s := b.currentSlide()
+	fmt.Fprintf(s, "<h2>%s</h2>\n", html.EscapeString(slide.Title))
+	fmt.Fprintf(s, "<h3>%s</h3>\n", html.EscapeString(slide.Subtitle))
+	s.WriteString(renderTable(slide.Table))
+	writeNotes(s, slide.Notes)
+	return nil
+}
+
+// CreateChartSlide creates a slide with a title, a subtitle and the image of the chart of the slide.
+func (b *Builder) CreateChartSlide(ctx context.Context, slide structure.Slide, imageUrl string) error {
+	if err := b.CreateNewSlide(ctx, layoutContent); err != nil {
+		return err
+	}
+	s := b.currentSlide()
+	fmt.Fprintf(s, "<h2>%s</h2>\n", html.EscapeString(slide.Title))
+	fmt.Fprintf(s, "<h3>%s</h3>\n", html.EscapeString(slide.Subtitle))
+	writeNotes(s, slide.Notes)
+	frame := slidesutils.DefaultBodyFrame
+	return b.InsertImage(ctx, imageUrl, frame.Width, frame.Height, frame.TranslateX, frame.TranslateY)
+}
+
+// InsertImage embeds the image in the current slide as a data URL.
+// The imageUrl is either an http(s) URL or the path of a local file (optionally prefixed by file://).
+func (b *Builder) InsertImage(ctx context.Context, imageUrl string, width, height, translateX, translateY float64) error {
+	s := b.currentSlide()
+	if s == nil {
+		return fmt.Errorf("current slide is not set")
+	}
+	data, err := slidesutils.FetchImage(ctx, imageUrl)
+	if err != nil {
+		return fmt.Errorf("failed to insert image: %w", err)
+	}
+	fmt.Fprintf(s, "<img src=\"data:%s;base64,%s\" style=\"position:absolute;left:%dpx;top:%dpx;width:%dpx;height:%dpx\">\n",
+		http.DetectContentType(data), base64.StdEncoding.EncodeToString(data),
+		int(translateX/emuPerPixel), int(translateY/emuPerPixel), int(width/emuPerPixel), int(height/emuPerPixel))
+	return nil
+}
+
+func (b *Builder) currentSlide() *strings.Builder {
+	if len(b.stacks) == 0 {
+		return nil
+	}
+	current := b.stacks[len(b.stacks)-1]
+	return &current.slides[len(current.slides)-1].content
+}
+
+// writeNotes writes the speaker notes in an aside, as reveal.js does; they are shown with the "n" key.
+func writeNotes(s *strings.Builder, notes string) {
+	if notes == "" {
+		return
+	}
+	fmt.Fprintf(s, "<aside class=\"notes\">%s</aside>\n", strings.ReplaceAll(html.EscapeString(notes), "\n", "<br>"))
+}
+
+// renderBody renders the parsed body as HTML; the list items become nested ul or ol lists.
+// The headings are shifted by two levels, below the title and the subtitle of the slide.
+func renderBody(paragraphs []slidesutils.Paragraph) string {
+	var sb strings.Builder
+	var lists []string // The tags of the open lists
+	closeLists := func(level int) {
+		for len(lists) > level {
+			fmt.Fprintf(&sb, "</li>\n</%s>\n", lists[len(lists)-1])
+			lists = lists[:len(lists)-1]
+		}
+	}
+	for _, p := range paragraphs {
+		if p.Level == 0 {
+			closeLists(0)
+			switch {
+			case p.Heading > 0:
+				level := min(p.Heading+2, 6)
+				fmt.Fprintf(&sb, "<h%d>%s</h%d>\n", level, renderRuns(p.Runs), level)
+			case len(p.Runs) > 0:
+				fmt.Fprintf(&sb, "<p>%s</p>\n", renderRuns(p.Runs))
+			}
+			continue
+		}
+		tag := "ul"
+		if p.Ordered {
+			tag = "ol"
+		}
+		if p.Level <= len(lists) {
+			closeLists(p.Level)
+			if lists[p.Level-1] == tag {
+				sb.WriteString("</li>\n")
+			} else {
+				// Another kind of list follows at the same level
+				closeLists(p.Level - 1)
+			}
+		}
+		for len(lists) < p.Level {
+			fmt.Fprintf(&sb, "<%s>\n", tag)
+			lists = append(lists, tag)
+		}
+		fmt.Fprintf(&sb, "<li>%s", renderRuns(p.Runs))
+	}
+	closeLists(0)
+	return sb.String()
+}
+
+// renderRuns renders the runs of a paragraph as HTML.
+func renderRuns(runs []slidesutils.Run) string {
+	var sb strings.Builder
+	for _, r := range runs {
+		text := html.EscapeString(r.Text)
+		if r.Code {
+			text = "<code>" + text + "</code>"
+		}
+		for _, style := range []struct {
+			on  bool
+			tag string
+		}{{r.Bold, "strong"}, {r.Italic, "em"}, {r.Underline, "u"}, {r.Strikethrough, "s"}} {
+			if style.on {
+				text = "<" + style.tag + ">" + text + "</" + style.tag + ">"
+			}
+		}
+		if safeLink(r.Link) {
+			text = fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(r.Link), text)
+		}
+		sb.WriteString(text)
+	}
+	return sb.String()
+}
+
+// safeLink reports whether the link is an absolute http, https or mailto URL; the other links, such as javascript: or
+// data: URLs, are rendered as plain text.
+func safeLink(link string) bool {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+// renderCode renders the fenced code block as a pre element, with the tokens colored as slidesutils.CodeColors.
+func renderCode(block string) string {
+	language, code := slidesutils.ParseCodeBlock(block)
+	var sb strings.Builder
+	if language != "" {
+		fmt.Fprintf(&sb, "<pre class=\"code\"><code class=\"language-%s\">", html.EscapeString(language))
+	} else {
+		sb.WriteString("<pre class=\"code\"><code>")
+	}
+	for _, token := range slidesutils.Highlight(code, language) {
+		color, ok := slidesutils.CodeColors[token.Kind]
+		if !ok {
+			sb.WriteString(html.EscapeString(token.Text))
+			continue
+		}
+		style := "color:#" + color
+		switch token.Kind {
+		case slidesutils.TokenKeyword:
+			style += ";font-weight:bold"
+		case slidesutils.TokenComment:
+			style += ";font-style:italic"
+		}
+		fmt.Fprintf(&sb, "<span style=\"%s\">%s</span>", style, html.EscapeString(token.Text))
+	}
+	sb.WriteString("</code></pre>")
+	return sb.String()
+}
+
+// renderTable renders the table as HTML; the columns are sized by slidesutils.ColumnWidths, in percent of the default body frame.
+func renderTable(t structure.Table) string {
+	widths := slidesutils.ColumnWidths(t, slidesutils.DefaultBodyFrame.Width)
+	if len(widths) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("<table class=\"data\">\n<colgroup>")
+	for _, w := range widths {
+		fmt.Fprintf(&sb, "<col style=\"width:%.1f%%\">", 100*w/slidesutils.DefaultBodyFrame.Width)
+	}
+	sb.WriteString("</colgroup>\n")
+	row := func(cells []string, tag string) {
+		sb.WriteString("<tr>")
+		for c := range widths {
+			var cell string
+			if c < len(cells) {
+				cell = cells[c]
+			}
+			fmt.Fprintf(&sb, "<%s>%s</%s>", tag, html.EscapeString(cell), tag)
+		}
+		sb.WriteString("</tr>\n")
+	}
+	if len(t.Header) > 0 {
+		sb.WriteString("<thead>\n")
+		row(t.Header, "th")
+		sb.WriteString("</thead>\n")
+	}
+	sb.WriteString("<tbody>\n")
+	for _, cells := range t.Rows {
+		row(cells, "td")
+	}
+	sb.WriteString("</tbody>\n</table>\n")
+	return sb.String()
+}
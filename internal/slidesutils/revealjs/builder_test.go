@@ -0,0 +1,68 @@
+package revealjs
+
+import (
+	"testing"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+)
+
+func TestRenderBody(t *testing.T) {
+	input := `this is a **bold** word and this is a list:
+- the level of indentation should be 1
+  - this content should have a level indentation of 2
+- back to <1>
+and this is back to a level of indentation of zero
+### A *heading*
+1. a [link](https://example.com/?a=1&b=2)
+   - with ~~struck~~ and ` + "`code`" + `
+2. second`
+
+	expected := `<p>this is a <strong>bold</strong> word and this is a list:</p>
+<ul>
+<li>the level of indentation should be 1<ul>
+<li>this content should have a level indentation of 2</li>
+</ul>
+</li>
+<li>back to &lt;1&gt;</li>
+</ul>
+<p>and this is back to a level of indentation of zero</p>
+<h5>A <em>heading</em></h5>
+<ol>
+<li>a <a href="https://example.com/?a=1&amp;b=2">link</a><ul>
+<li>with <s>struck</s> and <code>code</code></li>
+</ul>
+</li>
+<li>second</li>
+</ol>
+`
+
+	result := renderBody(slidesutils.Parse(input))
+	if result != expected {
+		t.Errorf("renderBody() = %v, want %v", result, expected)
+	}
+}
+
+func TestRenderRunsLinks(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{"https://example.com/", `<a href="https://example.com/">text</a>`},
+		{"HTTP://example.com/", `<a href="HTTP://example.com/">text</a>`},
+		{"mailto:team@example.com", `<a href="mailto:team@example.com">text</a>`},
+		{"javascript:alert(1)", "text"},
+		{" JavaScript:alert(1)", "text"},
+		{"data:text/html;base64,PHNjcmlwdD4=", "text"},
+		{"vbscript:msgbox", "text"},
+		{"/relative/path", "text"},
+		{"%zz", "text"},
+		{"", "text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			if got := renderRuns([]slidesutils.Run{{Text: "text", Link: tt.link}}); got != tt.want {
+				t.Errorf("renderRuns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package revealjs
+
+import (
+	"html/template"
+	"io"
+
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+)
+
+// page is the skeleton of the HTML document.
+var page = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+html, body { margin: 0; height: 100%; background: #1f2937; font-family: Calibri, "Helvetica Neue", Arial, sans-serif; }
+.reveal { width: 100%; height: 100%; display: flex; align-items: center; justify-content: center; }
+.slides > section, .slides > section > section { display: none; }
+.slides section.present { display: block; }
+.slides section section.present, .slides > section:not(.stack).present {
+  position: relative; box-sizing: border-box; width: 960px; height: 720px; padding: 40px 48px;
+  background: #ffffff; color: #111827; overflow: hidden; transform-origin: center; }
+.slides > section.stack.present { width: auto; height: auto; padding: 0; background: none; }
+h1 { font-size: 44pt; margin-top: 180px; text-align: center; }
+h2 { font-size: 32pt; margin: 0 0 8px 0; }
+h3 { font-size: 20pt; font-weight: normal; color: #4b5563; margin: 0 0 16px 0; }
+section.cover h3, .date { text-align: center; }
+.date { position: absolute; bottom: 40px; left: 0; right: 0; font-size: 14pt; color: #6b7280; }
+.chapter-number { font-size: 28pt; font-weight: bold; color: #2563eb; }
+section.chapter h2 { position: absolute; bottom: 80px; font-size: 40pt; }
+.body { font-size: 16pt; line-height: 1.3; }
+.body p { margin: 0 0 8px 0; }
+.body ul { margin: 0 0 4px 0; }
+table.data { width: 100%; table-layout: fixed; border-collapse: collapse; font-size: 14pt; }
+table.data th, table.data td { border: 1px solid #bfbfbf; padding: 4px 8px; text-align: left; vertical-align: top; }
+table.data th { background: #{{.TableHeaderColor}}; }
+pre.code { font-family: "Courier New", monospace; font-size: 14pt; line-height: 1.25; tab-size: 4; background: #f6f8fa; padding: 12px; margin: 0; overflow: auto; }
+aside.notes { display: none; }
+body.notes aside.notes { display: block; position: absolute; left: 0; right: 0; bottom: 0; max-height: 40%; overflow: auto;
+  padding: 12px 48px; background: #fef3c7; color: #111827; font-size: 14pt; }
+</style>
+</head>
+<body>
+<div class="reveal">
+<div class="slides">
+{{- range .Stacks}}
+{{- if .Chapter}}
+<section class="stack">
+{{- range .Slides}}
+<section class="{{.Layout}}">
+{{.Content}}</section>
+{{- end}}
+</section>
+{{- else}}
+{{- range .Slides}}
+<section class="{{.Layout}}">
+{{.Content}}</section>
+{{- end}}
+{{- end}}
+{{- end}}
+</div>
+</div>
+<script>
+(function () {
+  var stacks = Array.prototype.slice.call(document.querySelectorAll(".slides > section"));
+  var h = 0, v = 0;
+  function slidesOf(stack) {
+    var nested = stack.querySelectorAll(":scope > section");
+    return nested.length ? Array.prototype.slice.call(nested) : [stack];
+  }
+  function show() {
+    document.querySelectorAll(".slides section").forEach(function (s) { s.classList.remove("present"); });
+    if (!stacks.length) { return; }
+    stacks[h].classList.add("present");
+    slidesOf(stacks[h])[v].classList.add("present");
+    var scale = Math.min(window.innerWidth / 960, window.innerHeight / 720);
+    slidesOf(stacks[h])[v].style.transform = "scale(" + scale + ")";
+    location.hash = "#/" + h + "/" + v;
+  }
+  document.addEventListener("keydown", function (e) {
+    switch (e.key) {
+    case "ArrowRight": case "PageDown": case " ":
+      if (v + 1 < slidesOf(stacks[h]).length) { v++; } else if (h + 1 < stacks.length) { h++; v = 0; }
+      break;
+    case "ArrowLeft": case "PageUp":
+      if (v > 0) { v--; } else if (h > 0) { h--; v = 0; }
+      break;
+    case "ArrowDown":
+      if (v + 1 < slidesOf(stacks[h]).length) { v++; }
+      break;
+    case "ArrowUp":
+      if (v > 0) { v--; }
+      break;
+    case "n":
+      document.body.classList.toggle("notes");
+      break;
+    default:
+      return;
+    }
+    show();
+  });
+  window.addEventListener("resize", show);
+  var m = location.hash.match(/^#\/(\d+)\/(\d+)$/);
+  if (m && +m[1] < stacks.length && +m[2] < slidesOf(stacks[+m[1]]).length) { h = +m[1]; v = +m[2]; }
+  show();
+})();
+</script>
+</body>
+</html>
+`))
+
+// pageData is the data of the page template.
+type pageData struct {
+	Title            string
+	TableHeaderColor string
+	Stacks           []stackData
+}
+
+type stackData struct {
+	Chapter bool
+	Slides  []slideData
+}
+
+type slideData struct {
+	Layout  string
+	Content template.HTML
+}
+
+// Write renders the presentation as a self-contained HTML document.
+func (b *Builder) Write(w io.Writer) error {
+	data := pageData{Title: b.Title, TableHeaderColor: slidesutils.TableHeaderColor}
+	for _, s := range b.stacks {
+		sd := stackData{Chapter: s.chapter}
+		for _, slide := range s.slides {
+			// The content of the slides is built from escaped strings
+			sd.Slides = append(sd.Slides, slideData{Layout: slide.layout, Content: template.HTML(slide.content.String())})
+		}
+		data.Stacks = append(data.Stacks, sd)
+	}
+	return page.Execute(w, data)
+}
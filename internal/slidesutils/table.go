@@ -0,0 +1,296 @@
+package slidesutils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+	"google.golang.org/api/slides/v1"
+)
+
+// TableHeaderColor is the background color of the header row of the tables, as a hexadecimal RGB value.
+var TableHeaderColor = "E8EAED"
+
+// minColumnWidth is the minimum width of a column of a table in Google Slides (32 points), in EMUs.
+const minColumnWidth = 406400
+
+// The length, in characters, of the cells taken into account to size the columns:
+// a column of long sentences does not squeeze the other ones, a column of short values stays readable.
+const (
+	minCellLength = 4
+	maxCellLength = 40
+)
+
+// ColumnWidths shares the width (in EMUs) between the columns of the table, in proportion to the length of their
+// longest cell. Each column is at least 32 points wide, if the width allows it.
+func ColumnWidths(t structure.Table, width float64) []float64 {
+	columns := t.Columns()
+	if columns == 0 {
+		return nil
+	}
+	lengths := make([]int, columns)
+	total := 0
+	for c := range lengths {
+		lengths[c] = minCellLength
+		for _, row := range append([][]string{t.Header}, t.Rows...) {
+			if c < len(row) {
+				lengths[c] = max(lengths[c], min(utf8.RuneCountInString(row[c]), maxCellLength))
+			}
+		}
+		total += lengths[c]
+	}
+	widths := make([]float64, columns)
+	remaining := width - float64(columns)*minColumnWidth
+	for c := range widths {
+		if remaining <= 0 {
+			widths[c] = width / float64(columns)
+			continue
+		}
+		widths[c] = minColumnWidth + remaining*float64(lengths[c])/float64(total)
+	}
+	return widths
+}
+
+// FormatTable returns the requests creating the table on the page.
+//
+// The table fills the width of the frame; its columns are sized by ColumnWidths, and its rows grow with their content.
+// The cells of the header row are bold on a TableHeaderColor background. The missing cells of the short rows are left empty.
+//
+// Parameters:
+//   - t: The table.
+//   - pageID: The ID of the slide.
+//   - tableID: The ID of the table to create.
+//   - frame: The position and size of the table.
+//
+// Returns:
+//   - []*slides.Request: The requests to send in a batch update, nil if the table is empty.
+func FormatTable(t structure.Table, pageID, tableID string, frame Frame) []*slides.Request {
+	columns := t.Columns()
+	if columns == 0 {
+		return nil
+	}
+	rows := t.Rows
+	if len(t.Header) > 0 {
+		rows = append([][]string{t.Header}, rows...)
+	}
+	requests := []*slides.Request{
+		{
+			CreateTable: &slides.CreateTableRequest{
+				ObjectId: tableID,
+				ElementProperties: &slides.PageElementProperties{
+					PageObjectId: pageID,
+					Size: &slides.Size{
+						Width:  &slides.Dimension{Magnitude: frame.Width, Unit: "EMU"},
+						Height: &slides.Dimension{Magnitude: frame.Height, Unit: "EMU"},
+					},
+					Transform: &slides.AffineTransform{
+						ScaleX:     1,
+						ScaleY:     1,
+						TranslateX: frame.TranslateX,
+						TranslateY: frame.TranslateY,
+						Unit:       "EMU",
+					},
+				},
+				Rows:    int64(len(rows)),
+				Columns: int64(columns),
+			},
+		},
+	}
+	for r, row := range rows {
+		for c, cell := range row {
+			if cell == "" {
+				continue
+			}
+			location := &slides.TableCellLocation{RowIndex: int64(r), ColumnIndex: int64(c)}
+			requests = append(requests, &slides.Request{
+				InsertText: &slides.InsertTextRequest{
+					ObjectId:     tableID,
+					CellLocation: location,
+					Text:         cell,
+				},
+			})
+			if r == 0 && len(t.Header) > 0 {
+				requests = append(requests, &slides.Request{
+					UpdateTextStyle: &slides.UpdateTextStyleRequest{
+						ObjectId:     tableID,
+						CellLocation: location,
+						TextRange:    &slides.Range{Type: "ALL"},
+						Style:        &slides.TextStyle{Bold: true},
+						Fields:       "bold",
+					},
+				})
+			}
+		}
+	}
+	if len(t.Header) > 0 {
+		requests = append(requests, &slides.Request{
+			UpdateTableCellProperties: &slides.UpdateTableCellPropertiesRequest{
+				ObjectId: tableID,
+				TableRange: &slides.TableRange{
+					Location:   &slides.TableCellLocation{RowIndex: 0, ColumnIndex: 0},
+					RowSpan:    1,
+					ColumnSpan: int64(columns),
+				},
+				TableCellProperties: &slides.TableCellProperties{
+					TableCellBackgroundFill: &slides.TableCellBackgroundFill{
+						SolidFill: &slides.SolidFill{Color: rgbColor(TableHeaderColor).OpaqueColor},
+					},
+				},
+				Fields: "tableCellBackgroundFill.solidFill.color",
+			},
+		})
+	}
+	for c, width := range ColumnWidths(t, frame.Width) {
+		requests = append(requests, &slides.Request{
+			UpdateTableColumnProperties: &slides.UpdateTableColumnPropertiesRequest{
+				ObjectId:      tableID,
+				ColumnIndices: []int64{int64(c)},
+				TableColumnProperties: &slides.TableColumnProperties{
+					ColumnWidth: &slides.Dimension{Magnitude: width, Unit: "EMU"},
+				},
+				Fields: "columnWidth",
+			},
+		})
+	}
+	return requests
+}
+
+var tableDelimiter = regexp.MustCompile(`^\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?$`)
+
+// ParseTable parses a Markdown pipe table: a header row, a delimiter row (such as |---|:-:|) and the rows.
+// The inline Markdown of the cells (bold, code spans, links...) is removed, but not their block markers, so that a
+// cell such as "1. Plan" or "- 5" is kept as is; an escaped pipe (\|) is a pipe within a cell.
+func ParseTable(text string) (structure.Table, bool) {
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n")), "\n")
+	if len(lines) < 2 || !isTableRow(lines[0]) || !tableDelimiter.MatchString(strings.TrimSpace(lines[1])) {
+		return structure.Table{}, false
+	}
+	header := tableCells(lines[0])
+	if len(header) != len(tableCells(lines[1])) {
+		return structure.Table{}, false
+	}
+	t := structure.Table{Header: header}
+	for _, line := range lines[2:] {
+		if !isTableRow(line) {
+			break
+		}
+		t.Rows = append(t.Rows, tableCells(line))
+	}
+	return t, true
+}
+
+// MarkdownTable returns the table as a Markdown pipe table, the missing cells of the short rows left empty.
+// The line breaks of the cells are replaced by spaces, and their pipes are escaped so that ParseTable reads the table back.
+func MarkdownTable(t structure.Table) string {
+	var sb strings.Builder
+	row := func(cells []string) {
+		sb.WriteString("|")
+		for c := 0; c < t.Columns(); c++ {
+			var cell string
+			if c < len(cells) {
+				cell = strings.ReplaceAll(strings.Join(strings.Fields(cells[c]), " "), "|", `\|`)
+			}
+			fmt.Fprintf(&sb, " %s |", cell)
+		}
+		sb.WriteString("\n")
+	}
+	row(t.Header)
+	sb.WriteString("|" + strings.Repeat(" --- |", t.Columns()) + "\n")
+	for _, cells := range t.Rows {
+		row(cells)
+	}
+	return sb.String()
+}
+
+// TableMarker returns the line replacing the i-th table of the content (see ExtractTables).
+func TableMarker(i int) string {
+	return fmt.Sprintf("[[table %d]]", i+1)
+}
+
+// ExtractTables replaces the Markdown pipe tables of the content by a marker line (see TableMarker) and returns them,
+// each one on a table slide titled by the heading preceding the table. The tables of the code blocks are left as is.
+func ExtractTables(content string) (string, []structure.Slide) {
+	lines := strings.Split(content, "\n")
+	var out []string
+	var tables []structure.Slide
+	var title, fence string
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		case heading.MatchString(trimmed):
+			title = runsText(Parse(trimmed))
+		case i+1 < len(lines) && isTableRow(lines[i]):
+			end := i + 2
+			for end < len(lines) && isTableRow(lines[end]) {
+				end++
+			}
+			if t, ok := ParseTable(strings.Join(lines[i:end], "\n")); ok {
+				slide := structure.Slide{Title: title, Table: t}
+				if slide.Title == "" {
+					slide.Title = fmt.Sprintf("Table %d", len(tables)+1)
+				}
+				out = append(out, TableMarker(len(tables)))
+				tables = append(tables, slide)
+				i = end - 1
+				continue
+			}
+		}
+		out = append(out, lines[i])
+	}
+	return strings.Join(out, "\n"), tables
+}
+
+// isTableRow reports whether the line is a row of a pipe table.
+func isTableRow(line string) bool {
+	return strings.TrimSpace(line) != "" && strings.Contains(line, "|")
+}
+
+// tableCells splits a row of a pipe table into its cells, as plain text: a cell is an inline, not a block.
+func tableCells(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteString(`\|`)
+			i++
+		case line[i] == '|':
+			cells = append(cells, cell.String())
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	cells = append(cells, cell.String())
+	for i, c := range cells {
+		cells[i] = runsText([]Paragraph{{Runs: parseInline(filterPrintable(strings.TrimSpace(c)), Run{})}})
+	}
+	return cells
+}
+
+// runsText returns the text of the paragraphs without their style.
+func runsText(paragraphs []Paragraph) string {
+	var sb strings.Builder
+	for i, p := range paragraphs {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		for _, r := range p.Runs {
+			sb.WriteString(r.Text)
+		}
+	}
+	return sb.String()
+}
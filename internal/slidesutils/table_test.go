@@ -0,0 +1,117 @@
+package slidesutils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/owulveryck/gptslideshow/internal/structure"
+	"google.golang.org/api/slides/v1"
+)
+
+func TestExtractTables(t *testing.T) {
+	content := "# Languages\n\nSome text | with a pipe\n\n| Name | **Typed** |\n|:-----|---:|\n| Go | yes \\| static |\n| Python |\n\nAfter\n\n```\n| a | b |\n|---|---|\n```\n| x | y |\n|---|---|"
+	wantContent := "# Languages\n\nSome text | with a pipe\n\n[[table 1]]\n\nAfter\n\n```\n| a | b |\n|---|---|\n```\n[[table 2]]"
+	wantTables := []structure.Slide{
+		{Title: "Languages", Table: structure.Table{Header: []string{"Name", "Typed"}, Rows: [][]string{{"Go", "yes | static"}, {"Python"}}}},
+		{Title: "Languages", Table: structure.Table{Header: []string{"x", "y"}}},
+	}
+	gotContent, gotTables := ExtractTables(content)
+	if gotContent != wantContent {
+		t.Errorf("ExtractTables() content = %q, want %q", gotContent, wantContent)
+	}
+	if !reflect.DeepEqual(gotTables, wantTables) {
+		t.Errorf("ExtractTables() tables = %+v, want %+v", gotTables, wantTables)
+	}
+}
+
+func TestParseTable(t *testing.T) {
+	text := "| Step | Value |\n|---|---|\n| 1. Plan | - 5 |\n| # 1 | > quoted |\n| **Do** `it` | [link](https://example.com) |"
+	want := structure.Table{Header: []string{"Step", "Value"}, Rows: [][]string{{"1. Plan", "- 5"}, {"# 1", "> quoted"}, {"Do it", "link"}}}
+	got, ok := ParseTable(text)
+	if !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTable() = %+v, %v, want %+v", got, ok, want)
+	}
+}
+
+func TestColumnWidths(t *testing.T) {
+	tests := []struct {
+		name  string
+		table structure.Table
+		width float64
+		want  []float64
+	}{
+		{
+			name:  "proportional to the longest cell",
+			table: structure.Table{Header: []string{"Id", "Description"}, Rows: [][]string{{"1", "twenty characters..."}}},
+			width: 2*minColumnWidth + 2400,
+			want:  []float64{minColumnWidth + 400, minColumnWidth + 2000},
+		},
+		{
+			name:  "capped cells",
+			table: structure.Table{Rows: [][]string{{"abcd", "a sentence much longer than forty characters, really"}}},
+			width: 2*minColumnWidth + 4400,
+			want:  []float64{minColumnWidth + 400, minColumnWidth + 4000},
+		},
+		{
+			name:  "too narrow",
+			table: structure.Table{Header: []string{"a", "b", "c"}},
+			width: 300,
+			want:  []float64{100, 100, 100},
+		},
+		{
+			name:  "empty",
+			width: 300,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ColumnWidths(tt.table, tt.width); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ColumnWidths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTable(t *testing.T) {
+	table := structure.Table{Header: []string{"Name", "Value"}, Rows: [][]string{{"a"}}}
+	requests := FormatTable(table, "page", "table", DefaultBodyFrame)
+	var kinds []string
+	for _, r := range requests {
+		switch {
+		case r.CreateTable != nil:
+			kinds = append(kinds, "create "+dumpRequests([]*slides.Request{r}))
+		case r.InsertText != nil:
+			kinds = append(kinds, "insert "+r.InsertText.Text)
+		case r.UpdateTextStyle != nil:
+			kinds = append(kinds, "bold")
+		case r.UpdateTableCellProperties != nil:
+			kinds = append(kinds, "fill")
+		case r.UpdateTableColumnProperties != nil:
+			kinds = append(kinds, "width")
+		}
+	}
+	want := []string{
+		`create {"requests":[{"createTable":{"columns":2,"elementProperties":{"pageObjectId":"page","size":{"height":{"magnitude":4648200,"unit":"EMU"},"width":{"magnitude":8229600,"unit":"EMU"}},"transform":{"scaleX":1,"scaleY":1,"translateX":457200,"translateY":1752600,"unit":"EMU"}},"objectId":"table","rows":2}}]}`,
+		"insert Name", "bold", "insert Value", "bold", "insert a", "fill", "width", "width",
+	}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Errorf("FormatTable() = %q, want %q", kinds, want)
+	}
+	if got := FormatTable(structure.Table{}, "page", "table", DefaultBodyFrame); got != nil {
+		t.Errorf("FormatTable() = %v, want nil", dumpRequests(got))
+	}
+}
+
+func TestMarkdownTable(t *testing.T) {
+	table := structure.Table{Header: []string{"Name", "Pipe | inside"}, Rows: [][]string{{"a", "two\nlines"}, {"b"}}}
+	want := "| Name | Pipe \\| inside |\n| --- | --- |\n| a | two lines |\n| b |  |\n"
+	got := MarkdownTable(table)
+	if got != want {
+		t.Errorf("MarkdownTable() = %q, want %q", got, want)
+	}
+	parsed, ok := ParseTable(got)
+	wantParsed := structure.Table{Header: []string{"Name", "Pipe | inside"}, Rows: [][]string{{"a", "two lines"}, {"b", ""}}}
+	if !ok || !reflect.DeepEqual(parsed, wantParsed) {
+		t.Errorf("ParseTable(MarkdownTable()) = %+v, %v, want %+v", parsed, ok, wantParsed)
+	}
+}
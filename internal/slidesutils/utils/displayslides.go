@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
 	"google.golang.org/api/slides/v1"
 )
 
@@ -17,10 +18,7 @@ func ListSlidesAndElements(srv *slides.Service, presentationId string) error {
 
 	fmt.Printf("Presentation Title: %s\n", presentation.Title)
 	fmt.Println("Listing slides and elements:")
-	layoutMap := make(map[string]string)
-	for _, layout := range presentation.Layouts {
-		layoutMap[layout.ObjectId] = layout.LayoutProperties.Name
-	}
+	layoutMap := slidesutils.ListLayouts(presentation)
 	for k, v := range layoutMap {
 		fmt.Printf("%v : %v\n", k, v)
 	}
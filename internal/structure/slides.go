@@ -16,6 +16,63 @@ type Slide struct {
 	Subtitle string `json:"subtitle" jsonschema_description:"The subtitle of the slide"`
 	Body     string `json:"body" jsonschema_description:"The main content of the slide or the description of the chapter"`
 	Chapter  bool   `json:"chapter" jsonschema_description:"A boolean to indicate if this slides introduces a new chapter"`
+	Notes    string `json:"notes" jsonschema_description:"The speaker notes: the talking points of the slide, taken from the original content"`
+	Code     string `json:"code" jsonschema_description:"A fenced code block (with its language after the opening fence) shown on a code slide, empty for the other slides"`
+	Table    Table  `json:"table" jsonschema_description:"The table shown on a table slide, with no header and no rows for the other slides"`
+	Chart    Chart  `json:"chart" jsonschema_description:"The chart shown on a chart slide, with no series for the other slides"`
+	Audio    Span   `json:"audio" jsonschema_description:"The passages of the transcript of a talk the slide summarises, empty if the content is not a transcript"`
+	// ImagePrompt is the prompt of the illustration of a chapter, set by the plan; it is not generated by the model
+	ImagePrompt string `json:"image_prompt,omitempty" jsonschema:"-"`
+}
+
+// Table is the content of a table slide.
+type Table struct {
+	Header []string   `json:"header" jsonschema_description:"The header row: the title of each column"`
+	Rows   [][]string `json:"rows" jsonschema_description:"The rows of the table, each one with a plain text cell per column"`
+}
+
+// Empty reports whether the table has neither header nor rows.
+func (t Table) Empty() bool {
+	return len(t.Header) == 0 && len(t.Rows) == 0
+}
+
+// Columns returns the number of columns of the table: the number of cells of its longest row.
+func (t Table) Columns() int {
+	n := len(t.Header)
+	for _, row := range t.Rows {
+		n = max(n, len(row))
+	}
+	return n
+}
+
+// Chart is the data of a chart slide.
+type Chart struct {
+	Kind   string   `json:"kind" jsonschema_description:"The kind of chart: bar, line or pie"`
+	Labels []string `json:"labels" jsonschema_description:"The categories of the horizontal axis (such as the periods of a time series), or the slices of a pie chart"`
+	Series []Series `json:"series" jsonschema_description:"The series of values; a pie chart shows the first one"`
+}
+
+// Series is a named series of values of a chart, one value per label.
+type Series struct {
+	Name   string    `json:"name" jsonschema_description:"The name of the series, shown in the legend"`
+	Values []float64 `json:"values" jsonschema_description:"The values of the series, one per label"`
+}
+
+// Empty reports whether the chart has no series.
+func (c Chart) Empty() bool {
+	return len(c.Series) == 0
+}
+
+// Span is the time range of the passages of a transcript, given by their time markers such as 01:23 or 1:02:03.
+type Span struct {
+	Source string `json:"source" jsonschema_description:"The audio file of the transcript, as named by its Source heading, empty if there is a single transcript"`
+	Start  string `json:"start" jsonschema_description:"The time marker of the first passage"`
+	End    string `json:"end" jsonschema_description:"The time marker of the last passage"`
+}
+
+// Empty reports whether the span has no start.
+func (s Span) Empty() bool {
+	return s.Start == ""
 }
 
 // GenerateSchema generates the JSON schema for a given type
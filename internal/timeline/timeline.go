@@ -0,0 +1,138 @@
+// Package timeline maps the slides generated from the transcripts of talks to the times of their recordings, so that
+// the recordings can be navigated slide by slide: it exports WebVTT chapters and a JSON index.
+package timeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/owulveryck/gptslideshow/internal/audio"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// Chapter is a slide, with the time range of the recording it summarises.
+type Chapter struct {
+	Slide      int // the number of the slide in the deck, the cover being the first one
+	Title      string
+	Source     string // the audio file
+	Start, End time.Duration
+}
+
+// Resolve maps the slides of the presentation to the passages of the transcripts, indexed by the name of their audio
+// file. The time markers of the span of each slide are located in its transcript (see audio.Transcript.Locate) and
+// replaced by the markers of the passages, its source by the name of the file; the spans that cannot be located are
+// cleared. A span belongs to the transcript it names, by path or by base name, or to the only transcript.
+//
+// Parameters:
+//   - p: The presentation, whose spans are updated.
+//   - transcripts: The transcripts of the audio files, by name.
+//
+// Returns:
+//   - The chapters of the slides with a span, in the order of the slides.
+func Resolve(p *structure.Presentation, transcripts map[string]audio.Transcript) []Chapter {
+	var chapters []Chapter
+	for i := range p.Slides {
+		slide := &p.Slides[i]
+		if slide.Audio.Empty() {
+			continue
+		}
+		source := lookup(slide.Audio.Source, transcripts)
+		start, end, ok := transcripts[source].Locate(slide.Audio.Start, slide.Audio.End)
+		if source == "" || !ok {
+			slide.Audio = structure.Span{}
+			continue
+		}
+		slide.Audio = structure.Span{Source: source, Start: audio.Marker(start), End: audio.Marker(end)}
+		chapters = append(chapters, Chapter{Slide: i + 2, Title: slide.Title, Source: source, Start: start, End: end})
+	}
+	return chapters
+}
+
+// lookup returns the name of the transcript of the source, or an empty string if there is none.
+func lookup(source string, transcripts map[string]audio.Transcript) string {
+	if _, ok := transcripts[source]; ok {
+		return source
+	}
+	var names []string
+	for name := range transcripts {
+		if source != "" && filepath.Base(name) == filepath.Base(source) {
+			return name
+		}
+		names = append(names, name)
+	}
+	if len(names) == 1 {
+		return names[0]
+	}
+	return ""
+}
+
+// WebVTT returns the WebVTT chapters of a recording: a cue per slide, titled after the slide, in the order of their
+// start. Each cue ends at the start of the next one at the latest, and the slides starting at the same time share their
+// cue.
+func WebVTT(chapters []Chapter) []byte {
+	chapters = append([]Chapter(nil), chapters...)
+	sort.SliceStable(chapters, func(i, j int) bool { return chapters[i].Start < chapters[j].Start })
+	var cues []Chapter
+	for _, c := range chapters {
+		title := strings.Join(strings.Fields(strings.ReplaceAll(c.Title, "-->", "->")), " ")
+		if title == "" {
+			title = fmt.Sprintf("Slide %d", c.Slide)
+		}
+		if n := len(cues); n > 0 && cues[n-1].Start == c.Start {
+			cues[n-1].Title += " / " + title
+			cues[n-1].End = max(cues[n-1].End, c.End)
+			continue
+		}
+		c.Title = title
+		cues = append(cues, c)
+	}
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+	for i, c := range cues {
+		end := c.End
+		if i+1 < len(cues) {
+			end = min(end, cues[i+1].Start)
+		}
+		fmt.Fprintf(&b, "\n%d\n%v --> %v\n%v\n", i+1, timestamp(c.Start), timestamp(max(end, c.Start)), c.Title)
+	}
+	return []byte(b.String())
+}
+
+// entry is a chapter in the JSON index.
+type entry struct {
+	Slide        int     `json:"slide"`
+	Title        string  `json:"title"`
+	Source       string  `json:"source"`
+	Start        string  `json:"start"`
+	End          string  `json:"end"`
+	StartSeconds float64 `json:"start_seconds"`
+	EndSeconds   float64 `json:"end_seconds"`
+}
+
+// Index returns the JSON index of the chapters: an array of objects giving the number and the title of each slide,
+// its audio file, and its time range both as WebVTT timestamps and in seconds.
+func Index(chapters []Chapter) ([]byte, error) {
+	entries := make([]entry, len(chapters))
+	for i, c := range chapters {
+		entries[i] = entry{
+			Slide:        c.Slide,
+			Title:        c.Title,
+			Source:       c.Source,
+			Start:        timestamp(c.Start),
+			End:          timestamp(c.End),
+			StartSeconds: c.Start.Seconds(),
+			EndSeconds:   c.End.Seconds(),
+		}
+	}
+	return json.MarshalIndent(entries, "", " ")
+}
+
+// timestamp returns the WebVTT timestamp of d, such as 01:02:03.450.
+func timestamp(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
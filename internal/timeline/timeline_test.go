@@ -0,0 +1,100 @@
+package timeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/owulveryck/gptslideshow/internal/audio"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+var talk = audio.Transcript{Cues: []audio.Cue{
+	{Start: 0, End: 4 * time.Second, Text: "Welcome."},
+	{Start: 4 * time.Second, End: 65 * time.Second, Text: "The budget."},
+	{Start: 65 * time.Second, End: 90500 * time.Millisecond, Text: "The costs."},
+}}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name        string
+		transcripts map[string]audio.Transcript
+		spans       []structure.Span
+		want        []structure.Span
+		chapters    string
+	}{
+		{
+			"single transcript",
+			map[string]audio.Transcript{"talks/talk.mp3": talk},
+			[]structure.Span{{}, {Start: "00:00", End: "00:05"}, {Source: "other.mp3", Start: "[01:06]"}, {Start: "later"}},
+			[]structure.Span{{}, {Source: "talks/talk.mp3", Start: "00:00", End: "01:05"}, {Source: "talks/talk.mp3", Start: "01:05", End: "01:30"}, {}},
+			"[{3 Slide 1 talks/talk.mp3 0s 1m5s} {4 Slide 2 talks/talk.mp3 1m5s 1m30.5s}]",
+		},
+		{
+			"several transcripts",
+			map[string]audio.Transcript{"talks/talk.mp3": talk, "talks/qa.mp3": {Cues: []audio.Cue{{Start: 0, End: 30 * time.Second, Text: "Questions?"}}}},
+			[]structure.Span{{Source: "qa.mp3", Start: "00:00"}, {Source: "talks/talk.mp3", Start: "00:04", End: "00:04"}, {Start: "00:00"}},
+			[]structure.Span{{Source: "talks/qa.mp3", Start: "00:00", End: "00:30"}, {Source: "talks/talk.mp3", Start: "00:04", End: "01:05"}, {}},
+			"[{2 Slide 0 talks/qa.mp3 0s 30s} {3 Slide 1 talks/talk.mp3 4s 1m5s}]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &structure.Presentation{}
+			for i, span := range tt.spans {
+				p.Slides = append(p.Slides, structure.Slide{Title: fmt.Sprintf("Slide %d", i), Audio: span})
+			}
+			chapters := Resolve(p, tt.transcripts)
+			for i, slide := range p.Slides {
+				if slide.Audio != tt.want[i] {
+					t.Errorf("slide %d: span %+v, want %+v", i, slide.Audio, tt.want[i])
+				}
+			}
+			if got := fmt.Sprint(chapters); got != tt.chapters {
+				t.Errorf("Resolve() = %v, want %v", got, tt.chapters)
+			}
+		})
+	}
+}
+
+func TestWebVTT(t *testing.T) {
+	chapters := []Chapter{
+		{Slide: 2, Title: "Welcome", Start: 0, End: 4 * time.Second},
+		{Slide: 4, Title: "The costs\n--> details", Start: 65 * time.Second, End: 3725500 * time.Millisecond},
+		{Slide: 3, Title: "The budget", Start: 4 * time.Second, End: 70 * time.Second},
+		{Slide: 5, Start: 65 * time.Second, End: 66 * time.Second},
+	}
+	want := `WEBVTT
+
+1
+00:00:00.000 --> 00:00:04.000
+Welcome
+
+2
+00:00:04.000 --> 00:01:05.000
+The budget
+
+3
+00:01:05.000 --> 01:02:05.500
+The costs -> details / Slide 5
+`
+	if got := string(WebVTT(chapters)); got != want {
+		t.Errorf("WebVTT() = %q, want %q", got, want)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	b, err := Index([]Chapter{{Slide: 3, Title: "The budget", Source: "talk.mp3", Start: 4 * time.Second, End: 65500 * time.Millisecond}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := "[map[end:00:01:05.500 end_seconds:65.5 slide:3 source:talk.mp3 start:00:00:04.000 start_seconds:4 title:The budget]]"
+	if fmt.Sprint(got) != want {
+		t.Errorf("Index() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/owulveryck/gptslideshow/internal/lint"
+	"github.com/owulveryck/gptslideshow/internal/plan"
+	"github.com/owulveryck/gptslideshow/internal/slidesutils/mytemplate"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// lintDeck checks the presentation of the -plan file, or else the Google Slides presentation -id, against the lint rules,
+// and writes the report on the standard output. It exits with the status 1 if a finding is an error.
+func lintDeck(ctx context.Context, opts *options) {
+	config := lint.DefaultConfig()
+	if opts.lintConfig != "" {
+		var err error
+		config, err = lint.LoadConfig(opts.lintConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var presentationData *structure.Presentation
+	var source string
+	switch {
+	case opts.planFile != "":
+		presentationData, source = loadPresentation(opts.planFile), opts.planFile
+	case opts.presentationId != "":
+		templateProfile, err := loadProfile(opts)
+		if err != nil {
+			log.Fatal(err)
+		}
+		layouts := layoutNames(templateProfile)
+		b, err := mytemplate.NewBuilder(ctx, initSlidesService(initGoogleClient()), opts.presentationId, layouts)
+		if err != nil {
+			log.Fatal(err)
+		}
+		presentationData = mytemplate.Read(b.Presentation, b.Layouts)
+		source = "https://docs.google.com/presentation/d/" + opts.presentationId
+	default:
+		log.Fatal("the " + commandLint + " command needs a -plan file or a presentation -id")
+	}
+
+	findings := lint.Lint(presentationData, config)
+	if err := lint.Write(os.Stdout, opts.lintFormat, findings, source); err != nil {
+		log.Fatal(err)
+	}
+	if lint.Failed(findings) {
+		os.Exit(1)
+	}
+}
+
+// loadPresentation reads the presentation of a plan file, or of a generated-data-*.json file (see savePresentation).
+func loadPresentation(filename string) *structure.Presentation {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(b, &header); err != nil {
+		log.Fatalf("cannot read %v: %v", filename, err)
+	}
+	if header.Version != 0 {
+		p, err := plan.Read(bytes.NewReader(b))
+		if err != nil {
+			log.Fatalf("cannot read %v: %v", filename, err)
+		}
+		return p.Presentation
+	}
+	var presentationData structure.Presentation
+	if err := json.Unmarshal(b, &presentationData); err != nil {
+		log.Fatalf("cannot read %v: %v", filename, err)
+	}
+	return &presentationData
+}
@@ -3,57 +3,169 @@ package main
 import (
 	"context"
 	"log"
-
-	drive "google.golang.org/api/drive/v3"
+	"os"
 
 	"github.com/owulveryck/gptslideshow/config"
 	"github.com/owulveryck/gptslideshow/internal/ai"
-	"github.com/owulveryck/gptslideshow/internal/driveutils"
-	"github.com/owulveryck/gptslideshow/internal/slidesutils/mytemplate"
+	"github.com/owulveryck/gptslideshow/internal/plan"
+	"github.com/owulveryck/gptslideshow/internal/slidesutils"
+	"github.com/owulveryck/gptslideshow/internal/structure"
 )
 
 func main() {
 	// Parse command-line flags
-	presentationId, fromTemplate, prompt, textfile, audiofile, helpFlag := parseFlags()
+	command, opts := parseFlags(os.Args[1:])
 
-	if *helpFlag {
+	if opts.help {
 		printHelp()
 		return
 	}
 
 	ctx := context.Background()
-	openaiClient := ai.NewAI()
-
-	// Initialize Google services
-	client := initGoogleClient()
-	slidesSrv := initSlidesService(client)
-	var driveSrv *drive.Service
+	if command == commandLint {
+		// Linting needs neither the model nor the content
+		lintDeck(ctx, opts)
+		return
+	}
+	// The provider is created on the first call of the model: the outline mode and the Marp files need no API key
+	if err := ai.Check(config.ConfigInstance); err != nil {
+		log.Fatal(err)
+	}
+	aiClient := ai.NewLazy(config.ConfigInstance)
+	templateProfile, err := loadProfile(opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	layouts := layoutNames(templateProfile)
 
-	// Read content from file or audio
-	content := readContent(ctx, openaiClient, textfile, audiofile)
+	if opts.resumeFile != "" {
+		// Continue an interrupted build in the same presentation, from the plan of the checkpoint
+		cp, err := plan.LoadCheckpoint(opts.resumeFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts.output = outputSlides
+		opts.presentationId = cp.PresentationID
+		opts.fromTemplate = ""
+		if cp.Plan.Layouts != nil {
+			layouts = cp.Plan.Layouts
+		}
+		d, err := newDeck(ctx, opts, templateProfile, layouts)
+		if err != nil {
+			log.Fatal(err)
+		}
+		d.checkpointFile = opts.resumeFile
+		if err := d.resume(ctx, cp); err != nil {
+			log.Fatal(err)
+		}
+		applyPlan(ctx, d, aiClient, cp)
+		return
+	}
 
-	// Handle template copy if specified
-	driveSrv = initDriveService(client)
-	if *fromTemplate != "" {
-		p := handleTemplateCopy(ctx, driveSrv, *fromTemplate)
-		presentationId = &p
+	switch command {
+	case commandRefine:
+		refineSlide(ctx, aiClient, opts, layouts)
+	case commandPlan:
+		p := makePlan(ctx, aiClient, opts, layouts, nil)
+		if err := writePlan(opts.planFile, p); err != nil {
+			log.Fatal(err)
+		}
+	case commandApply:
+		if opts.planFile == "" {
+			log.Fatal("the " + commandApply + " command needs a -plan file")
+		}
+		p, err := plan.Load(opts.planFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if p.Layouts != nil {
+			layouts = p.Layouts
+		}
+		d, err := newDeck(ctx, opts, templateProfile, layouts)
+		if err != nil {
+			log.Fatal(err)
+		}
+		applyPlan(ctx, d, aiClient, plan.NewCheckpoint(p))
+	default:
+		// Initialize the destination of the presentation before the generation
+		d, err := newDeck(ctx, opts, templateProfile, layouts)
+		if err != nil {
+			log.Fatal(err)
+		}
+		p := makePlan(ctx, aiClient, opts, layouts, d)
+		if err := writePlan(opts.planFile, p); err != nil {
+			log.Fatal(err)
+		}
+		applyPlan(ctx, d, aiClient, plan.NewCheckpoint(p))
 	}
+}
 
-	// Generate slides from content
-	presentationData := generateSlides(ctx, openaiClient, *prompt, content)
-	// Using mytemplate change to use yours
-	builder, err := mytemplate.NewBuilder(ctx, slidesSrv, *presentationId)
+// makePlan reads the contents and generates the presentation, or builds it from a Marp file or from the outline of the contents.
+// The bodies too long for a slide of the deck (nil for the plan command) are split into continuation slides, so that
+// the slides of the plan, of the chapters of the recordings and of the deck stay one to one.
+func makePlan(ctx context.Context, aiClient ai.Provider, opts *options, layouts map[string]string, d *deck) *plan.Plan {
+	var presentationData *structure.Presentation
+	var docs []document
+	if opts.marpFile != "" {
+		// Build the slides from a reviewed Markdown file
+		presentationData = loadMarp(opts.marpFile)
+	} else {
+		if len(opts.sources) == 0 {
+			log.Fatal("no content: give a -content or an -audio file")
+		}
+		sources, err := expandSources(opts.sources, opts.order)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// Read the contents from the files, and transcribe the audio files
+		docs = readContent(ctx, aiClient, sources)
+		if opts.outline {
+			// Build the slides from the structure of the Markdown contents
+			presentationData = loadOutline(ctx, aiClient, docs, opts.chapters, opts.condense)
+		} else {
+			// Generate slides from content, and review them
+			var critiques []ai.Critique
+			presentationData, critiques = generateSlides(ctx, aiClient, opts.prompt, docs, opts.chapters, opts.review)
+			if len(critiques) > 0 {
+				if err := writeCritiques(opts.planFile, critiques); err != nil {
+					log.Fatal(err)
+				}
+			}
+		}
+	}
+	if box, ok := bodyBox(opts, d); ok {
+		splitSlides(presentationData, box)
+	}
+	if opts.marpFile == "" {
+		saveChapters(presentationData, docs)
+		savePresentation(presentationData)
+	}
+	return plan.New(presentationData, layouts)
+}
 
-	// Create presentation slides
-	err = createPresentationSlides(ctx, builder, driveSrv, openaiClient, config.ConfigInstance.WithImage, presentationData)
-	if err != nil {
-		log.Fatal(err)
+// bodyBox returns the text box the bodies of the content slides are fitted in by the Google Slides deck: the one of
+// the deck, or the one of the default template if the deck is not created yet. It reports false for the other outputs
+// and for the templates described by a profile, whose bodies are not fitted.
+func bodyBox(opts *options, d *deck) (slidesutils.TextBox, bool) {
+	if d != nil {
+		if d.bodyBox == nil {
+			return slidesutils.TextBox{}, false
+		}
+		return *d.bodyBox, true
+	}
+	if opts.output != outputSlides || opts.profileFile != "" {
+		return slidesutils.TextBox{}, false
 	}
-	b, err := driveutils.ExtractPDF(ctx, driveSrv, *presentationId)
+	return slidesutils.TextBox{Frame: slidesutils.DefaultBodyFrame, FontSize: slidesutils.DefaultFontSize}, true
+}
+
+// applyPlan creates the slides of the plan held by the checkpoint and saves the deck.
+func applyPlan(ctx context.Context, d *deck, aiClient ai.Provider, cp *plan.Checkpoint) {
+	err := createPresentationSlides(ctx, d, aiClient, config.ConfigInstance.WithImage, cp, config.ConfigInstance.CheckpointInterval)
 	if err != nil {
 		log.Fatal(err)
 	}
-	err = saveContent("output-*.pdf", b)
+	err = d.save(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/owulveryck/gptslideshow/config"
+	"github.com/owulveryck/gptslideshow/internal/ai"
+	"github.com/owulveryck/gptslideshow/internal/plan"
+	"github.com/owulveryck/gptslideshow/internal/slidesutils/mytemplate"
+	"github.com/owulveryck/gptslideshow/internal/structure"
+)
+
+// refineSlide rewrites a slide of the Google Slides presentation -id according to the -instruction, from its current
+// text and the original content (the content of the -plan file, or else the -content and -audio files), and replaces the
+// text of its placeholders in place; the other slides are left untouched. An original content longer than the budget of
+// the prompt (MAX_PROMPT_TOKENS) is reduced to its part closest to the slide (see ai.RelevantChunk).
+func refineSlide(ctx context.Context, aiClient ai.Provider, opts *options, layouts map[string]string) {
+	if opts.presentationId == "" || opts.slide == "" || opts.instruction == "" {
+		log.Fatal("the " + commandRefine + " command needs a presentation -id, a -slide and an -instruction")
+	}
+	var original string
+	if opts.planFile != "" {
+		// The plan gives the original content and the layouts the presentation was built with
+		p, err := plan.Load(opts.planFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		original = p.Content
+		if p.Layouts != nil {
+			layouts = p.Layouts
+		}
+	} else if len(opts.sources) > 0 {
+		sources, err := expandSources(opts.sources, opts.order)
+		if err != nil {
+			log.Fatal(err)
+		}
+		original = joinDocuments(readContent(ctx, aiClient, sources))
+	}
+
+	b, err := mytemplate.NewBuilder(ctx, initSlidesService(initGoogleClient()), opts.presentationId, layouts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	page, number, err := b.FindSlide(opts.slide)
+	if err != nil {
+		log.Fatal(err)
+	}
+	current := mytemplate.ReadSlide(page, b.Layouts)
+
+	prompt := fmt.Sprintf(refinePrompt, opts.instruction)
+	content := slideText(current)
+	if original != "" {
+		// The original content takes the rest of the budget of the prompt: beyond, only its part closest to the slide is sent
+		header := "\nOriginal content:\n\n"
+		budget := config.ConfigInstance.MaxPromptTokens - ai.EstimateTokens(prompt+content+header)
+		if budget > 0 {
+			part := ai.RelevantChunk(original, content, budget)
+			if len(part) < len(original) {
+				log.Printf("The original content (about %d tokens) is too long: only its part closest to the slide is sent", ai.EstimateTokens(original))
+			}
+			content += header + part
+		} else {
+			log.Printf("The slide is too long to be sent along with the original content")
+		}
+	}
+	log.Printf("Refining slide %v: %v", number, current.Title)
+	refined, err := aiClient.GenerateSlide(ctx, prompt, []byte(content))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if current.Chapter {
+		refined.Chapter, refined.Body = true, ""
+	}
+	if err := b.ReplaceSlideText(ctx, page, *refined); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Slide %v refined: https://docs.google.com/presentation/d/%v/edit#slide=id.%v", number, opts.presentationId, page.ObjectId)
+}
+
+// slideText returns the text of the slide sent to the model.
+func slideText(slide structure.Slide) string {
+	return "Title: " + slide.Title + "\nSubtitle: " + slide.Subtitle + "\nBody:\n" + slide.Body + "\nNotes:\n" + slide.Notes + "\n"
+}
@@ -1,95 +1,371 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
 
-	drive "google.golang.org/api/drive/v3"
-
+	"github.com/owulveryck/gptslideshow/config"
 	"github.com/owulveryck/gptslideshow/internal/ai"
-	"github.com/owulveryck/gptslideshow/internal/driveutils"
+	"github.com/owulveryck/gptslideshow/internal/audio"
+	"github.com/owulveryck/gptslideshow/internal/chart"
+	"github.com/owulveryck/gptslideshow/internal/marp"
+	"github.com/owulveryck/gptslideshow/internal/outline"
+	"github.com/owulveryck/gptslideshow/internal/plan"
 	"github.com/owulveryck/gptslideshow/internal/slidesutils"
 	"github.com/owulveryck/gptslideshow/internal/structure"
+	"github.com/owulveryck/gptslideshow/internal/timeline"
 )
 
-func generateSlides(ctx context.Context, openaiClient *ai.AI, prompt string, content []byte) *structure.Presentation {
+// generateSlides generates the presentation from the documents, in a single generation or, if chapters is true and
+// there are several documents, with a chapter per document (see ai.ChapterPresentations); each generation is then
+// critiqued and revised at most review times (see generate), and the critiques are returned.
+func generateSlides(ctx context.Context, aiClient ai.Provider, prompt string, docs []document, chapters bool, review int) (*structure.Presentation, []ai.Critique) {
+	content := joinDocuments(docs)
+	var presentationData *structure.Presentation
+	var critiques []ai.Critique
+	if chapters && len(docs) > 1 {
+		partials := make([]*structure.Presentation, len(docs))
+		names := make([]string, len(docs))
+		for i, doc := range docs {
+			log.Printf("Generating the chapter of %v", doc.name)
+			var docCritiques []ai.Critique
+			partials[i], docCritiques = generate(ctx, aiClient, contentPrompt(prompt, docs[i:i+1]), doc.text, review)
+			critiques = append(critiques, docCritiques...)
+			names[i] = doc.name
+			if doc.audio {
+				// The transcript was generated alone: its spans name no source
+				for j := range partials[i].Slides {
+					if span := &partials[i].Slides[j].Audio; !span.Empty() {
+						span.Source = doc.name
+					}
+				}
+			}
+		}
+		var err error
+		presentationData, err = ai.ChapterPresentations(ctx, aiClient, prompt, partials, names)
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		presentationData, critiques = generate(ctx, aiClient, contentPrompt(prompt, docs), content, review)
+	}
+	presentationData.OriginalContent = []byte(content)
+	return presentationData, critiques
+}
+
+// saveChapters maps the slides generated from the transcripts to the times of the audio files (see timeline.Resolve),
+// and saves in the temporary directory the WebVTT chapters of each audio file and the JSON index of the slides.
+func saveChapters(presentationData *structure.Presentation, docs []document) {
+	transcripts := make(map[string]audio.Transcript)
+	for _, doc := range docs {
+		if doc.audio {
+			transcripts[doc.name] = doc.transcript
+		}
+	}
+	if len(transcripts) == 0 {
+		return
+	}
+	chapters := timeline.Resolve(presentationData, transcripts)
+	if len(chapters) == 0 {
+		log.Println("No slide is mapped to the time of the audio")
+		return
+	}
+	for _, doc := range docs {
+		var recording []timeline.Chapter
+		for _, c := range chapters {
+			if c.Source == doc.name {
+				recording = append(recording, c)
+			}
+		}
+		if len(recording) > 0 {
+			saveContent(fileTitle(doc.name)+"-chapters-*.vtt", timeline.WebVTT(recording))
+		}
+	}
+	index, err := timeline.Index(chapters)
+	if err != nil {
+		log.Fatal(err)
+	}
+	saveContent("chapters-*.json", index)
+}
+
+// generate generates the presentation of the content, and critiques and revises it at most review times (see
+// ai.ReviewPresentation); it returns the critiques.
+// The Markdown tables of the content do not go through the model: they are replaced by markers and become table slides,
+// or chart slides for the tables of figures, once the presentation is revised.
+func generate(ctx context.Context, aiClient ai.Provider, prompt string, content string, review int) (*structure.Presentation, []ai.Critique) {
+	text, tables := slidesutils.ExtractTables(content)
+	chartTables(tables)
+	if len(tables) > 0 {
+		prompt = tablesPrompt + prompt
+	}
 	saveContent("prompt-*.txt", []byte(prompt))
-	presentationData, err := openaiClient.GeneratePresentationFromText(ctx, prompt, content)
+	presentationData, err := ai.GenerateLongPresentation(ctx, aiClient, prompt, []byte(text), config.ConfigInstance.MaxPromptTokens, config.ConfigInstance.MaxOutputTokens)
 	if err != nil {
 		log.Fatal(err)
 	}
-	presentationData.OriginalContent = content
+	presentationData, critiques, err := ai.ReviewPresentation(ctx, aiClient, presentationData, []byte(text), review, config.ConfigInstance.MaxPromptTokens, config.ConfigInstance.MaxOutputTokens)
+	if err != nil {
+		// The review only improves the presentation: keep its last revision
+		log.Printf("The review stopped: %v", err)
+	}
+	insertTables(presentationData, tables)
+	checkCharts(presentationData.Slides)
+	return presentationData, critiques
+}
+
+// loadOutline builds the presentation from the headings of the Markdown documents, without calling the model
+// (see outline.Parse). The documents follow each other, the level 1 headings after the first one being chapters;
+// if chapters is true, each document is a chapter, under a level 1 heading named after the file if it has none.
+// The bodies longer than condense characters are condensed by the model, if condense is positive;
+// the original body is kept in the speaker notes.
+func loadOutline(ctx context.Context, aiClient ai.Provider, docs []document, chapters bool, condense int) *structure.Presentation {
+	title := documentsTitle(docs)
+	parts := make([]string, len(docs))
+	for i, doc := range docs {
+		parts[i] = strings.TrimSpace(doc.text)
+		if doc.audio {
+			// The time markers of a transcript are not part of its text
+			parts[i] = doc.transcript.Text()
+		}
+		if chapters && len(docs) > 1 && !strings.HasPrefix(parts[i], "# ") {
+			parts[i] = "# " + fileTitle(doc.name) + "\n\n" + parts[i]
+		}
+	}
+	content := strings.Join(parts, "\n\n") + "\n"
+	if chapters && len(docs) > 1 {
+		// The title of the presentation comes first, so that the first document is a chapter as well
+		content = "# " + title + "\n\n" + content
+	}
+	presentationData := outline.Parse(content, title)
+	chartTables(presentationData.Slides)
+	if condense > 0 {
+		for i := range presentationData.Slides {
+			slide := &presentationData.Slides[i]
+			if slide.Chapter || utf8.RuneCountInString(slide.Body) <= condense {
+				continue
+			}
+			log.Printf("Condensing slide %v: %v", i, slide.Title)
+			condensed, err := aiClient.GenerateSlide(ctx, fmt.Sprintf(condensePrompt, condense), []byte("Title: "+slide.Title+"\nBody:\n"+slide.Body))
+			if err != nil {
+				log.Fatal(err)
+			}
+			slide.Notes = slide.Body
+			slide.Body = condensed.Body
+		}
+	}
+	presentationData.OriginalContent = []byte(content)
+	return presentationData
+}
+
+// documentsTitle returns the default title of the presentation of the documents: the name of their directory if they
+// share one, or else the name of the first document.
+func documentsTitle(docs []document) string {
+	if len(docs) == 0 {
+		return ""
+	}
+	dir := filepath.Dir(docs[0].name)
+	for _, doc := range docs[1:] {
+		if filepath.Dir(doc.name) != dir {
+			return fileTitle(docs[0].name)
+		}
+	}
+	if len(docs) > 1 {
+		if abs, err := filepath.Abs(dir); err == nil {
+			return filepath.Base(abs)
+		}
+	}
+	return fileTitle(docs[0].name)
+}
+
+// fileTitle returns the name of the file without its extension.
+func fileTitle(filename string) string {
+	return strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+}
+
+// savePresentation saves the presentation in the temporary directory, as JSON and as a Marp Markdown file.
+func savePresentation(presentationData *structure.Presentation) {
 	b, err := json.MarshalIndent(presentationData, "", " ")
 	if err != nil {
 		log.Fatal(err)
 	}
 	saveContent("generated-data-*.json", b)
 
-	return presentationData
+	// Export a Markdown version that can be reviewed, edited and reloaded with -marp
+	var md bytes.Buffer
+	err = marp.Export(&md, presentationData)
+	if err != nil {
+		log.Fatal(err)
+	}
+	saveContent("presentation-*.md", md.Bytes())
 }
 
-func createPresentationSlides(ctx context.Context, builder slidesutils.BuilderInterface, driveSrv *drive.Service, openaiClient *ai.AI, withImages bool, presentationData *structure.Presentation) error {
-	err := builder.CreateCover(ctx, presentationData.Title, presentationData.Subtitle)
+// loadMarp reads a presentation from a Marp Markdown file.
+func loadMarp(filename string) *structure.Presentation {
+	f, err := os.Open(filename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	presentationData, err := marp.Import(f)
 	if err != nil {
+		log.Fatalf("cannot read %v: %v", filename, err)
+	}
+	return presentationData
+}
+
+// The size in pixels of the images of the charts, in the proportions of slidesutils.DefaultBodyFrame.
+const (
+	chartWidth  = 1600
+	chartHeight = 904
+)
+
+// createPresentationSlides builds the slides of the plan held by the checkpoint, from the slide following the checkpoint.
+// Every interval slides, and after each upload of an illustration or a chart, the pending requests are sent and the checkpoint is saved.
+func createPresentationSlides(ctx context.Context, d *deck, aiClient ai.Provider, withImages bool, cp *plan.Checkpoint, interval int) error {
+	builder := d.builder
+	imageFrame := d.imageFrame
+	p := cp.Plan
+	presentationData := p.Presentation
+	// A chart of an edited plan that cannot be rendered is built as a table rather than stopping the build
+	checkCharts(presentationData.Slides)
+
+	// Record the presentation before sending anything, so that a failure of the first batch can be resumed as well
+	if err := d.saveCheckpoint(ctx, cp); err != nil {
 		return err
 	}
+	if !cp.Cover {
+		err := builder.CreateCover(ctx, presentationData.Title, presentationData.Subtitle)
+		if err != nil {
+			return err
+		}
+		cp.Cover = true
+	}
+	if cp.Slides > 0 {
+		log.Printf("Resuming at slide %v of %v", cp.Slides, len(presentationData.Slides))
+	}
 
-	for i, slide := range presentationData.Slides {
+	for i := cp.Slides; i < len(presentationData.Slides); i++ {
+		slide := presentationData.Slides[i]
 		log.Printf("Slide %v: %v", i, slide.Title)
 		if slide.Chapter {
-			err = builder.CreateChapter(ctx, slide)
+			err := builder.CreateChapter(ctx, slide)
 			if err != nil {
 				return err
 			}
 			if withImages {
-				// Generate the illustration
-				img, err := openaiClient.GenerateImageFromText(ctx, slide.Body)
+				imageUrl, ok := cp.Images[i]
+				if !ok {
+					// Generate the illustration
+					img, err := aiClient.GenerateImageFromText(ctx, p.ImagePrompt(i))
+					if err != nil {
+						return err
+					}
+					imageUrl, err = d.uploadImage(ctx, img, slide.Title+".png")
+					if err != nil {
+						return err
+					}
+					cp.Images[i] = imageUrl
+				}
+				// Insert the image in the frame of the chapter
+				err = builder.InsertImage(ctx, imageUrl, imageFrame.Width, imageFrame.Height, imageFrame.TranslateX, imageFrame.TranslateY)
 				if err != nil {
 					return err
 				}
-				imageUrl, err := driveutils.UploadImage(ctx, driveSrv, img, slide.Title+".png")
+			}
+		} else if !slide.Chart.Empty() {
+			imageUrl, ok := cp.Images[i]
+			if !ok {
+				// Render the chart and upload it as an illustration
+				img, err := chart.Render(slide.Chart, chartWidth, chartHeight)
 				if err != nil {
-					return err
+					return fmt.Errorf("slide %v: %w", i, err)
 				}
-				var imageWidth, imageHeight, slideWidth, slideHeight, translateX, translateY float64
-				// Dimensions in EMUs (e.g., 3x3 inches)
-				imageWidth = 2743200  // 3 inches
-				imageHeight = 2743200 // 3 inches
-
-				// Slide dimensions in EMUs
-				slideWidth = 9144000  // 10 inches
-				slideHeight = 6858000 // 7.5 inches
-
-				// Calculate position to center the image
-				translateX = (slideWidth - imageWidth) / 2
-				translateY = (slideHeight - imageHeight) / 2
-				translateX = 1213950
-				translateY = 1659800
-
-				// Insert the image centered on the current slide
-				err = builder.InsertImage(ctx, imageUrl, imageWidth, imageHeight, translateX, translateY)
+				imageUrl, err = d.uploadImage(ctx, img, slide.Title+".png")
 				if err != nil {
 					return err
 				}
+				cp.Images[i] = imageUrl
+			}
+			err := builder.CreateChartSlide(ctx, slide, imageUrl)
+			if err != nil {
+				return err
+			}
+		} else if !slide.Table.Empty() {
+			err := builder.CreateTableSlide(ctx, slide)
+			if err != nil {
+				return err
+			}
+		} else if slide.Code != "" {
+			err := builder.CreateCodeSlide(ctx, slide)
+			if err != nil {
+				return err
 			}
 		} else {
-			/*
-				currentSlide, err := openaiClient.GenerateSlide(ctx, "Create a slide content based on the current title, subtitle, abstract based on the content provided", []byte("Title: "+slide.Title+"\nSubtitle: "+slide.Subtitle+"\nAbstract: "+slide.Body+"\nContent: "+string(presentationData.OriginalContent)))
-				if err != nil {
-					log.Fatal(err)
-				}
-				log.Println(currentSlide.Body)
-			*/
-
-			err = builder.CreateSlideTitleSubtitleBody(ctx, slide)
+			err := builder.CreateSlideTitleSubtitleBody(ctx, slide)
 			if err != nil {
 				return err
 			}
 		}
+		if (interval > 0 && (i+1)%interval == 0) || (withImages && slide.Chapter) || !slide.Chart.Empty() {
+			cp.Slides = i + 1
+			if err := d.saveCheckpoint(ctx, cp); err != nil {
+				return err
+			}
+		}
+	}
+	cp.Slides = len(presentationData.Slides)
+	if err := d.saveCheckpoint(ctx, cp); err != nil {
+		return err
+	}
+
+	// Send the requests deferred by the builder
+	if f, ok := builder.(slidesutils.Flusher); ok {
+		if err := f.Flush(ctx); err != nil {
+			return err
+		}
 	}
 
 	fmt.Println("New presentation created and modified successfully.")
 	return nil
 }
+
+// writePlan writes the plan to filename, or to a plan-*.json file in the temporary directory if filename is empty.
+func writePlan(filename string, p *plan.Plan) error {
+	var buf bytes.Buffer
+	if err := p.Write(&buf); err != nil {
+		return err
+	}
+	if filename == "" {
+		return saveContent("plan-*.json", buf.Bytes())
+	}
+	if err := os.WriteFile(filename, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	log.Printf("Plan written to: %s", filename)
+	return nil
+}
+
+// writeCritiques writes the critiques of the review of the presentation next to its plan: beside the plan file, or
+// in a critique-*.json file of the temporary directory if the plan has no file name (see writePlan).
+func writeCritiques(planFile string, critiques []ai.Critique) error {
+	b, err := json.MarshalIndent(critiques, "", " ")
+	if err != nil {
+		return err
+	}
+	if planFile == "" {
+		return saveContent("critique-*.json", b)
+	}
+	filename := strings.TrimSuffix(planFile, filepath.Ext(planFile)) + "-critique.json"
+	if err := os.WriteFile(filename, b, 0o644); err != nil {
+		return err
+	}
+	log.Printf("Critique written to: %s", filename)
+	return nil
+}
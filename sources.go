@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/owulveryck/gptslideshow/internal/audio"
+	"github.com/owulveryck/gptslideshow/internal/extract"
+)
+
+// source is an input of the presentation: a document, or an audio file to transcribe.
+type source struct {
+	path  string
+	audio bool
+}
+
+// sourceFlag is a repeatable flag adding its values to the sources, in the order of the command line.
+type sourceFlag struct {
+	sources *[]source
+	audio   bool
+}
+
+func (f sourceFlag) String() string {
+	if f.sources == nil {
+		return ""
+	}
+	var paths []string
+	for _, s := range *f.sources {
+		if s.audio == f.audio {
+			paths = append(paths, s.path)
+		}
+	}
+	return strings.Join(paths, ",")
+}
+
+func (f sourceFlag) Set(value string) error {
+	*f.sources = append(*f.sources, source{path: value, audio: f.audio})
+	return nil
+}
+
+// expandSources replaces the directories and the glob patterns of the sources by the files they hold, and sorts them.
+// A file given twice is read once.
+func expandSources(sources []source, order string) ([]source, error) {
+	var files []source
+	seen := make(map[string]bool)
+	for _, s := range sources {
+		paths, err := expandPath(s)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			if !seen[path] {
+				seen[path] = true
+				files = append(files, source{path: path, audio: s.audio})
+			}
+		}
+	}
+	switch order {
+	case orderArgs:
+	case orderName:
+		sort.SliceStable(files, func(i, j int) bool {
+			return filepath.Base(files[i].path) < filepath.Base(files[j].path)
+		})
+	case orderTime:
+		times := make(map[string]int64)
+		for _, f := range files {
+			info, err := os.Stat(f.path)
+			if err != nil {
+				return nil, err
+			}
+			times[f.path] = info.ModTime().UnixNano()
+		}
+		sort.SliceStable(files, func(i, j int) bool {
+			return times[files[i].path] < times[files[j].path]
+		})
+	default:
+		return nil, fmt.Errorf("unknown order %v (expected %v, %v or %v)", order, orderArgs, orderName, orderTime)
+	}
+	return files, nil
+}
+
+// expandPath returns the files of the source: the file itself, the supported files of a directory and its
+// subdirectories (hidden ones excepted), or the files matching a glob pattern; in both cases sorted by path.
+func expandPath(s source) ([]string, error) {
+	info, err := os.Stat(s.path)
+	switch {
+	case err == nil && !info.IsDir():
+		return []string{s.path}, nil
+	case err == nil:
+		var paths []string
+		err := filepath.WalkDir(s.path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(d.Name(), ".") && path != s.path {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.IsDir() && supported(path, s.audio) {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("%v: no supported file in the directory", s.path)
+		}
+		return paths, nil
+	case strings.ContainsAny(s.path, "*?["):
+		matches, err := filepath.Glob(s.path)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", s.path, err)
+		}
+		var paths []string
+		for _, path := range matches {
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				paths = append(paths, path)
+			}
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("%v: no file matches the pattern", s.path)
+		}
+		return paths, nil
+	default:
+		return nil, err
+	}
+}
+
+// supported reports whether the file of a directory is read: a document of a supported format, or an audio file.
+func supported(path string, transcribed bool) bool {
+	if transcribed {
+		return audio.Supported(path)
+	}
+	return extract.Supported(path)
+}
+
+// checkAudio checks the format of the audio file from its first bytes, so that an unsupported file is reported before
+// any transcription.
+func checkAudio(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	header := make([]byte, 4096)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	_, err = audio.Detect(path, header[:n])
+	return err
+}